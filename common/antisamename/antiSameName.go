@@ -10,74 +10,156 @@ import (
 	"fyne.io/fyne/v2"
 
 	"rename-tool/common/dialogcustomize"
-	"rename-tool/common/pathgen"
+	"rename-tool/common/planner"
 	"rename-tool/setting/model"
 )
 
-// CheckConflicts computes target paths for the given files and config,
-// and returns a list of conflicting target paths (case-insensitive):
-//  1. duplicates within the batch; 2) paths that already exist on disk
-//     and are not the same file (ignoring case-only change).
-func CheckConflicts(files []string, config model.RenameConfig) ([]string, error) {
-	seen := make(map[string]string) // lower(target) -> firstOriginal
-	conflictsSet := make(map[string]struct{})
+// DefaultSuffixPattern 未配置后缀格式时使用，生成 "name (2).ext"
+const DefaultSuffixPattern = " ({n})"
 
-	// state used to mirror batch naming logic
-	globalCounter := 0
-	perExtCounters := make(map[string]int)
+// Conflict 一个未解决的目标冲突
+type Conflict struct {
+	Index    int    // 计划项下标
+	Occupant string // 占用目标名称的文件：磁盘上已存在的文件，或同一批次中先占用该目标的源文件
+	InBatch  bool   // 占用者是否为同一批次的另一个计划项
+}
 
-	addConflict := func(path string) {
-		conflictsSet[path] = struct{}{}
+// FindConflicts returns the unresolved conflicts of a plan (case-insensitive):
+//  1. duplicates within the batch; 2) paths that already exist on disk
+//     and are not the same file (ignoring case-only change).
+//
+// A target that is the source of another entry is not a conflict: that file
+// moves away first, and swaps or cycles go through a temporary name.
+// Skipped entries keep their names, so targeting them is a conflict.
+// In copy mode no source moves away.
+func FindConflicts(plan *planner.Plan) []Conflict {
+	moving := make(map[string]struct{}, plan.Len()) // lower(source) of entries that leave their name
+	for _, entry := range plan.Entries {
+		if plan.Config.Mode == model.ExecRename && entry.Err == nil && entry.Source != entry.Target && entry.Resolution != planner.ResolutionSkip {
+			moving[strings.ToLower(entry.Source)] = struct{}{}
+		}
 	}
 
-	for _, file := range files {
-		var target string
-		var err error
+	var conflicts []Conflict
+	seen := make(map[string]int) // lower(target) -> first entry index
+	for i, entry := range plan.Entries {
+		if !active(entry) {
+			continue
+		}
 
-		switch config.Type {
-		case model.RenameTypeBatch:
-			current := globalCounter
-			globalCounter++
-			ext := filepath.Ext(file)
-			if _, ok := perExtCounters[ext]; !ok {
-				perExtCounters[ext] = 0
-			}
-			target, err = pathgen.GenerateBatchRenamePath(file, config, current, perExtCounters)
-		case model.RenameTypeExtension:
-			target, err = pathgen.GenerateExtensionRenamePath(file, config)
-		case model.RenameTypeCase:
-			target, err = pathgen.GenerateCaseRenamePath(file, config)
-		case model.RenameTypeInsertChar:
-			target, err = pathgen.GenerateInsertCharRenamePath(file, config)
-		case model.RenameTypeReplace:
-			target, err = pathgen.GenerateReplaceRenamePath(file, config)
-		case model.RenameTypeDeleteChar:
-			target, err = pathgen.GenerateDeleteCharRenamePath(file, config)
-		default:
-			// unknown type: skip
+		lower := strings.ToLower(entry.Target)
+		if first, exists := seen[lower]; exists {
+			conflicts = append(conflicts, Conflict{Index: i, Occupant: plan.Entries[first].Source, InBatch: true})
 			continue
 		}
-		if err != nil {
-			// treat as conflict source information
-			addConflict(file)
+		seen[lower] = i
+
+		// filesystem existence (ignore case-only self-change, files moved by this plan and accepted overwrites)
+		if entry.Resolution == planner.ResolutionOverwrite || caseOnlyRename(entry) {
 			continue
 		}
+		if _, isMoving := moving[lower]; isMoving {
+			continue
+		}
+		if _, err := os.Lstat(entry.Target); err == nil {
+			conflicts = append(conflicts, Conflict{Index: i, Occupant: entry.Target})
+		}
+	}
+	return conflicts
+}
 
-		lower := strings.ToLower(target)
-		if first, exists := seen[lower]; exists {
-			addConflict(first)
-			addConflict(target)
+// Apply 按策略解决单个冲突，更新计划项的 Target/Resolution/Conflict
+func Apply(plan *planner.Plan, c Conflict, policy model.ConflictPolicy) error {
+	entry := &plan.Entries[c.Index]
+	entry.Conflict = c.Occupant
+	// 文件夹不能覆盖或按内容去重，只能跳过或加前后缀
+	folder := entry.Folder || isFolder(c.Occupant)
+
+	switch policy {
+	case model.ConflictSkip:
+		entry.Resolution = planner.ResolutionSkip
+	case model.ConflictOverwrite:
+		if folder {
+			return fmt.Errorf("cannot overwrite folder %s", c.Occupant)
+		}
+		overwrite(plan, c)
+	case model.ConflictNewer:
+		newer, err := isNewer(entry.Source, c.Occupant)
+		if err != nil {
+			return err
+		}
+		if newer {
+			if folder {
+				return fmt.Errorf("cannot overwrite folder %s", c.Occupant)
+			}
+			overwrite(plan, c)
 		} else {
-			seen[lower] = file
+			entry.Resolution = planner.ResolutionSkip
+		}
+	case model.ConflictDedupe:
+		if folder {
+			return applySuffix(plan, c.Index)
+		}
+		same, err := SameContent(entry.Source, c.Occupant)
+		if err != nil {
+			return err
+		}
+		if same {
+			// 复制模式下输出文件夹中已有相同的副本，不再复制，也不删除源文件
+			entry.Resolution = planner.ResolutionDedupe
+			if plan.Config.Mode != model.ExecRename {
+				entry.Resolution = planner.ResolutionSkip
+			}
+			return nil
 		}
+		return applySuffix(plan, c.Index)
+	case model.ConflictSuffix:
+		return applySuffix(plan, c.Index)
+	case model.ConflictPrefix:
+		return applyPrefix(plan, c.Index)
+	default:
+		return fmt.Errorf("conflict policy %q cannot be applied automatically", policy)
+	}
+	return nil
+}
 
-		// filesystem existence (ignore case-only self-change)
-		if info, err := os.Stat(target); err == nil && info != nil {
-			if !strings.EqualFold(file, target) {
-				addConflict(target)
+// Resolve 按计划配置中的策略自动解决全部冲突
+// ConflictAbort 与 ConflictAsk 不做处理；解决冲突可能产生新的冲突（例如跳过的文件保留原名），因此反复检测直到稳定
+func Resolve(plan *planner.Plan) error {
+	policy := plan.Config.ConflictPolicy
+	if policy == model.ConflictAbort || policy == model.ConflictAsk {
+		return nil
+	}
+	for round := 0; round <= plan.Len(); round++ {
+		conflicts := FindConflicts(plan)
+		if len(conflicts) == 0 {
+			return nil
+		}
+		for _, c := range conflicts {
+			if err := Apply(plan, c, policy); err != nil {
+				return err
 			}
 		}
 	}
+	return fmt.Errorf("conflicts could not be resolved")
+}
+
+// CheckConflicts returns the conflicting paths that are still unresolved,
+// plus the sources whose target could not be generated.
+func CheckConflicts(plan *planner.Plan) ([]string, error) {
+	conflictsSet := make(map[string]struct{})
+	for _, entry := range plan.Entries {
+		if entry.Err != nil {
+			// treat as conflict source information
+			conflictsSet[entry.Source] = struct{}{}
+		}
+	}
+	for _, c := range FindConflicts(plan) {
+		if c.InBatch {
+			conflictsSet[c.Occupant] = struct{}{}
+		}
+		conflictsSet[plan.Entries[c.Index].Target] = struct{}{}
+	}
 
 	// collect set to slice
 	out := make([]string, 0, len(conflictsSet))
@@ -89,18 +171,56 @@ func CheckConflicts(files []string, config model.RenameConfig) ([]string, error)
 	return out, nil
 }
 
-// CheckAndShowConflicts runs CheckConflicts and shows a dialog if any conflicts found.
-// Returns true if a dialog was shown (caller should abort execution).
-func CheckAndShowConflicts(window fyne.Window, files []string, config model.RenameConfig) (bool, error) {
-	conflicts, err := CheckConflicts(files, config)
-	if err != nil {
-		return false, err
+// ResolveConflicts 按计划中的策略解决冲突，ConflictAsk 时逐个弹窗询问，处理完后调用 onReady
+// 中止策略下的冲突留在计划中，由执行前的预检报告列出；出错或用户取消时不调用 onReady
+func ResolveConflicts(window fyne.Window, plan *planner.Plan, onReady func()) {
+	if plan.Config.ConflictPolicy == model.ConflictAsk {
+		askConflicts(window, plan, onReady)
+		return
+	}
+	if err := Resolve(plan); err != nil {
+		dialogcustomize.ShowMessageDialog("error", dialogTr("error"), err.Error(), window)
+		return
 	}
-	if len(conflicts) > 0 {
-		dialogcustomize.ShowMultiLineCopyDialog("error", dialogTr("duplicateNames"), conflicts, window)
-		return true, nil
+	onReady()
+}
+
+// ValidateSuffixPattern 检查后缀格式是否包含序号且不含非法字符
+func ValidateSuffixPattern(pattern string) error {
+	if pattern == "" {
+		return nil
 	}
-	return false, nil
+	if !suffixNumber.MatchString(pattern) {
+		return fmt.Errorf("suffix pattern %q must contain {n}", pattern)
+	}
+	if strings.ContainsAny(suffixNumber.ReplaceAllString(pattern, ""), `\/:*?"<>|`) {
+		return fmt.Errorf("suffix pattern %q contains characters not allowed in file names", pattern)
+	}
+	for _, m := range suffixNumber.FindAllStringSubmatch(pattern, -1) {
+		if atoi(m[1]) > maxSuffixWidth {
+			return fmt.Errorf("suffix pattern %q: number width above %d", pattern, maxSuffixWidth)
+		}
+	}
+	return nil
+}
+
+// SuffixPath 在扩展名前插入按格式展开的序号后缀；文件夹没有扩展名，后缀加在名称末尾
+func SuffixPath(path, pattern string, n int, folder bool) string {
+	if pattern == "" {
+		pattern = DefaultSuffixPattern
+	}
+	var ext string
+	if !folder {
+		ext = filepath.Ext(path)
+	}
+	suffix := suffixNumber.ReplaceAllStringFunc(pattern, func(token string) string {
+		width := suffixNumber.FindStringSubmatch(token)[1]
+		if width == "" {
+			return fmt.Sprint(n)
+		}
+		return fmt.Sprintf("%0*d", min(atoi(width), maxSuffixWidth), n)
+	})
+	return path[:len(path)-len(ext)] + suffix + ext
 }
 
 // GenerateUniquePath returns a non-conflicting file path by appending
@@ -0,0 +1,174 @@
+package antisamename
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"rename-tool/common/planner"
+)
+
+// suffixNumber 后缀格式中的序号占位符：{n} 或 {n:03}
+var suffixNumber = regexp.MustCompile(`\{n(?::(\d+))?\}`)
+
+// maxSuffixWidth 序号补零位数上限，文件名本身最长 255 个字符
+const maxSuffixWidth = 255
+
+// active 计划项是否会占用目标名称
+func active(entry planner.Entry) bool {
+	return entry.Err == nil &&
+		entry.Source != entry.Target &&
+		entry.Resolution != planner.ResolutionSkip &&
+		entry.Resolution != planner.ResolutionDedupe
+}
+
+// isFolder 判断占用者是否为文件夹
+func isFolder(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.IsDir()
+}
+
+// overwrite 当前计划项取得目标名称；占用者是同批次计划项时，该计划项改为跳过
+func overwrite(plan *planner.Plan, c Conflict) {
+	plan.Entries[c.Index].Resolution = planner.ResolutionOverwrite
+	if !c.InBatch {
+		return
+	}
+	for i := range plan.Entries {
+		other := &plan.Entries[i]
+		if i != c.Index && other.Source == c.Occupant {
+			other.Resolution = planner.ResolutionSkip
+			other.Conflict = plan.Entries[c.Index].Source
+		}
+	}
+}
+
+// applySuffix 为计划项选择第一个未被占用的带后缀名称（序号从 2 开始）
+func applySuffix(plan *planner.Plan, index int) error {
+	taken := make(map[string]struct{}, plan.Len()*2)
+	for i, entry := range plan.Entries {
+		if i == index {
+			continue
+		}
+		// 其他计划项的源和目标都视为占用，避免依赖执行顺序
+		taken[strings.ToLower(entry.Source)] = struct{}{}
+		if entry.Err == nil {
+			taken[strings.ToLower(entry.Target)] = struct{}{}
+		}
+	}
+
+	entry := &plan.Entries[index]
+	for n := 2; n < 100000; n++ {
+		candidate := SuffixPath(entry.Target, plan.Config.SuffixPattern, n, entry.Folder)
+		if _, exists := taken[strings.ToLower(candidate)]; exists {
+			continue
+		}
+		if _, err := os.Lstat(candidate); err == nil {
+			continue
+		}
+		entry.Target = candidate
+		entry.Resolution = planner.ResolutionSuffix
+		return nil
+	}
+	return fmt.Errorf("no free suffixed name for %s", entry.Target)
+}
+
+// applyPrefix 在名称前加上源文件相对所选目录的路径（sub_dir_file.jpg）
+// 源文件就在所选目录中或加前缀后仍冲突时，改为加后缀
+func applyPrefix(plan *planner.Plan, index int) error {
+	entry := &plan.Entries[index]
+	rel, err := filepath.Rel(plan.Config.SelectedDir, filepath.Dir(entry.Source))
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") || entry.Resolution == planner.ResolutionPrefix {
+		return applySuffix(plan, index)
+	}
+	prefix := strings.Join(strings.Split(rel, string(filepath.Separator)), "_")
+	dir, name := filepath.Split(entry.Target)
+	entry.Target = filepath.Join(dir, prefix+"_"+name)
+	entry.Resolution = planner.ResolutionPrefix
+	return nil
+}
+
+// isNewer 判断 source 的修改时间是否晚于 occupant
+func isNewer(source, occupant string) (bool, error) {
+	sourceInfo, err := os.Stat(source)
+	if err != nil {
+		return false, err
+	}
+	occupantInfo, err := os.Stat(occupant)
+	if err != nil {
+		return false, err
+	}
+	return sourceInfo.ModTime().After(occupantInfo.ModTime()), nil
+}
+
+// SameContent 逐块比较两个文件的内容，同一个文件（例如只改大小写）不视为重复
+func SameContent(a, b string) (bool, error) {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	if os.SameFile(infoA, infoB) {
+		return false, nil // 同一个文件（例如只改大小写），不能当作重复删除
+	}
+	if infoA.Size() != infoB.Size() {
+		return false, nil
+	}
+
+	fileA, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fileA.Close()
+	fileB, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fileB.Close()
+
+	bufA := make([]byte, 64*1024)
+	bufB := make([]byte, 64*1024)
+	for {
+		nA, errA := io.ReadFull(fileA, bufA)
+		nB, errB := io.ReadFull(fileB, bufB)
+		if nA != nB || !bytes.Equal(bufA[:nA], bufB[:nB]) {
+			return false, nil
+		}
+		if errA == io.EOF || errA == io.ErrUnexpectedEOF {
+			return errB == io.EOF || errB == io.ErrUnexpectedEOF, nil
+		}
+		if errA != nil {
+			return false, errA
+		}
+		if errB != nil {
+			return false, errB
+		}
+	}
+}
+
+// caseOnlyRename 只改大小写且目标名称指向源文件本身（不区分大小写的文件系统）
+// 区分大小写的文件系统上 a.txt 与 A.txt 是两个文件，已存在的 A.txt 属于冲突
+func caseOnlyRename(entry planner.Entry) bool {
+	if !strings.EqualFold(entry.Source, entry.Target) {
+		return false
+	}
+	source, err := os.Lstat(entry.Source)
+	if err != nil {
+		return false
+	}
+	target, err := os.Lstat(entry.Target)
+	return err != nil || os.SameFile(source, target)
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,200 @@
+package antisamename
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"rename-tool/common/planner"
+	"rename-tool/setting/model"
+)
+
+// writeFiles 在 dir 中按相对路径创建文件，内容由 content 给出
+func writeFiles(t *testing.T, dir string, content map[string]string) {
+	t.Helper()
+	for rel, data := range content {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestResolvePolicies(t *testing.T) {
+	type want struct {
+		target     string
+		resolution planner.Resolution
+	}
+	old := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		policy    model.ConflictPolicy
+		mode      model.ExecMode
+		files     map[string]string
+		older     []string    // 修改时间改为一小时前的文件
+		pairs     [][2]string // 计划项：源 -> 目标
+		want      []want
+		conflicts int // Resolve 之后仍未解决的冲突数
+	}{
+		{name: "abort leaves conflicts", policy: model.ConflictAbort,
+			files: map[string]string{"a.txt": "a", "b.txt": "b"},
+			pairs: [][2]string{{"a.txt", "b.txt"}, {"c.txt", "d.txt"}},
+			want:  []want{{"b.txt", ""}, {"d.txt", ""}}, conflicts: 1},
+		{name: "ask is left to the dialog", policy: model.ConflictAsk,
+			files: map[string]string{"a.txt": "a", "b.txt": "b"},
+			pairs: [][2]string{{"a.txt", "b.txt"}},
+			want:  []want{{"b.txt", ""}}, conflicts: 1},
+		{name: "skip", policy: model.ConflictSkip,
+			files: map[string]string{"a.txt": "a", "b.txt": "b"},
+			pairs: [][2]string{{"a.txt", "b.txt"}},
+			want:  []want{{"b.txt", planner.ResolutionSkip}}},
+		{name: "overwrite existing file", policy: model.ConflictOverwrite,
+			files: map[string]string{"a.txt": "a", "b.txt": "b"},
+			pairs: [][2]string{{"a.txt", "b.txt"}},
+			want:  []want{{"b.txt", planner.ResolutionOverwrite}}},
+		{name: "overwrite within the batch skips the first", policy: model.ConflictOverwrite,
+			files: map[string]string{"a.txt": "a", "c.txt": "c"},
+			pairs: [][2]string{{"a.txt", "x.txt"}, {"c.txt", "x.txt"}},
+			want:  []want{{"x.txt", planner.ResolutionSkip}, {"x.txt", planner.ResolutionOverwrite}}},
+		{name: "suffix skips names taken by other targets", policy: model.ConflictSuffix,
+			files: map[string]string{"a.txt": "a", "b.txt": "b", "c.txt": "c"},
+			pairs: [][2]string{{"a.txt", "b.txt"}, {"c.txt", "b (2).txt"}},
+			want:  []want{{"b (3).txt", planner.ResolutionSuffix}, {"b (2).txt", ""}}},
+		{name: "suffix within the batch", policy: model.ConflictSuffix,
+			files: map[string]string{"a.txt": "a", "c.txt": "c"},
+			pairs: [][2]string{{"a.txt", "x.txt"}, {"c.txt", "x.txt"}},
+			want:  []want{{"x.txt", ""}, {"x (2).txt", planner.ResolutionSuffix}}},
+		{name: "prefix with the source folder", policy: model.ConflictPrefix,
+			files: map[string]string{"sub/dir/a.jpg": "a", "a.jpg": "root"},
+			pairs: [][2]string{{"sub/dir/a.jpg", "a.jpg"}},
+			want:  []want{{"sub_dir_a.jpg", planner.ResolutionPrefix}}},
+		{name: "prefix falls back to suffix in the selected folder", policy: model.ConflictPrefix,
+			files: map[string]string{"a.jpg": "a", "b.jpg": "b"},
+			pairs: [][2]string{{"a.jpg", "b.jpg"}},
+			want:  []want{{"b (2).jpg", planner.ResolutionSuffix}}},
+		{name: "newer source overwrites", policy: model.ConflictNewer,
+			files: map[string]string{"a.txt": "new", "b.txt": "old"}, older: []string{"b.txt"},
+			pairs: [][2]string{{"a.txt", "b.txt"}},
+			want:  []want{{"b.txt", planner.ResolutionOverwrite}}},
+		{name: "older source is skipped", policy: model.ConflictNewer,
+			files: map[string]string{"a.txt": "old", "b.txt": "new"}, older: []string{"a.txt"},
+			pairs: [][2]string{{"a.txt", "b.txt"}},
+			want:  []want{{"b.txt", planner.ResolutionSkip}}},
+		{name: "dedupe identical content", policy: model.ConflictDedupe,
+			files: map[string]string{"a.txt": "same", "b.txt": "same"},
+			pairs: [][2]string{{"a.txt", "b.txt"}},
+			want:  []want{{"b.txt", planner.ResolutionDedupe}}},
+		{name: "dedupe equal size different content", policy: model.ConflictDedupe,
+			files: map[string]string{"a.txt": "abcd", "b.txt": "abce"},
+			pairs: [][2]string{{"a.txt", "b.txt"}},
+			want:  []want{{"b (2).txt", planner.ResolutionSuffix}}},
+		{name: "dedupe in copy mode skips the copy", policy: model.ConflictDedupe, mode: model.ExecCopy,
+			files: map[string]string{"a.txt": "same", "out/b.txt": "same"},
+			pairs: [][2]string{{"a.txt", "out/b.txt"}},
+			want:  []want{{"out/b.txt", planner.ResolutionSkip}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			for _, rel := range tt.older {
+				if err := os.Chtimes(filepath.Join(dir, rel), old, old); err != nil {
+					t.Fatal(err)
+				}
+			}
+			plan := &planner.Plan{Config: model.RenameConfig{ConflictPolicy: tt.policy, Mode: tt.mode, SelectedDir: dir}}
+			for _, p := range tt.pairs {
+				plan.Entries = append(plan.Entries, planner.Entry{
+					Source: filepath.Join(dir, filepath.FromSlash(p[0])),
+					Target: filepath.Join(dir, filepath.FromSlash(p[1])),
+				})
+			}
+
+			if err := Resolve(plan); err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			for i, w := range tt.want {
+				entry := plan.Entries[i]
+				if target := filepath.Join(dir, filepath.FromSlash(w.target)); entry.Target != target || entry.Resolution != w.resolution {
+					t.Errorf("entry %d = %s [%s], want %s [%s]", i, entry.Target, entry.Resolution, target, w.resolution)
+				}
+			}
+			if got := len(FindConflicts(plan)); got != tt.conflicts {
+				t.Errorf("%d conflicts left, want %d", got, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestSameContent(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"a": "abcd", "b": "abcd", "c": "abce", "d": "abc"})
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"a", "b", true},
+		{"a", "c", false}, // 大小相同，内容不同
+		{"a", "d", false},
+		{"a", "a", false}, // 同一个文件不是重复文件
+	}
+	for _, tt := range tests {
+		got, err := SameContent(path(tt.a), path(tt.b))
+		if err != nil || got != tt.want {
+			t.Errorf("SameContent(%s, %s) = %v, %v, want %v", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+	if _, err := SameContent(path("a"), path("missing")); err == nil {
+		t.Error("SameContent with a missing file: expected an error")
+	}
+}
+
+func TestSuffixPath(t *testing.T) {
+	tests := []struct {
+		path, pattern string
+		n             int
+		folder        bool
+		want          string
+	}{
+		{"/d/a.txt", "", 2, false, "/d/a (2).txt"},
+		{"/d/a.txt", "_{n:03}", 7, false, "/d/a_007.txt"},
+		{"/d/v1.2", "-{n}", 3, true, "/d/v1.2-3"},
+	}
+	for _, tt := range tests {
+		if got := SuffixPath(tt.path, tt.pattern, tt.n, tt.folder); got != tt.want {
+			t.Errorf("SuffixPath(%s, %q, %d) = %s, want %s", tt.path, tt.pattern, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSuffixPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr bool
+	}{
+		{"", false},
+		{" ({n})", false},
+		{"_{n:03}", false},
+		{"_copy", true},
+		{"/{n}", true},
+		{"_{n:255}", false},
+		{"_{n:256}", true},
+		{"_{n}_{n:999999999}", true},
+	}
+	for _, tt := range tests {
+		if err := ValidateSuffixPattern(tt.pattern); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateSuffixPattern(%q) = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+		}
+	}
+	// 未经检查的格式（如旧的设置）也不会生成超长的名称
+	if got := SuffixPath("a", "_{n:999999999}", 2, true); len(got) != len("a_")+maxSuffixWidth {
+		t.Errorf("SuffixPath with a huge width returned %d characters", len(got))
+	}
+}
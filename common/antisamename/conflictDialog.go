@@ -0,0 +1,115 @@
+package antisamename
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"rename-tool/common/dialogcustomize"
+	"rename-tool/common/planner"
+	"rename-tool/setting/model"
+)
+
+// conflictChoices 询问对话框中的选项，顺序即按钮顺序
+var conflictChoices = []model.ConflictPolicy{
+	model.ConflictSkip,
+	model.ConflictOverwrite,
+	model.ConflictSuffix,
+	model.ConflictPrefix,
+	model.ConflictNewer,
+	model.ConflictDedupe,
+}
+
+// askConflicts 逐个询问冲突的处理方式；勾选“应用到全部”后其余冲突按同一策略自动处理
+// 全部解决后调用 done；用户取消时直接返回，不执行重命名
+func askConflicts(window fyne.Window, plan *planner.Plan, done func()) {
+	var applyAll model.ConflictPolicy
+	rounds := 0
+
+	var next func()
+	next = func() {
+		conflicts := FindConflicts(plan)
+		if len(conflicts) == 0 || rounds > plan.Len()*2 {
+			done()
+			return
+		}
+		rounds++
+
+		if applyAll != "" {
+			for _, c := range conflicts {
+				if err := Apply(plan, c, applyAll); err != nil {
+					dialogcustomize.ShowMessageDialog("error", dialogTr("error"), err.Error(), window)
+					return
+				}
+			}
+			next()
+			return
+		}
+
+		c := conflicts[0]
+		showConflictDialog(window, plan, c, func(policy model.ConflictPolicy, all bool) {
+			if err := Apply(plan, c, policy); err != nil {
+				dialogcustomize.ShowMessageDialog("error", dialogTr("error"), err.Error(), window)
+				return
+			}
+			if all {
+				applyAll = policy
+			}
+			next()
+		})
+	}
+	next()
+}
+
+// showConflictDialog 显示单个冲突：源文件、目标名称以及占用者的大小和修改时间
+func showConflictDialog(window fyne.Window, plan *planner.Plan, c Conflict, onChoose func(policy model.ConflictPolicy, all bool)) {
+	entry := plan.Entries[c.Index]
+	message := fmt.Sprintf(dialogTr("conflictMessage"),
+		filepath.Base(entry.Source), planner.TargetName(entry.Source, entry.Target),
+		describeFile(entry.Source), describeFile(c.Occupant))
+	label := widget.NewLabel(message)
+	label.Wrapping = fyne.TextWrapWord
+
+	applyAll := widget.NewCheck(dialogTr("conflictApplyAll"), nil)
+
+	// 文件夹只能跳过或加前后缀；所选目录中的文件没有路径前缀可加
+	folder := entry.Folder || isFolder(c.Occupant)
+	nested := filepath.Dir(entry.Source) != filepath.Clean(plan.Config.SelectedDir)
+
+	var d dialog.Dialog
+	buttons := container.NewHBox(layout.NewSpacer())
+	for _, policy := range conflictChoices {
+		policy := policy
+		if folder && policy != model.ConflictSkip && policy != model.ConflictSuffix && policy != model.ConflictPrefix {
+			continue
+		}
+		if policy == model.ConflictPrefix && !nested {
+			continue
+		}
+		buttons.Add(widget.NewButton(dialogTr("conflict_"+string(policy)), func() {
+			d.Hide()
+			onChoose(policy, applyAll.Checked)
+		}))
+	}
+	buttons.Add(widget.NewButton(dialogTr("cancel"), func() { d.Hide() }))
+
+	content := container.NewVBox(label, applyAll, buttons)
+	d = dialog.NewCustomWithoutButtons(dialogTr("conflictTitle"), content, window)
+	d.Resize(fyne.NewSize(560, 0))
+	d.Show()
+}
+
+// describeFile 返回“路径（大小，修改时间）”
+func describeFile(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return path
+	}
+	return fmt.Sprintf("%s (%d B, %s)", path, info.Size(), info.ModTime().Format("2006-01-02 15:04:05"))
+}
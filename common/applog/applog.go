@@ -60,3 +60,13 @@ func GetLogPath() string {
 	}
 	return filepath.Join(logDir, "rename.log")
 }
+
+// GetJournalPath 返回持久化重命名日志的路径，与操作日志位于同一目录
+func GetJournalPath() string {
+	appDir := getUserDir()
+	logDir := filepath.Join(appDir, config.LogDir)
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return filepath.Join(appDir, config.JournalFile)
+	}
+	return filepath.Join(logDir, config.JournalFile)
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"rename-tool/common/antisamename"
+	"rename-tool/common/dirpath"
+	"rename-tool/common/executor"
+	"rename-tool/common/jobfile"
+	"rename-tool/common/journal"
+	"rename-tool/common/planner"
+	"rename-tool/common/preflight"
+)
+
+// 退出码，供脚本区分执行结果
+const (
+	ExitOK         = 0 // 全部成功（或预演无冲突）
+	ExitError      = 1 // 参数错误、目录读取失败等，未执行任何重命名
+	ExitConflict   = 2 // 存在重名冲突、生成失败或未通过预检的文件，未执行任何重命名
+	ExitPartial    = 3 // 部分文件重命名失败（事务模式下为回滚未完全成功）
+	ExitRolledBack = 4 // 事务模式下有文件失败，已全部回滚
+)
+
+// IsCommand 判断参数是否为命令行模式的子命令，用于 main 决定是否跳过界面
+func IsCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	if _, ok := commands[args[0]]; ok {
+		return true
+	}
+	switch args[0] {
+	case recoverCommand, jobCommand, "help", "-h", "-help", "--help":
+		return true
+	}
+	return false
+}
+
+// Run 执行命令行模式，返回进程退出码
+// 与界面共用 pathgen 生成器、antisamename 预检和 filestatus.RenameFile
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		printUsage(stdout)
+		return ExitOK
+	}
+
+	switch args[0] {
+	case recoverCommand:
+		return runRecover(args[1:], stdout, stderr)
+	case jobCommand:
+		return runJob(args[1:], stdout, stderr)
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		printUsage(stderr)
+		return ExitError
+	}
+
+	opts, err := parseOptions(cmd, args[1:], stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", cmd.name, err)
+		return ExitError
+	}
+
+	if opts.saveJob != "" {
+		if err := jobfile.Save(opts.saveJob, jobfile.New(opts.config, opts.recursive)); err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", cmd.name, err)
+			return ExitError
+		}
+	}
+
+	return execute(cmd.name, opts, stdout, stderr)
+}
+
+// execute 按解析后的选项生成计划、预检并执行，子命令与 run 共用
+func execute(name string, opts options, stdout, stderr io.Writer) int {
+	noteInterrupted(stderr)
+
+	files, err := dirpath.GetItems(opts.config.SelectedDir, opts.config.Formats, opts.recursive, opts.config.Items)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return ExitError
+	}
+
+	plan, err := planner.Build(files, opts.config)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return ExitError
+	}
+
+	if err := antisamename.Resolve(plan); err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return ExitError
+	}
+
+	// 执行前预检：权限、只读、占用、路径长度、名称与冲突；--skip-problems 时排除有问题的文件，
+	// 排除的文件保留原名，可能使其他文件产生新的冲突，反复检查直到没有问题
+	// 试运行不在磁盘上写入任何文件，跳过写入权限测试，报告中列出未验证的文件夹
+	var unverified []string
+	check := func() []preflight.Issue {
+		if opts.dryRun {
+			var issues []preflight.Issue
+			issues, unverified = preflight.CheckDryRun(plan)
+			return issues
+		}
+		return preflight.Check(plan)
+	}
+	issues := check()
+	var excluded []preflight.Issue
+	for opts.skipProblems && len(issues) > 0 {
+		preflight.Exclude(plan, issues)
+		excluded = append(excluded, issues...)
+		issues = check()
+	}
+
+	conflicts, err := antisamename.CheckConflicts(plan)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return ExitError
+	}
+
+	rep := newReport(plan, conflicts, opts.dryRun)
+	rep.preflight(issues, excluded, unverified)
+	if len(conflicts) > 0 || len(issues) > 0 || opts.dryRun {
+		return rep.write(opts, stdout, stderr)
+	}
+
+	// Ctrl+C 停止开始新的重命名，已开始的完成后输出完整报告
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	for result := range executor.Run(ctx, plan, nil) {
+		rep.record(result)
+	}
+	return rep.write(opts, stdout, stderr)
+}
+
+// noteInterrupted 提示日志中有中断的批次（与界面启动时的检测相同），不影响本次执行
+func noteInterrupted(stderr io.Writer) {
+	if _, err := journal.RecoverInterrupted(); err != nil {
+		return
+	}
+	if ops, err := journal.Interrupted(); err == nil && len(ops) > 0 {
+		fmt.Fprintf(stderr, "note: %d interrupted batch(es) found, run 'renamer %s' to finish or roll them back\n", len(ops), recoverCommand)
+	}
+}
+
+// Main 供 main 包调用：连接控制台后执行并退出进程
+func Main(args []string) {
+	attachConsole()
+	os.Exit(Run(args, os.Stdout, os.Stderr))
+}
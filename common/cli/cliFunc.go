@@ -0,0 +1,574 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"rename-tool/common/antisamename"
+	"rename-tool/common/executor"
+	"rename-tool/common/filesort"
+	"rename-tool/common/planner"
+	"rename-tool/common/preflight"
+	"rename-tool/setting/i18n"
+	"rename-tool/setting/model"
+)
+
+// command 子命令定义：注册专属参数并在解析后生成重命名配置
+type command struct {
+	name    string
+	summary string
+	setup   func(fs *flag.FlagSet) func() (model.RenameConfig, error)
+}
+
+// commands 子命令表，与界面中的各重命名类型一一对应
+var commands = map[string]command{
+	"batch": {
+		name:    "batch",
+		summary: "sequence rename with prefix/suffix numbering",
+		setup: func(fs *flag.FlagSet) func() (model.RenameConfig, error) {
+			prefix := fs.String("prefix", "", "prefix text")
+			prefixDigits := fs.Int("prefix-digits", 0, "digits of the prefix number (0 = none)")
+			suffix := fs.String("suffix", "", "suffix text")
+			suffixDigits := fs.Int("suffix-digits", 0, "digits of the suffix number (0 = none)")
+			keep := fs.Bool("keep-original", true, "keep the original file name")
+			perFormat := fs.Bool("per-format", false, "number each file format separately")
+			fromZero := fs.Bool("start-from-zero", true, "start numbering from 0")
+			return func() (model.RenameConfig, error) {
+				if *prefixDigits < 0 || *prefixDigits > 5 || *suffixDigits < 0 || *suffixDigits > 5 {
+					return model.RenameConfig{}, errors.New("number digits must be between 0 and 5")
+				}
+				return model.RenameConfig{
+					Type:                    model.RenameTypeBatch,
+					PrefixText:              *prefix,
+					PrefixDigits:            *prefixDigits,
+					SuffixText:              *suffix,
+					SuffixDigits:            *suffixDigits,
+					KeepOriginal:            *keep,
+					FormatSpecificNumbering: *perFormat,
+					StartFromZero:           *fromZero,
+				}, nil
+			}
+		},
+	},
+	"ext": {
+		name:    "ext",
+		summary: "change the file extension",
+		setup: func(fs *flag.FlagSet) func() (model.RenameConfig, error) {
+			to := fs.String("to", "", "new extension, e.g. .jpg")
+			return func() (model.RenameConfig, error) {
+				ext := strings.TrimSpace(*to)
+				if ext == "" || ext == "." {
+					return model.RenameConfig{}, errors.New("--to is required")
+				}
+				if !strings.HasPrefix(ext, ".") {
+					ext = "." + ext
+				}
+				return model.RenameConfig{Type: model.RenameTypeExtension, NewExtension: ext}, nil
+			}
+		},
+	},
+	"case": {
+		name:    "case",
+		summary: "convert the file name case",
+		setup: func(fs *flag.FlagSet) func() (model.RenameConfig, error) {
+			to := fs.String("to", "", "upper, lower, title or camel")
+			return func() (model.RenameConfig, error) {
+				switch *to {
+				case "upper", "lower", "title", "camel":
+					return model.RenameConfig{Type: model.RenameTypeCase, CaseType: *to}, nil
+				}
+				return model.RenameConfig{}, fmt.Errorf("--to must be upper, lower, title or camel, got %q", *to)
+			}
+		},
+	},
+	"insert": {
+		name:    "insert",
+		summary: "insert text at a character position",
+		setup: func(fs *flag.FlagSet) func() (model.RenameConfig, error) {
+			pos := fs.Int("pos", 0, "insert position (characters, 0 = start)")
+			text := fs.String("text", "", "text to insert")
+			return func() (model.RenameConfig, error) {
+				if *pos < 0 {
+					return model.RenameConfig{}, errors.New("--pos must not be negative")
+				}
+				if *text == "" {
+					return model.RenameConfig{}, errors.New("--text is required")
+				}
+				return model.RenameConfig{Type: model.RenameTypeInsertChar, InsertPosition: *pos, InsertText: *text}, nil
+			}
+		},
+	},
+	"delete": {
+		name:    "delete",
+		summary: "delete characters from the file name",
+		setup: func(fs *flag.FlagSet) func() (model.RenameConfig, error) {
+			pos := fs.Int("pos", 0, "start position (characters, 0 = start)")
+			length := fs.Int("len", 0, "number of characters to delete")
+			return func() (model.RenameConfig, error) {
+				if *pos < 0 || *length < 0 {
+					return model.RenameConfig{}, errors.New("--pos and --len must not be negative")
+				}
+				return model.RenameConfig{Type: model.RenameTypeDeleteChar, DeleteStartPosition: *pos, DeleteLength: *length}, nil
+			}
+		},
+	},
+	"replace": {
+		name:    "replace",
+		summary: "replace text or a regular expression",
+		setup: func(fs *flag.FlagSet) func() (model.RenameConfig, error) {
+			pattern := fs.String("pattern", "", "text or regular expression to find")
+			with := fs.String("with", "", "replacement text")
+			useRegex := fs.Bool("regex", false, "treat --pattern as a regular expression")
+			return func() (model.RenameConfig, error) {
+				if *pattern == "" {
+					return model.RenameConfig{}, errors.New("--pattern is required")
+				}
+				if *useRegex {
+					if _, err := regexp.Compile(*pattern); err != nil {
+						return model.RenameConfig{}, fmt.Errorf("invalid --pattern: %w", err)
+					}
+				}
+				return model.RenameConfig{Type: model.RenameTypeReplace, ReplacePattern: *pattern, ReplaceText: *with, UseRegex: *useRegex}, nil
+			}
+		},
+	},
+	"flatten": {
+		name:    "flatten",
+		summary: "move files from all subfolders into the directory (always recursive)",
+		setup: func(fs *flag.FlagSet) func() (model.RenameConfig, error) {
+			removeEmpty := fs.Bool("remove-empty", false, "remove folders left empty after the move")
+			return func() (model.RenameConfig, error) {
+				return model.RenameConfig{Type: model.RenameTypeFlatten, RemoveEmptyDirs: *removeEmpty}, nil
+			}
+		},
+	},
+}
+
+// options 解析后的命令行选项
+type options struct {
+	config    model.RenameConfig
+	dryRun    bool
+	recursive bool
+	json      bool
+	saveJob   string // 保存任务文件的路径，之后可用 renamer run 重新执行
+
+	skipProblems bool // 排除未通过预检的文件，执行其余文件
+}
+
+const skipProblemsUsage = "exclude files that fail the pre-flight check (conflicts, missing, locked, read-only, no write permission, invalid or too long names) and rename the rest"
+
+// parseOptions 解析子命令参数；允许选项出现在目录参数前后
+func parseOptions(cmd command, args []string, stderr io.Writer) (options, error) {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: renamer %s [options] <dir>\n\n%s\n\noptions:\n", cmd.name, cmd.summary)
+		fs.PrintDefaults()
+	}
+
+	var opts options
+	var formats, onConflict, suffixPattern, sortKey, lang, items, copyTo, linkTo string
+	var transactional, descending, keepStructure, hardlink, allowMove bool
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "print the plan without renaming")
+	fs.BoolVar(&opts.recursive, "recursive", false, "include files in subdirectories")
+	fs.StringVar(&formats, "formats", "", "comma separated extensions to include, e.g. jpg,png (default all)")
+	fs.StringVar(&items, "items", "files", "what to rename: files, folders or both (folders are renamed deepest first)")
+	fs.BoolVar(&opts.json, "json", false, "write the result as JSON")
+	fs.StringVar(&sortKey, "sort", "name", "order before numbering: name, natural, mtime, ctime, size or exif")
+	fs.BoolVar(&descending, "desc", false, "sort in descending order")
+	fs.StringVar(&lang, "lang", "", "collation language for name sorting: zh (pinyin), ja (gojuon) or en (default: interface language)")
+	fs.StringVar(&opts.saveJob, "save-job", "", "also save the options as a job file that 'renamer run' can rerun")
+	fs.BoolVar(&opts.skipProblems, "skip-problems", false, skipProblemsUsage)
+	fs.BoolVar(&transactional, "transaction", false, "all or nothing: roll back every rename of the batch if any file fails")
+	fs.StringVar(&copyTo, "copy-to", "", "write renamed copies into this folder and leave the originals untouched")
+	fs.StringVar(&linkTo, "link-to", "", "create symbolic links with the new names in this folder and leave the originals untouched")
+	fs.BoolVar(&hardlink, "hardlink", false, "with --link-to, create hard links instead of symbolic links")
+	fs.BoolVar(&allowMove, "allow-move", false, "allow new names containing folders (e.g. --with photos/), which moves files into subfolders of the directory")
+	fs.BoolVar(&keepStructure, "keep-structure", false, "with --copy-to or --link-to, keep the subfolders relative to the directory")
+	fs.StringVar(&onConflict, "on-conflict", "abort", "abort, skip, overwrite, suffix, prefix, newer or dedupe")
+	fs.StringVar(&suffixPattern, "suffix-pattern", antisamename.DefaultSuffixPattern, "suffix for --on-conflict suffix/dedupe, {n} or {n:03} is the number")
+	build := cmd.setup(fs)
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return opts, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return opts, errors.New("exactly one directory is required")
+	}
+
+	config, err := build()
+	if err != nil {
+		return opts, err
+	}
+	// 日志记录绝对路径，界面中的撤销不依赖命令行的工作目录
+	if config.SelectedDir, err = filepath.Abs(positional[0]); err != nil {
+		return opts, err
+	}
+	if config.ConflictPolicy, err = parseConflictPolicy(onConflict); err != nil {
+		return opts, err
+	}
+	if err = antisamename.ValidateSuffixPattern(suffixPattern); err != nil {
+		return opts, fmt.Errorf("invalid --suffix-pattern: %w", err)
+	}
+	config.SuffixPattern = suffixPattern
+	config.Transactional = transactional
+	config.AllowMove = allowMove
+	if config.SortKey, err = filesort.ParseKey(sortKey); err != nil {
+		return opts, fmt.Errorf("invalid --sort: %w", err)
+	}
+	config.SortDescending = descending
+	if config.Type == model.RenameTypeFlatten {
+		opts.recursive = true // 扁平化总是处理所有子文件夹
+	}
+	if config.Items, err = parseItems(items); err != nil {
+		return opts, err
+	}
+	if config.Mode, config.OutputDir, err = parseExecMode(copyTo, linkTo, hardlink); err != nil {
+		return opts, err
+	}
+	if config.Mode != model.ExecRename && config.Items != model.ItemFiles {
+		return opts, errors.New("--copy-to and --link-to handle files only, --items must be files")
+	}
+	if keepStructure && config.Mode == model.ExecRename {
+		return opts, errors.New("--keep-structure requires --copy-to or --link-to")
+	}
+	config.KeepStructure = keepStructure
+	switch lang {
+	case "":
+	case "zh", "en", "ja":
+		i18n.GetManager().SetLanguage(lang)
+	default:
+		return opts, fmt.Errorf("--lang must be zh, en or ja, got %q", lang)
+	}
+	for _, ext := range strings.Split(formats, ",") {
+		if ext = strings.TrimSpace(ext); ext != "" {
+			config.Formats = append(config.Formats, ext)
+		}
+	}
+	opts.config = config
+	return opts, nil
+}
+
+// parseItems 解析 --items
+func parseItems(name string) (model.ItemType, error) {
+	switch name {
+	case "files":
+		return model.ItemFiles, nil
+	case "folders":
+		return model.ItemFolders, nil
+	case "both":
+		return model.ItemBoth, nil
+	}
+	return model.ItemFiles, fmt.Errorf("--items must be files, folders or both, got %q", name)
+}
+
+// parseExecMode 解析 --copy-to、--link-to 与 --hardlink，返回执行方式和输出文件夹的绝对路径
+func parseExecMode(copyTo, linkTo string, hardlink bool) (model.ExecMode, string, error) {
+	switch {
+	case copyTo != "" && linkTo != "":
+		return model.ExecRename, "", errors.New("--copy-to and --link-to cannot be used together")
+	case hardlink && linkTo == "":
+		return model.ExecRename, "", errors.New("--hardlink requires --link-to")
+	case copyTo != "":
+		dir, err := filepath.Abs(copyTo)
+		return model.ExecCopy, dir, err
+	case linkTo != "":
+		mode := model.ExecSymlink
+		if hardlink {
+			mode = model.ExecHardlink
+		}
+		dir, err := filepath.Abs(linkTo)
+		return mode, dir, err
+	}
+	return model.ExecRename, "", nil
+}
+
+// parseConflictPolicy 解析 --on-conflict；命令行无法交互，不支持 ask
+func parseConflictPolicy(name string) (model.ConflictPolicy, error) {
+	switch policy := model.ConflictPolicy(name); policy {
+	case "abort":
+		return model.ConflictAbort, nil
+	case model.ConflictSkip, model.ConflictOverwrite, model.ConflictSuffix, model.ConflictPrefix, model.ConflictNewer, model.ConflictDedupe:
+		return policy, nil
+	}
+	return model.ConflictAbort, fmt.Errorf("--on-conflict must be abort, skip, overwrite, suffix, prefix, newer or dedupe, got %q", name)
+}
+
+// printUsage 输出总体帮助
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "usage: renamer <command> [options] <dir>")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-8s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(w, "  %-8s %s\n", jobCommand, "run a saved job file (options and manual order from the preview)")
+	fmt.Fprintf(w, "  %-8s %s\n", recoverCommand, "list, finish (--resume) or roll back (--rollback) interrupted batches")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "common options: --dry-run --recursive --formats jpg,png --items files|folders|both --json --transaction --skip-problems --allow-move")
+	fmt.Fprintln(w, "                --sort name|natural|mtime|ctime|size|exif --desc --lang zh|en|ja --save-job file")
+	fmt.Fprintln(w, "                --on-conflict abort|skip|overwrite|suffix|prefix|newer|dedupe --suffix-pattern \" ({n})\"")
+	fmt.Fprintln(w, "                --copy-to dir | --link-to dir [--hardlink] [--keep-structure]")
+	fmt.Fprintln(w, "run 'renamer <command> -h' for command options")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "exit codes: %d success, %d error, %d conflicts or pre-flight problems (nothing renamed), %d partial failure, %d rolled back (nothing renamed)\n",
+		ExitOK, ExitError, ExitConflict, ExitPartial, ExitRolledBack)
+}
+
+// 计划项状态
+const (
+	statusPlanned   = "planned"
+	statusRenamed   = "renamed"
+	statusUnchanged = "unchanged"
+	statusFailed    = "failed"
+	statusConflict  = "conflict"
+	statusProblem   = "problem"  // 未通过预检
+	statusExcluded  = "excluded" // --skip-problems：未通过预检，已排除
+	statusSkipped   = "skipped"
+	statusRestored  = "restored"        // recover --rollback：已恢复原名
+	statusRemoved   = "removed"         // recover --rollback：已删除执行时创建的文件夹
+	statusRecreated = "recreated"       // recover --rollback：已重新创建执行后删除的空文件夹
+	statusAborted   = "aborted"         // 事务中止，未执行
+	statusCancelled = "cancelled"       // 中断（Ctrl+C）后未执行
+	statusRolled    = "rolled-back"     // 已重命名，事务失败后恢复原名
+	statusStranded  = "rollback-failed" // 已重命名，未能恢复原名
+)
+
+// reportEntry 单个文件的输出记录
+type reportEntry struct {
+	Source     string `json:"source"`
+	Target     string `json:"target,omitempty"`
+	Status     string `json:"status"`
+	Resolution string `json:"resolution,omitempty"` // 冲突处理方式：skip/overwrite/suffix/dedupe
+	Error      string `json:"error,omitempty"`
+}
+
+// report 命令输出，--json 时直接序列化
+type report struct {
+	DryRun         bool          `json:"dryRun"`
+	Transaction    bool          `json:"transaction"`
+	Mode           string        `json:"mode,omitempty"` // copy、symlink 或 hardlink，原地重命名时为空
+	Total          int           `json:"total"`
+	Renamed        int           `json:"renamed"`
+	Skipped        int           `json:"skipped"`
+	Failed         int           `json:"failed"`
+	Cancelled      int           `json:"cancelled"`
+	RolledBack     int           `json:"rolledBack"`
+	RollbackFailed int           `json:"rollbackFailed"`
+	Excluded       int           `json:"excluded"`
+	Conflicts      []string      `json:"conflicts"`
+	Problems       []problem     `json:"problems"`
+	Unverified     []string      `json:"unverified"` // 试运行：未验证写入权限的文件夹
+	Entries        []reportEntry `json:"entries"`
+
+	index map[string]int // source -> Entries 下标
+}
+
+// problem 预检发现的一个问题
+type problem struct {
+	Source  string `json:"source"`
+	Target  string `json:"target,omitempty"`
+	Problem string `json:"problem"` // target/missing/no_write/read_only/locked/too_long/invalid_name/conflict
+	Detail  string `json:"detail,omitempty"`
+}
+
+// newReport 按计划顺序生成输出记录
+func newReport(plan *planner.Plan, conflicts []string, dryRun bool) *report {
+	rep := &report{
+		DryRun:      dryRun,
+		Transaction: plan.Config.Transactional,
+		Mode:        string(plan.Config.Mode),
+		Total:       plan.Len(),
+		Conflicts:   conflicts,
+		Problems:    []problem{},
+		Unverified:  []string{},
+		Entries:     make([]reportEntry, 0, plan.Len()),
+		index:       make(map[string]int, plan.Len()),
+	}
+	if rep.Conflicts == nil {
+		rep.Conflicts = []string{}
+	}
+
+	conflictSet := make(map[string]bool, len(conflicts))
+	for _, path := range conflicts {
+		conflictSet[path] = true
+	}
+
+	for _, entry := range plan.Entries {
+		e := reportEntry{Source: entry.Source, Target: entry.Target, Status: statusPlanned, Resolution: string(entry.Resolution)}
+		switch {
+		case entry.Err != nil:
+			e.Status, e.Error = statusConflict, entry.Err.Error()
+		case conflictSet[entry.Target]:
+			e.Status = statusConflict
+		case entry.Resolution == planner.ResolutionSkip:
+			e.Status = statusSkipped
+			rep.Skipped++
+		case entry.Source == entry.Target:
+			e.Status = statusUnchanged
+		}
+		rep.index[entry.Source] = len(rep.Entries)
+		rep.Entries = append(rep.Entries, e)
+	}
+	return rep
+}
+
+// preflight 写入预检结果：issues 为仍在计划中的问题，excluded 为已排除的文件的问题，
+// unverified 为试运行时未验证写入权限的文件夹
+func (r *report) preflight(issues, excluded []preflight.Issue, unverified []string) {
+	if unverified != nil {
+		r.Unverified = unverified
+	}
+	for _, issue := range issues {
+		r.Problems = append(r.Problems, problem{Source: issue.Source, Target: issue.Target, Problem: string(issue.Problem), Detail: issue.Detail})
+		if i, ok := r.index[issue.Source]; ok && r.Entries[i].Status == statusPlanned {
+			r.Entries[i].Status, r.Entries[i].Error = statusProblem, describeIssue(issue)
+		}
+	}
+	// 排除的文件已不在计划中，追加在末尾；一个文件的多个问题合并为一条
+	for _, issue := range excluded {
+		if i, ok := r.index[issue.Source]; ok {
+			r.Entries[i].Error += "; " + describeIssue(issue)
+			continue
+		}
+		r.index[issue.Source] = len(r.Entries)
+		r.Entries = append(r.Entries, reportEntry{Source: issue.Source, Target: issue.Target, Status: statusExcluded, Error: describeIssue(issue)})
+		r.Excluded++
+		r.Total++
+	}
+}
+
+// describeIssue 问题类型加上相关的路径或名称
+func describeIssue(issue preflight.Issue) string {
+	if issue.Detail == "" {
+		return string(issue.Problem)
+	}
+	return string(issue.Problem) + ": " + issue.Detail
+}
+
+// record 写入执行结果
+func (r *report) record(result executor.Result) {
+	i, ok := r.index[result.Source]
+	if !ok {
+		return
+	}
+	e := &r.Entries[i]
+	switch {
+	case result.RollbackErr != nil:
+		if e.Status == statusRenamed {
+			r.Renamed--
+		}
+		e.Status, e.Error = statusStranded, result.RollbackErr.Error()
+		r.RollbackFailed++
+	case result.RolledBack:
+		if e.Status == statusRenamed {
+			r.Renamed--
+		}
+		e.Status = statusRolled
+		r.RolledBack++
+	case errors.Is(result.Err, executor.ErrAborted):
+		e.Status = statusAborted
+	case errors.Is(result.Err, executor.ErrCancelled):
+		e.Status = statusCancelled
+		r.Cancelled++
+	case result.Err != nil:
+		e.Status, e.Error = statusFailed, result.Err.Error()
+		r.Failed++
+	case e.Status == statusPlanned:
+		e.Status = statusRenamed
+		r.Renamed++
+	}
+}
+
+// exitCode 根据结果计算退出码
+func (r *report) exitCode() int {
+	switch {
+	case len(r.Conflicts) > 0, len(r.Problems) > 0:
+		return ExitConflict
+	case r.Transaction && r.Failed+r.Cancelled > 0 && r.RollbackFailed == 0:
+		return ExitRolledBack
+	case r.Failed > 0, r.Cancelled > 0:
+		return ExitPartial
+	}
+	return ExitOK
+}
+
+// write 输出结果并返回退出码
+func (r *report) write(opts options, stdout, stderr io.Writer) int {
+	code := r.exitCode()
+	if opts.json {
+		if c := writeJSON(r, stdout, stderr); c != ExitOK {
+			return c
+		}
+		return code
+	}
+
+	for _, e := range r.Entries {
+		switch e.Status {
+		case statusFailed, statusConflict, statusStranded, statusProblem, statusExcluded:
+			if e.Error != "" {
+				fmt.Fprintf(stderr, "%s: %s: %s\n", e.Status, e.Source, e.Error)
+			} else {
+				fmt.Fprintf(stderr, "%s: %s -> %s\n", e.Status, e.Source, e.Target)
+			}
+		case statusUnchanged:
+		case statusAborted, statusRolled, statusCancelled:
+			fmt.Fprintf(stderr, "%s: %s\n", e.Status, e.Source)
+		case statusSkipped:
+			fmt.Fprintf(stdout, "%s: %s (target exists: %s)\n", e.Status, e.Source, e.Target)
+		default:
+			if e.Resolution != "" {
+				fmt.Fprintf(stdout, "%s -> %s [%s]\n", e.Source, e.Target, e.Resolution)
+			} else {
+				fmt.Fprintf(stdout, "%s -> %s\n", e.Source, e.Target)
+			}
+		}
+	}
+
+	if r.Excluded > 0 {
+		fmt.Fprintf(stderr, "%d file(s) excluded by the pre-flight check\n", r.Excluded)
+	}
+	if len(r.Unverified) > 0 {
+		fmt.Fprintf(stderr, "write permission not verified for %d folder(s): the dry run does not write to disk\n", len(r.Unverified))
+	}
+	switch {
+	case len(r.Conflicts) > 0:
+		fmt.Fprintf(stderr, "%d conflicting target(s), nothing renamed\n", len(r.Conflicts))
+	case len(r.Problems) > 0:
+		fmt.Fprintf(stderr, "%d problem(s) found before renaming, nothing renamed (use --skip-problems to exclude the files and rename the rest)\n", len(r.Problems))
+	case r.DryRun && r.Mode != "":
+		fmt.Fprintf(stdout, "dry run: %d file(s) planned (%s)\n", r.Total-r.Excluded, r.Mode)
+	case r.DryRun:
+		fmt.Fprintf(stdout, "dry run: %d file(s) planned\n", r.Total-r.Excluded)
+	case r.Cancelled > 0 && !r.Transaction:
+		fmt.Fprintf(stderr, "cancelled: %d renamed, %d failed, %d not renamed\n", r.Renamed, r.Failed, r.Cancelled)
+	case r.Transaction && r.Failed+r.Cancelled > 0 && r.RollbackFailed > 0:
+		fmt.Fprintf(stderr, "%d failed, rollback incomplete: %d file(s) could not be restored\n", r.Failed, r.RollbackFailed)
+	case r.Transaction && r.Failed+r.Cancelled > 0:
+		fmt.Fprintf(stderr, "%d failed, %d cancelled, rolled back %d rename(s), nothing renamed\n", r.Failed, r.Cancelled, r.RolledBack)
+	default:
+		fmt.Fprintf(stdout, "%d renamed, %d skipped, %d failed, %d total\n", r.Renamed, r.Skipped, r.Failed, r.Total)
+	}
+	return code
+}
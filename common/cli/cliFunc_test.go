@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rename-tool/common/executor"
+	"rename-tool/common/planner"
+	"rename-tool/setting/model"
+)
+
+func TestReportExitCodeAfterFailure(t *testing.T) {
+	tests := []struct {
+		name          string
+		transactional bool
+		want          int
+		wantStatus    []string // 各计划项最终的状态
+	}{
+		{"transaction rolls back", true, ExitRolledBack, []string{statusRolled, statusFailed}},
+		{"partial without a transaction", false, ExitPartial, []string{statusRenamed, statusFailed}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APPDATA", t.TempDir())
+			dir := t.TempDir()
+			// 第 0 项更深，先执行；第 1 项的目标已被占用，执行时失败
+			for _, rel := range []string{"sub/a", "b", "taken"} {
+				path := filepath.Join(dir, filepath.FromSlash(rel))
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(rel), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			plan := &planner.Plan{
+				Config: model.RenameConfig{Transactional: tt.transactional, SelectedDir: dir},
+				Entries: []planner.Entry{
+					{Source: filepath.Join(dir, "sub", "a"), Target: filepath.Join(dir, "sub", "A")},
+					{Source: filepath.Join(dir, "b"), Target: filepath.Join(dir, "taken")},
+				},
+			}
+
+			rep := newReport(plan, nil, false)
+			for result := range executor.Run(context.Background(), plan, nil) {
+				rep.record(result)
+			}
+			if got := rep.exitCode(); got != tt.want {
+				t.Errorf("exit code = %d, want %d", got, tt.want)
+			}
+			for i, want := range tt.wantStatus {
+				if got := rep.Entries[i].Status; got != want {
+					t.Errorf("entry %d status = %s, want %s", i, got, want)
+				}
+			}
+		})
+	}
+}
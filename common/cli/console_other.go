@@ -0,0 +1,6 @@
+//go:build !windows
+
+package cli
+
+// attachConsole 非 Windows 平台直接使用当前终端
+func attachConsole() {}
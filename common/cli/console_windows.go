@@ -0,0 +1,39 @@
+//go:build windows
+
+package cli
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// attachConsole 程序以 -H windowsgui 构建时没有控制台，从终端启动时连接父进程控制台以输出结果
+// 已被重定向到文件或管道的输出保持不变
+func attachConsole() {
+	stdout, _ := windows.GetStdHandle(windows.STD_OUTPUT_HANDLE)
+	stderr, _ := windows.GetStdHandle(windows.STD_ERROR_HANDLE)
+	if stdout != 0 && stdout != windows.InvalidHandle && stderr != 0 && stderr != windows.InvalidHandle {
+		return
+	}
+
+	proc := windows.NewLazySystemDLL("kernel32.dll").NewProc("AttachConsole")
+	if proc.Find() != nil {
+		return
+	}
+	const attachParentProcess = ^uintptr(0) // ATTACH_PARENT_PROCESS (-1)
+	if ok, _, _ := proc.Call(attachParentProcess); ok == 0 {
+		return // 父进程没有控制台（例如从资源管理器启动）
+	}
+
+	out, err := os.OpenFile("CONOUT$", os.O_WRONLY, 0)
+	if err != nil {
+		return
+	}
+	if stdout == 0 || stdout == windows.InvalidHandle {
+		os.Stdout = out
+	}
+	if stderr == 0 || stderr == windows.InvalidHandle {
+		os.Stderr = out
+	}
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"rename-tool/common/jobfile"
+	"rename-tool/setting/model"
+)
+
+// jobCommand 执行已保存任务文件的子命令名
+const jobCommand = "run"
+
+// runJob 执行界面预览或 --save-job 保存的任务，包括其中的手动顺序
+func runJob(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet(jobCommand, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var opts options
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "print the plan without renaming")
+	fs.BoolVar(&opts.json, "json", false, "write the result as JSON")
+	fs.BoolVar(&opts.skipProblems, "skip-problems", false, skipProblemsUsage)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: renamer %s [--dry-run] [--json] [--skip-problems] <job file>\n\nrun a saved rename job\n\noptions:\n", jobCommand)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitError
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return ExitError
+	}
+
+	job, err := jobfile.Load(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", jobCommand, err)
+		return ExitError
+	}
+	if job.Config.ConflictPolicy == model.ConflictAsk {
+		// 命令行无法逐个询问，按默认策略列出冲突并中止
+		job.Config.ConflictPolicy = model.ConflictAbort
+	}
+	opts.config = job.Config
+	opts.recursive = job.Recursive
+	return execute(jobCommand, opts, stdout, stderr)
+}
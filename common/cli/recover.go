@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"rename-tool/common/executor"
+	"rename-tool/common/journal"
+)
+
+// recoverCommand 处理中断批次的子命令名
+const recoverCommand = "recover"
+
+// interruptedBatch recover 列表中的一个批次
+type interruptedBatch struct {
+	ID        string `json:"id"`
+	Started   string `json:"started"`
+	Dir       string `json:"dir"`
+	Renamed   int    `json:"renamed"`
+	Remaining int    `json:"remaining"`
+}
+
+// runRecover 列出中断的批次，或对其继续执行剩余文件（--resume）/回滚已完成的文件（--rollback）
+// 与界面启动时的询问使用同一份预写日志
+func runRecover(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet(recoverCommand, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	resume := fs.Bool("resume", false, "finish the remaining renames of the interrupted batches")
+	rollback := fs.Bool("rollback", false, "restore the files the interrupted batches already renamed")
+	id := fs.String("op", "", "only handle the batch with this id (default all interrupted batches)")
+	asJSON := fs.Bool("json", false, "write the result as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: renamer %s [--resume | --rollback] [--op id]\n\nlist, finish or roll back rename batches that were interrupted\n\noptions:\n", recoverCommand)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitError
+	}
+	if *resume && *rollback {
+		fmt.Fprintf(stderr, "%s: --resume and --rollback cannot be used together\n", recoverCommand)
+		return ExitError
+	}
+
+	if _, err := journal.RecoverInterrupted(); err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", recoverCommand, err)
+		return ExitError
+	}
+	ops, err := journal.Interrupted()
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", recoverCommand, err)
+		return ExitError
+	}
+	if *id != "" {
+		var selected []*journal.OperationLog
+		for _, op := range ops {
+			if op.ID == *id {
+				selected = append(selected, op)
+			}
+		}
+		if len(selected) == 0 {
+			fmt.Fprintf(stderr, "%s: no interrupted batch with id %s\n", recoverCommand, *id)
+			return ExitError
+		}
+		ops = selected
+	}
+
+	switch {
+	case *resume:
+		return resumeBatches(ops, *asJSON, stdout, stderr)
+	case *rollback:
+		return rollbackBatches(ops, *asJSON, stdout, stderr)
+	}
+	return listBatches(ops, *asJSON, stdout, stderr)
+}
+
+// listBatches 输出中断批次列表
+func listBatches(ops []*journal.OperationLog, asJSON bool, stdout, stderr io.Writer) int {
+	batches := make([]interruptedBatch, 0, len(ops))
+	for _, op := range ops {
+		batches = append(batches, interruptedBatch{
+			ID:        op.ID,
+			Started:   op.Started.Format("2006-01-02 15:04:05"),
+			Dir:       op.Dir,
+			Renamed:   len(op.Undoable()),
+			Remaining: len(op.Remaining()),
+		})
+	}
+	if asJSON {
+		return writeJSON(batches, stdout, stderr)
+	}
+	if len(batches) == 0 {
+		fmt.Fprintln(stdout, "no interrupted batches")
+		return ExitOK
+	}
+	for _, b := range batches {
+		fmt.Fprintf(stdout, "%s  %s  %s  %d renamed, %d remaining\n", b.ID, b.Started, b.Dir, b.Renamed, b.Remaining)
+	}
+	fmt.Fprintf(stdout, "run 'renamer %s --resume' or 'renamer %s --rollback'\n", recoverCommand, recoverCommand)
+	return ExitOK
+}
+
+// resumeBatches 依次执行各批次剩余的文件，输出格式与普通重命名相同
+func resumeBatches(ops []*journal.OperationLog, asJSON bool, stdout, stderr io.Writer) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	code := ExitOK
+	for _, op := range ops {
+		plan := executor.RemainingPlan(op)
+		resultChan, err := executor.Resume(ctx, op, plan, nil)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %s: %v\n", recoverCommand, op.ID, err)
+			return ExitError
+		}
+		rep := newReport(plan, nil, false)
+		for result := range resultChan {
+			rep.record(result)
+		}
+		if c := rep.write(options{json: asJSON}, stdout, stderr); c != ExitOK {
+			code = c
+		}
+	}
+	return code
+}
+
+// rollbackBatches 依次恢复各批次已完成的文件
+func rollbackBatches(ops []*journal.OperationLog, asJSON bool, stdout, stderr io.Writer) int {
+	var entries []reportEntry
+	failed := 0
+	for _, op := range ops {
+		results, err := executor.Rollback(op)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %s: %v\n", recoverCommand, op.ID, err)
+			return ExitError
+		}
+		for _, result := range results {
+			e := reportEntry{Source: result.Target, Target: result.Source, Status: statusRestored}
+			if result.Source == "" {
+				e.Status = statusRemoved // 执行时创建的文件夹
+			}
+			if result.Target == "" {
+				e.Status = statusRecreated // 执行后删除的空文件夹
+			}
+			if result.Err != nil {
+				e.Status, e.Error = statusFailed, result.Err.Error()
+				failed++
+			}
+			entries = append(entries, e)
+		}
+	}
+
+	code := ExitOK
+	if failed > 0 {
+		code = ExitPartial
+	}
+	if asJSON {
+		if c := writeJSON(entries, stdout, stderr); c != ExitOK {
+			return c
+		}
+		return code
+	}
+	for _, e := range entries {
+		if e.Error != "" {
+			fmt.Fprintf(stderr, "%s: %s: %s\n", e.Status, e.Source, e.Error)
+			continue
+		}
+		if e.Status == statusRemoved {
+			fmt.Fprintf(stdout, "removed folder %s\n", e.Source)
+			continue
+		}
+		if e.Status == statusRecreated {
+			fmt.Fprintf(stdout, "recreated folder %s\n", e.Target)
+			continue
+		}
+		fmt.Fprintf(stdout, "%s -> %s\n", e.Source, e.Target)
+	}
+	fmt.Fprintf(stdout, "%d restored, %d failed\n", len(entries)-failed, failed)
+	return code
+}
+
+// writeJSON 以缩进格式输出 JSON
+func writeJSON(v any, stdout, stderr io.Writer) int {
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	return ExitOK
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"rename-tool/common/filestatus"
+	"rename-tool/setting/model"
 	"strings"
 )
 
@@ -47,9 +48,20 @@ func mapExt(formats []string) map[string]bool {
 	return m
 }
 
+// includeItem 按重命名对象和扩展名判断是否包含该项；扩展名只过滤文件
+func includeItem(name string, dir bool, items model.ItemType, formatsMap map[string]bool) bool {
+	if dir {
+		return items != model.ItemFiles
+	}
+	if items == model.ItemFolders {
+		return false
+	}
+	return len(formatsMap) == 0 || formatsMap[strings.ToLower(filepath.Ext(name))]
+}
+
 // walkDirFiltered 统一封装遍历逻辑：
-// 按扩展名过滤文件，并为每个文件调用 fn(name string)。
-func walkDirFilteredWalk(root string, formats []string, fn func(path string, info os.FileInfo)) error {
+// 按重命名对象和扩展名过滤，并为每一项调用 fn(name string)。
+func walkDirFilteredWalk(root string, formats []string, items model.ItemType, fn func(path string, info os.FileInfo)) error {
 	formatsMap := mapExt(formats)
 
 	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
@@ -59,17 +71,17 @@ func walkDirFilteredWalk(root string, formats []string, fn func(path string, inf
 			}
 			return fmt.Errorf("%s: %w", textTr("failReadFiles"), err)
 		}
-		if info.IsDir() {
+		if path == root {
 			return nil
 		}
-		if len(formatsMap) == 0 || formatsMap[strings.ToLower(filepath.Ext(path))] {
+		if includeItem(path, info.IsDir(), items, formatsMap) {
 			fn(path, info)
 		}
 		return nil
 	})
 }
 
-func walkDirFiltered(root string, formats []string, fn func(path string, info os.FileInfo)) error {
+func walkDirFiltered(root string, formats []string, items model.ItemType, fn func(path string, info os.FileInfo)) error {
 	formatsMap := mapExt(formats)
 
 	entries, err := os.ReadDir(root)
@@ -81,7 +93,7 @@ func walkDirFiltered(root string, formats []string, fn func(path string, info os
 	}
 
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if !includeItem(entry.Name(), entry.IsDir(), items, formatsMap) {
 			continue
 		}
 
@@ -92,11 +104,7 @@ func walkDirFiltered(root string, formats []string, fn func(path string, info os
 			}
 			return fmt.Errorf("%s: %w", textTr("failReadFiles"), err)
 		}
-
-		ext := strings.ToLower(filepath.Ext(entry.Name()))
-		if len(formatsMap) == 0 || formatsMap[ext] {
-			fn(filepath.Join(root, entry.Name()), info)
-		}
+		fn(filepath.Join(root, entry.Name()), info)
 	}
 
 	return nil
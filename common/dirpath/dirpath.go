@@ -4,7 +4,9 @@ import (
 	"errors"
 	"os"
 
+	"rename-tool/common/filesort"
 	"rename-tool/setting/global"
+	"rename-tool/setting/model"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -12,17 +14,23 @@ import (
 	"fyne.io/fyne/v2/widget"
 )
 
-// GetFiles 获取指定目录下符合格式的所有文件
+// GetFiles 获取指定目录下符合格式的所有文件，按界面语言的自然顺序排列
 func GetFiles(root string, formats []string, recursive bool) ([]string, error) {
+	return GetItems(root, formats, recursive, model.ItemFiles)
+}
+
+// GetItems 获取指定目录下要重命名的文件和/或文件夹（不含目录本身），按界面语言的自然顺序排列
+// formats 只过滤文件，文件夹全部包含
+func GetItems(root string, formats []string, recursive bool, items model.ItemType) ([]string, error) {
 	var files []string
 
 	var err error
 	if recursive {
-		err = walkDirFilteredWalk(root, formats, func(path string, _ os.FileInfo) {
+		err = walkDirFilteredWalk(root, formats, items, func(path string, _ os.FileInfo) {
 			files = append(files, path)
 		})
 	} else {
-		err = walkDirFiltered(root, formats, func(path string, _ os.FileInfo) {
+		err = walkDirFiltered(root, formats, items, func(path string, _ os.FileInfo) {
 			files = append(files, path)
 		})
 	}
@@ -30,14 +38,14 @@ func GetFiles(root string, formats []string, recursive bool) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	return files, nil
+	return filesort.Sort(files, model.SortNatural, false), nil
 }
 
 // GetShortestFilenameLength 返回目录中文件名的最短长度（忽略子目录）
 func GetShortestFilenameLength(dir string) (int, error) {
 	minLen := -1
 
-	err := walkDirFiltered(dir, nil, func(path string, info os.FileInfo) {
+	err := walkDirFiltered(dir, nil, model.ItemFiles, func(path string, info os.FileInfo) {
 		nameLen := len(info.Name())
 		if minLen == -1 || nameLen < minLen {
 			minLen = nameLen
@@ -84,3 +92,22 @@ func CreateDirSelector(win fyne.Window, onDirChanged func()) fyne.CanvasObject {
 
 	return container.NewHBox(label, button)
 }
+
+// CreateOutputDirSelector 创建输出文件夹选择器组件，选择结果通过 onSelected 返回，不修改所选目录
+func CreateOutputDirSelector(win fyne.Window, onSelected func(dir string)) fyne.CanvasObject {
+	label := widget.NewLabel(buttonTr("outputDir") + ": ")
+	button := widget.NewButton(buttonTr("selectOutputDir"), func() {
+		dialog.NewFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil {
+				logEvent("PATH ERROR", "folderOpenError", err)
+				return
+			}
+			if uri != nil {
+				label.SetText(buttonTr("outputDir") + ": " + truncatePathMiddle(uri.Path(), 50))
+				onSelected(uri.Path())
+			}
+		}, win).Show()
+	})
+
+	return container.NewHBox(label, button)
+}
@@ -0,0 +1,257 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"rename-tool/common/applog"
+	"rename-tool/common/filestatus"
+	"rename-tool/common/journal"
+	"rename-tool/common/planner"
+	"rename-tool/setting/global"
+	"rename-tool/setting/model"
+)
+
+// Result 单个计划项的执行结果
+type Result struct {
+	Source     string
+	Target     string
+	Err        error
+	Resolution planner.Resolution // 冲突处理结果
+
+	// 事务模式下，失败后对已完成计划项的回滚结果（同一计划项会先产生一次成功结果）
+	RolledBack  bool  // 已恢复原名
+	RollbackErr error // 恢复原名失败的原因
+}
+
+// ErrCancelled 用户取消后尚未开始的计划项，保持原名
+var ErrCancelled = errors.New("not renamed: cancelled")
+
+// logMu 保护工作协程并发追加 global.Logs
+var logMu sync.Mutex
+
+// Run 使用工作池执行重命名计划，不依赖任何界面，GUI 与命令行共用
+// 结果按完成顺序写入返回的通道，全部完成后通道关闭；生成阶段出错的计划项直接作为失败结果返回
+// 每个重命名前后都会写入持久化日志，用于跨重启撤销和中断检测
+// 目标与其他计划项的源重叠时（依赖链、互换、环），按 schedule 的顺序经临时名执行
+// 计划配置为事务模式时，任一文件失败即停止执行，并按相反顺序回滚本批次已完成的重命名
+// ctx 取消后不再开始新的重命名，尚未开始的计划项以 ErrCancelled 结束；tracker 可为 nil
+func Run(ctx context.Context, plan *planner.Plan, tracker *Tracker) <-chan Result {
+	op, err := journal.Begin(string(plan.Config.Type), string(plan.Config.Mode), plan.Config.SelectedDir, plan.Len())
+	if err != nil && applog.Logger != nil {
+		applog.Logger.Printf("[JOURNAL ERROR] %v", err)
+	}
+
+	// 预写整个批次，中断后可以继续剩余的文件或回滚已完成的文件
+	planned := make([]journal.Planned, 0, plan.Len())
+	for _, entry := range plan.Entries {
+		if entry.Err == nil && entry.Source != entry.Target {
+			planned = append(planned, journal.Planned{Source: entry.Source, Target: entry.Target, Resolution: string(entry.Resolution)})
+		}
+	}
+	if err := op.Plan(planned); err != nil && applog.Logger != nil {
+		applog.Logger.Printf("[JOURNAL ERROR] %v", err)
+	}
+	return run(ctx, op, plan, tracker)
+}
+
+// RemainingPlan 根据预写日志生成中断操作中尚未完成的计划
+func RemainingPlan(opLog *journal.OperationLog) *planner.Plan {
+	plan := &planner.Plan{Config: model.RenameConfig{Type: model.RenameType(opLog.Type), Mode: model.ExecMode(opLog.Mode), SelectedDir: opLog.Dir}}
+	for _, p := range opLog.Remaining() {
+		plan.Entries = append(plan.Entries, planner.Entry{Source: p.Source, Target: p.Target, Resolution: planner.Resolution(p.Resolution)})
+	}
+	return plan
+}
+
+// Resume 继续执行中断操作的剩余计划（由 RemainingPlan 生成），记录追加到原操作，撤销时整个批次一起处理
+func Resume(ctx context.Context, opLog *journal.OperationLog, plan *planner.Plan, tracker *Tracker) (<-chan Result, error) {
+	op, err := journal.Resume(opLog.ID)
+	if err != nil {
+		return nil, err
+	}
+	return run(ctx, op, plan, tracker), nil
+}
+
+// Rollback 撤销中断操作中已完成的重命名，并将操作标记为结束
+func Rollback(opLog *journal.OperationLog) ([]Result, error) {
+	results, err := Undo(opLog, opLog.Undoable())
+	if err != nil {
+		return nil, err
+	}
+	op, err := journal.Resume(opLog.ID)
+	if err != nil {
+		return results, err
+	}
+	return results, op.End()
+}
+
+// run 在已打开的操作中执行计划
+// 取消只在 job 之间生效：依赖链或环一旦开始就执行完，不会把文件留在临时名上
+// 按路径深度从深到浅分批执行：文件夹中的项目先于文件夹本身重命名，计划中的路径始终有效
+func run(ctx context.Context, op *journal.Operation, plan *planner.Plan, tracker *Tracker) <-chan Result {
+	tx := newTransaction(plan.Config.Transactional, plan.Config.Mode)
+	dirs := &folders{}
+	levels := byDepth(schedule(plan.Entries))
+	resultChan := make(chan Result, 2*plan.Len()) // 事务回滚时每个计划项可能再产生一个结果
+
+	go func() {
+		for _, jobs := range levels {
+			runLevel(ctx, op, tx, dirs, tracker, jobs, resultChan)
+		}
+		tx.finish(op, dirs, resultChan)
+		// 扁平化后删除变空的文件夹；取消或回滚时目录结构保持不变
+		if plan.Config.RemoveEmptyDirs && !keepsSources(plan.Config.Mode) && ctx.Err() == nil && !tx.rolledBack() {
+			removeEmptied(op, plan)
+		}
+		if err := op.End(); err != nil && applog.Logger != nil {
+			applog.Logger.Printf("[JOURNAL ERROR] %v", err)
+		}
+		close(resultChan)
+	}()
+
+	return resultChan
+}
+
+// runLevel 使用工作池并行执行同一深度的 job，全部完成后返回
+func runLevel(ctx context.Context, op *journal.Operation, tx *transaction, dirs *folders, tracker *Tracker, jobs []job, results chan<- Result) {
+	jobChan := make(chan job, len(jobs))
+	for _, j := range jobs {
+		jobChan <- j
+	}
+	close(jobChan)
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(runtime.NumCPU(), len(jobs)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobChan {
+				if ctx.Err() != nil {
+					tx.fail() // 事务模式下取消等同失败，回滚已完成的重命名
+					cancelJob(j, tracker, results)
+					continue
+				}
+				runJob(op, tx, dirs, tracker, j, results)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// runJob 按顺序执行一组步骤；某个计划项的中间步骤失败时，该计划项立即以失败结束
+// 事务已中止时不再执行剩余步骤，对应计划项以 ErrAborted 结束
+func runJob(op *journal.Operation, tx *transaction, dirs *folders, tracker *Tracker, j job, results chan<- Result) {
+	failed := make(map[string]bool)
+	for _, s := range j {
+		if failed[s.entry.Source] {
+			continue
+		}
+		if tx.aborted() {
+			if s.final {
+				results <- Result{Source: s.entry.Source, Target: s.entry.Target, Err: ErrAborted, Resolution: s.entry.Resolution}
+				tracker.advance()
+			}
+			continue
+		}
+		tracker.begin(s.entry.Source)
+		err := execute(op, tx, dirs, tracker, s)
+		if err != nil {
+			tx.fail()
+			failed[s.entry.Source] = true
+			if s.from != s.entry.Source {
+				err = fmt.Errorf("%w (file is kept at %s)", err, s.from)
+			}
+		}
+		if err != nil || s.final {
+			results <- Result{Source: s.entry.Source, Target: s.entry.Target, Err: err, Resolution: s.entry.Resolution}
+			tracker.advance()
+		}
+	}
+}
+
+// cancelJob 取消后未开始的 job：其中每个计划项以 ErrCancelled 结束
+func cancelJob(j job, tracker *Tracker, results chan<- Result) {
+	for _, s := range j {
+		if s.final {
+			results <- Result{Source: s.entry.Source, Target: s.entry.Target, Err: ErrCancelled, Resolution: s.entry.Resolution}
+			tracker.advance()
+		}
+	}
+}
+
+// execute 执行单个步骤并记录撤销日志
+// pending 记录写入失败时不执行重命名，保证磁盘上的每次修改都能在日志中找到
+// 目标位于其他文件夹（模板中的子文件夹、复制或链接模式的输出文件夹）时，先创建缺少的文件夹
+func execute(op *journal.Operation, tx *transaction, dirs *folders, tracker *Tracker, s step) error {
+	if s.entry.Err != nil {
+		return s.entry.Err
+	}
+	res := string(s.resolution)
+	if s.resolution == planner.ResolutionSkip {
+		op.Skipped(s.from, s.to, res)
+		return nil
+	}
+	if s.from == s.to {
+		return nil
+	}
+	if dir := filepath.Dir(s.to); s.resolution != planner.ResolutionDedupe && dir != filepath.Dir(s.from) {
+		if err := dirs.ensure(op, dir); err != nil {
+			return err
+		}
+	}
+	if err := op.Pending(s.from, s.to, res); err != nil {
+		return err
+	}
+
+	var err error
+	switch {
+	case keepsSources(tx.mode):
+		err = tx.place(s)
+	case s.resolution == planner.ResolutionOverwrite:
+		err = tx.replace(s.from, s.to)
+	case s.resolution == planner.ResolutionDedupe:
+		err = os.Remove(s.from) // 目标已有相同内容，删除重复的源文件
+	default:
+		// 目标在其他磁盘时复制、校验后删除源文件，进度显示在当前文件上
+		err = filestatus.RenameFileWithProgress(s.from, s.to, tracker.copying)
+	}
+	if err != nil {
+		op.Failed(s.from, s.to, res, err)
+		return err
+	}
+	op.Done(s.from, s.to, res)
+	tx.record(s)
+	appendRenameLog(s.from, s.to, res)
+	return nil
+}
+
+// appendRenameLog 追加本次会话的重命名日志，供保存日志使用
+func appendRenameLog(original, newPath, resolution string) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	global.Logs = append(global.Logs, global.RenameLog{
+		Original:   original,
+		New:        newPath,
+		Time:       time.Now().Format("2006-01-02 15:04:05"),
+		Resolution: resolution,
+	})
+}
+
+// removeRenameLog 从本次会话的内存日志中移除已撤销的记录
+func removeRenameLog(original, newPath string) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	for i, log := range global.Logs {
+		if log.Original == original && log.New == newPath {
+			global.Logs = append(global.Logs[:i], global.Logs[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package executor
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"rename-tool/common/applog"
+	"rename-tool/common/journal"
+	"rename-tool/common/planner"
+)
+
+// ErrFolderNotEmpty 执行时创建的文件夹中还有其他文件，撤销时保留该文件夹
+var ErrFolderNotEmpty = errors.New("created folder is not empty")
+
+// folders 本批次执行时创建的文件夹（模板中的子文件夹），工作协程共用
+type folders struct {
+	mu      sync.Mutex
+	created []string // 按创建顺序，上级在前
+}
+
+// ensure 创建目标文件夹及其缺少的上级文件夹，逐级写入日志，撤销时可以逐级删除
+// 与其他记录相同，先写 pending 再创建
+func (f *folders) ensure(op *journal.Operation, dir string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Lstat(d); err == nil || filepath.Dir(d) == d {
+			break
+		}
+		missing = append(missing, d)
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		d := missing[i]
+		if err := op.Pending("", d, journal.ResolutionMkdir); err != nil {
+			return err
+		}
+		if err := os.Mkdir(d, 0755); err != nil {
+			op.Failed("", d, journal.ResolutionMkdir, err)
+			return err
+		}
+		op.Done("", d, journal.ResolutionMkdir)
+		f.created = append(f.created, d)
+	}
+	return nil
+}
+
+// removeEmptied 删除因移出文件而变空的文件夹（不含所选目录），从深到浅逐个写入日志，撤销时重新创建
+func removeEmptied(op *journal.Operation, plan *planner.Plan) {
+	root := filepath.Clean(plan.Config.SelectedDir)
+	candidates := make(map[string]bool)
+	for _, entry := range plan.Entries {
+		if entry.Err != nil || entry.Resolution == planner.ResolutionSkip || filepath.Dir(entry.Source) == filepath.Dir(entry.Target) {
+			continue
+		}
+		for d := filepath.Dir(entry.Source); strings.HasPrefix(d, root+string(filepath.Separator)); d = filepath.Dir(d) {
+			candidates[d] = true
+		}
+	}
+
+	dirs := make([]string, 0, len(candidates))
+	for d := range candidates {
+		dirs = append(dirs, d)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if di, dj := depth(dirs[i]), depth(dirs[j]); di != dj {
+			return di > dj
+		}
+		return dirs[i] < dirs[j]
+	})
+
+	for _, d := range dirs {
+		if empty, err := isEmptyDir(d); err != nil || !empty {
+			continue
+		}
+		if err := op.Pending(d, "", journal.ResolutionRmdir); err != nil {
+			return
+		}
+		if err := os.Remove(d); err != nil {
+			op.Failed(d, "", journal.ResolutionRmdir, err)
+			continue
+		}
+		op.Done(d, "", journal.ResolutionRmdir)
+	}
+}
+
+// removeAll 事务回滚时按创建的相反顺序删除文件夹，文件夹中仍有文件时保留
+func (f *folders) removeAll(op *journal.Operation) {
+	for i := len(f.created) - 1; i >= 0; i-- {
+		d := f.created[i]
+		if err := removeEmptyDir(d); err != nil {
+			if applog.Logger != nil {
+				applog.Logger.Printf("[TRANSACTION] remove folder %s: %v", d, err)
+			}
+			continue
+		}
+		op.Undone("", d)
+	}
+}
+
+// removeEmptyDir 删除空文件夹；不为空时返回 ErrFolderNotEmpty
+func removeEmptyDir(dir string) error {
+	empty, err := isEmptyDir(dir)
+	if err != nil {
+		return err
+	}
+	if !empty {
+		return ErrFolderNotEmpty
+	}
+	return os.Remove(dir)
+}
+
+// isEmptyDir 判断文件夹是否为空
+func isEmptyDir(dir string) (bool, error) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return false, err
+	}
+	defer d.Close()
+	_, err = d.Readdirnames(1)
+	if errors.Is(err, io.EOF) {
+		return true, nil
+	}
+	return false, err
+}
@@ -0,0 +1,43 @@
+package executor
+
+import (
+	"os"
+
+	"rename-tool/common/filestatus"
+	"rename-tool/common/planner"
+	"rename-tool/setting/model"
+)
+
+// place 复制或链接模式下在目标写入源文件的副本或链接，源文件不变
+// 覆盖时先写到目标旁的临时名，再按覆盖流程替换目标（事务模式下可以恢复被覆盖的文件）
+func (t *transaction) place(s step) error {
+	if s.resolution != planner.ResolutionOverwrite {
+		return writeOutput(t.mode, s.from, s.to)
+	}
+	temp := tempPath(s.to)
+	if err := writeOutput(t.mode, s.from, temp); err != nil {
+		return err
+	}
+	if err := t.replace(temp, s.to); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	return nil
+}
+
+// writeOutput 按执行方式在 to 创建 from 的副本、符号链接或硬链接；to 已存在时失败
+// 符号链接指向源文件的绝对路径，输出文件夹移动后仍然有效
+func writeOutput(mode model.ExecMode, from, to string) error {
+	switch mode {
+	case model.ExecSymlink:
+		return os.Symlink(from, to)
+	case model.ExecHardlink:
+		return os.Link(from, to)
+	}
+	return filestatus.CopyFile(from, to)
+}
+
+// keepsSources 执行方式是否保留源文件（目标是副本或链接）
+func keepsSources(mode model.ExecMode) bool {
+	return mode != model.ExecRename
+}
@@ -0,0 +1,160 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"rename-tool/common/planner"
+)
+
+// step 一次磁盘重命名；环中的计划项会拆成“源 → 临时名”和“临时名 → 目标”两步
+type step struct {
+	entry      planner.Entry
+	from       string
+	to         string
+	final      bool               // 计划项的最后一步，完成后产生结果
+	resolution planner.Resolution // 冲突处理结果，只作用于最后一步
+}
+
+// job 必须按顺序执行的步骤（依赖链或环），不同 job 之间互不影响，可以并行
+type job []step
+
+// schedule 分析计划中目标与源的依赖关系，生成安全的执行顺序
+//   - 依赖链 a→b、b→c：先执行 b→c，再执行 a→b
+//   - 环 a↔b 或 a→b→c→a：先把 a 移到临时名，依次执行其余各项，最后把临时名改为 a 的目标
+//
+// 目标路径不会被改变：每个文件最终落在计划中的目标上
+func schedule(entries []planner.Entry) []job {
+	var jobs []job
+
+	// 只有成功生成且名称有变化的计划项参与依赖分析，跳过的计划项保留原名
+	var nodes []planner.Entry
+	bySource := make(map[string]int)
+	for _, entry := range entries {
+		if entry.Err != nil || entry.Source == entry.Target || entry.Resolution == planner.ResolutionSkip {
+			jobs = append(jobs, job{finalStep(entry)})
+			continue
+		}
+		bySource[pathKey(entry.Source)] = len(nodes)
+		nodes = append(nodes, entry)
+	}
+
+	// next[i]：计划项 i 的目标正被计划项 next[i] 的源占用，需等待其先移走
+	// 删除重复文件不占用目标，没有后继；覆盖的目标由冲突处理确认不是其他计划项的源
+	next := make([]int, len(nodes))
+	hasPrev := make([]bool, len(nodes))
+	for i, entry := range nodes {
+		next[i] = -1
+		if entry.Resolution == planner.ResolutionDedupe {
+			continue
+		}
+		if j, ok := bySource[pathKey(entry.Target)]; ok && j != i {
+			next[i] = j
+			hasPrev[j] = true
+		}
+	}
+
+	visited := make([]bool, len(nodes))
+
+	// 依赖链：从没有前驱的节点出发，逆序执行
+	for start := range nodes {
+		if hasPrev[start] || visited[start] {
+			continue
+		}
+		var chain []int
+		for i := start; i >= 0 && !visited[i]; i = next[i] {
+			visited[i] = true
+			chain = append(chain, i)
+		}
+		var j job
+		for k := len(chain) - 1; k >= 0; k-- {
+			j = append(j, finalStep(nodes[chain[k]]))
+		}
+		jobs = append(jobs, j)
+	}
+
+	// 剩余未访问的节点都在环中
+	for start := range nodes {
+		if visited[start] {
+			continue
+		}
+		var cycle []int
+		for i := start; !visited[i]; i = next[i] {
+			visited[i] = true
+			cycle = append(cycle, i)
+		}
+
+		first := nodes[cycle[0]]
+		temp := tempPath(first.Source)
+		j := job{{entry: first, from: first.Source, to: temp}}
+		for k := len(cycle) - 1; k >= 1; k-- {
+			j = append(j, finalStep(nodes[cycle[k]]))
+		}
+		last := finalStep(first)
+		last.from = temp
+		j = append(j, last)
+		jobs = append(jobs, j)
+	}
+
+	return jobs
+}
+
+// byDepth 按 job 的深度从深到浅分组，同一组内保持原有顺序
+// 目标可以位于其他文件夹，依赖链或环中的各步可能跨越不同深度，job 的深度取所有步骤源路径中最深的一个，
+// 保证其中任何一步都先于所在文件夹（或其上级）的重命名执行
+func byDepth(jobs []job) [][]job {
+	groups := make(map[int][]job)
+	var depths []int
+	for _, j := range jobs {
+		d := jobDepth(j)
+		if _, ok := groups[d]; !ok {
+			depths = append(depths, d)
+		}
+		groups[d] = append(groups[d], j)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(depths)))
+
+	levels := make([][]job, len(depths))
+	for i, d := range depths {
+		levels[i] = groups[d]
+	}
+	return levels
+}
+
+// jobDepth job 中所有步骤源路径的最大层级数
+func jobDepth(j job) int {
+	d := 0
+	for _, s := range j {
+		d = max(d, depth(s.from))
+	}
+	return d
+}
+
+// depth 路径的层级数
+func depth(path string) int {
+	return strings.Count(filepath.Clean(path), string(filepath.Separator))
+}
+
+// finalStep 计划项从源直接到目标的一步
+func finalStep(entry planner.Entry) step {
+	return step{entry: entry, from: entry.Source, to: entry.Target, final: true, resolution: entry.Resolution}
+}
+
+// pathKey 路径比较键（Windows 文件系统不区分大小写）
+func pathKey(path string) string {
+	return strings.ToLower(filepath.Clean(path))
+}
+
+// tempPath 生成与源文件同目录、当前不存在的临时名
+func tempPath(source string) string {
+	dir, base := filepath.Split(source)
+	for i := 0; ; i++ {
+		path := filepath.Join(dir, fmt.Sprintf(".%s.renaming-%d-%d", base, os.Getpid(), i))
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			return path
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package executor
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rename-tool/common/planner"
+)
+
+// useTempJournal 把日志写到临时目录，测试不影响用户的撤销记录
+func useTempJournal(t *testing.T) {
+	t.Helper()
+	t.Setenv("APPDATA", t.TempDir())
+}
+
+// writeTree 按相对路径创建文件，内容即文件名，便于检查每个文件最终的位置
+func writeTree(t *testing.T, dir string, files ...string) {
+	t.Helper()
+	for _, rel := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(rel), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readTree 返回目录中全部文件的相对路径与内容
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// runPlan 执行计划并返回全部结果
+func runPlan(t *testing.T, plan *planner.Plan) []Result {
+	t.Helper()
+	var results []Result
+	for result := range Run(context.Background(), plan, nil) {
+		results = append(results, result)
+	}
+	return results
+}
+
+// newPlan 由相对路径对生成计划，folders 中的源为文件夹
+func newPlan(dir string, pairs [][2]string, folders ...string) *planner.Plan {
+	isFolder := make(map[string]bool)
+	for _, f := range folders {
+		isFolder[f] = true
+	}
+	plan := &planner.Plan{}
+	plan.Config.SelectedDir = dir
+	for _, p := range pairs {
+		plan.Entries = append(plan.Entries, planner.Entry{
+			Source: filepath.Join(dir, filepath.FromSlash(p[0])),
+			Target: filepath.Join(dir, filepath.FromSlash(p[1])),
+			Folder: isFolder[p[0]],
+		})
+	}
+	return plan
+}
+
+func TestRunSchedulesThroughTemporaryNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		pairs   [][2]string
+		folders []string
+		want    map[string]string // 相对路径 -> 原来的相对路径（文件内容）
+	}{
+		{"swap", []string{"a", "b"},
+			[][2]string{{"a", "b"}, {"b", "a"}}, nil,
+			map[string]string{"a": "b", "b": "a"}},
+		{"cycle", []string{"a", "b", "c"},
+			[][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}}, nil,
+			map[string]string{"a": "c", "b": "a", "c": "b"}},
+		{"chain freed later in the batch", []string{"1", "2", "3"},
+			[][2]string{{"1", "2"}, {"2", "3"}, {"3", "4"}}, nil,
+			map[string]string{"2": "1", "3": "2", "4": "3"}},
+		{"case only", []string{"photo.JPG", "other"},
+			[][2]string{{"photo.JPG", "photo.jpg"}}, nil,
+			map[string]string{"photo.jpg": "photo.JPG", "other": "other"}},
+		{"nested folders", []string{"x/y/f", "x/g"},
+			[][2]string{{"x", "X"}, {"x/y", "x/Y"}, {"x/y/f", "x/y/F"}, {"x/g", "x/G"}}, []string{"x", "x/y"},
+			map[string]string{"X/Y/F": "x/y/f", "X/G": "x/g"}},
+		{"swap folders with their contents", []string{"p/1", "q/2"},
+			[][2]string{{"p", "q"}, {"q", "p"}, {"p/1", "p/one"}}, []string{"p", "q"},
+			map[string]string{"q/one": "p/1", "p/2": "q/2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTempJournal(t)
+			dir := t.TempDir()
+			writeTree(t, dir, tt.files...)
+
+			for _, r := range runPlan(t, newPlan(dir, tt.pairs, tt.folders...)) {
+				if r.Err != nil {
+					t.Errorf("%s -> %s: %v", r.Source, r.Target, r.Err)
+				}
+			}
+			// 比较完整的文件集合：临时名不能留下
+			got := readTree(t, dir)
+			if len(got) != len(tt.want) {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+			for rel, content := range tt.want {
+				if got[rel] != content {
+					t.Errorf("%s holds %q, want %q (all files: %v)", rel, got[rel], content, got)
+				}
+			}
+		})
+	}
+}
+
+func TestByDepthOrdersDeepestFirst(t *testing.T) {
+	dir := t.TempDir()
+	entry := func(source, target string) planner.Entry {
+		return planner.Entry{Source: filepath.Join(dir, filepath.FromSlash(source)), Target: filepath.Join(dir, filepath.FromSlash(target))}
+	}
+	jobs := schedule([]planner.Entry{
+		entry("a", "A"),
+		entry("a/b/c", "a/b/C"),
+		entry("a/b", "a/B"),
+		// 依赖链跨越不同深度，第一步（top → top2）最浅：整个 job 按最深的一步排列
+		entry("a/b/x", "top"),
+		entry("top", "top2"),
+		entry("d/e", "D"),
+	})
+
+	levels := byDepth(jobs)
+	last := -1
+	for i := len(levels) - 1; i >= 0; i-- {
+		d := jobDepth(levels[i][0])
+		for _, j := range levels[i] {
+			if jobDepth(j) != d {
+				t.Errorf("level %d mixes depths %d and %d", i, d, jobDepth(j))
+			}
+		}
+		if d <= last {
+			t.Errorf("level %d has depth %d, not deeper than the next level (%d)", i, d, last)
+		}
+		last = d
+	}
+	for _, j := range levels[0] {
+		for _, s := range j {
+			if s.entry.Source == filepath.Join(dir, "top") {
+				return
+			}
+		}
+	}
+	t.Errorf("the chain from a/b/x is not in the deepest level")
+}
@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"sync"
+	"time"
+)
+
+// Progress 执行进度快照
+type Progress struct {
+	Done    int           // 已处理的计划项（成功、失败或取消）
+	Total   int           // 计划项总数
+	Current string        // 最近开始处理的文件
+	Copied  int64         // 当前文件移到其他磁盘时已复制的字节数
+	Size    int64         // 当前文件移到其他磁盘时的大小，未复制时为 0
+	Rate    float64       // 每秒处理的文件数
+	ETA     time.Duration // 预计剩余时间，尚无法估计时为 0
+}
+
+// Tracker 统计执行进度：工作协程开始处理文件时更新当前文件，产生结果时更新完成数
+// 界面定时读取快照；nil 时所有方法为空操作
+type Tracker struct {
+	mu      sync.Mutex
+	total   int
+	done    int
+	current string
+	copied  int64
+	size    int64
+	started time.Time
+}
+
+// NewTracker 创建进度统计，total 为计划项数量
+func NewTracker(total int) *Tracker {
+	return &Tracker{total: total, started: time.Now()}
+}
+
+// begin 记录正在处理的文件
+func (t *Tracker) begin(path string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.current = path
+	t.copied, t.size = 0, 0
+	t.mu.Unlock()
+}
+
+// copying 记录当前文件移到其他磁盘时的复制进度
+func (t *Tracker) copying(copied, size int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.copied, t.size = copied, size
+	t.mu.Unlock()
+}
+
+// advance 一个计划项处理完毕
+func (t *Tracker) advance() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.done++
+	t.copied, t.size = 0, 0
+	t.mu.Unlock()
+}
+
+// Snapshot 返回当前进度，速度和剩余时间按开始以来的平均速度计算
+func (t *Tracker) Snapshot() Progress {
+	if t == nil {
+		return Progress{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p := Progress{Done: t.done, Total: t.total, Current: t.current, Copied: t.copied, Size: t.size}
+	if elapsed := time.Since(t.started).Seconds(); elapsed > 0 && t.done > 0 {
+		p.Rate = float64(t.done) / elapsed
+		p.ETA = time.Duration(float64(t.total-t.done) / p.Rate * float64(time.Second))
+	}
+	return p
+}
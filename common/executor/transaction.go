@@ -0,0 +1,150 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"rename-tool/common/applog"
+	"rename-tool/common/filestatus"
+	"rename-tool/common/journal"
+	"rename-tool/common/planner"
+	"rename-tool/setting/model"
+)
+
+// ErrAborted 事务模式下其他文件失败，该文件未执行重命名
+var ErrAborted = errors.New("not renamed: batch aborted after a failure")
+
+// transaction 事务模式（全部成功或全部回滚）的执行状态
+// 未启用时只负责转发覆盖、复制与链接操作，其余方法均为空操作
+type transaction struct {
+	enabled bool
+	mode    model.ExecMode // 执行方式，决定如何写入和回滚目标
+
+	mu      sync.Mutex
+	failed  bool
+	done    []step            // 已在磁盘上完成的步骤，按完成顺序
+	backups map[string]string // 被覆盖的目标 -> 备份路径，提交时删除，回滚时恢复
+}
+
+func newTransaction(enabled bool, mode model.ExecMode) *transaction {
+	return &transaction{enabled: enabled, mode: mode, backups: make(map[string]string)}
+}
+
+// aborted 是否已有文件失败，之后的步骤不再执行
+func (t *transaction) aborted() bool {
+	if !t.enabled {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.failed
+}
+
+// rolledBack 事务模式下是否已失败并回滚
+func (t *transaction) rolledBack() bool {
+	return t.enabled && t.failed
+}
+
+// fail 标记事务失败
+func (t *transaction) fail() {
+	t.mu.Lock()
+	t.failed = true
+	t.mu.Unlock()
+}
+
+// record 记录已完成的步骤，供回滚使用
+func (t *transaction) record(s step) {
+	if !t.enabled {
+		return
+	}
+	t.mu.Lock()
+	t.done = append(t.done, s)
+	t.mu.Unlock()
+}
+
+// replace 覆盖目标；事务模式下先把目标移到备份名，回滚时可以恢复被覆盖的文件
+func (t *transaction) replace(from, to string) error {
+	if !t.enabled {
+		return filestatus.ReplaceFile(from, to)
+	}
+	backup := tempPath(to)
+	if err := filestatus.RenameFile(to, backup); err != nil {
+		return err
+	}
+	if err := filestatus.RenameFile(from, to); err != nil {
+		if restoreErr := filestatus.RenameFile(backup, to); restoreErr != nil {
+			return fmt.Errorf("%w (overwritten file is kept at %s)", err, backup)
+		}
+		return err
+	}
+	t.mu.Lock()
+	t.backups[to] = backup
+	t.mu.Unlock()
+	return nil
+}
+
+// finish 全部步骤结束后提交或回滚
+// 提交时删除覆盖产生的备份；回滚时按完成的相反顺序恢复，每个计划项产生一个回滚结果，最后删除本批次创建的文件夹
+func (t *transaction) finish(op *journal.Operation, dirs *folders, results chan<- Result) {
+	if !t.enabled {
+		return
+	}
+	if !t.failed {
+		for _, backup := range t.backups {
+			if err := os.Remove(backup); err != nil && applog.Logger != nil {
+				applog.Logger.Printf("[TRANSACTION] remove backup %s: %v", backup, err)
+			}
+		}
+		return
+	}
+
+	for i := len(t.done) - 1; i >= 0; i-- {
+		s := t.done[i]
+		err := t.revert(s)
+		if err == nil {
+			op.Undone(s.from, s.to)
+			removeRenameLog(s.from, s.to)
+		}
+
+		result := Result{Source: s.entry.Source, Target: s.entry.Target, Resolution: s.entry.Resolution}
+		switch {
+		case err != nil:
+			result.RollbackErr = fmt.Errorf("%w (file is kept at %s)", err, s.to)
+		case s.final:
+			result.RolledBack = true
+		default:
+			continue // 中间步骤恢复成功，计划项的结果已在最后一步产生
+		}
+		results <- result
+	}
+	dirs.removeAll(op)
+}
+
+// revert 撤销单个已完成的步骤
+func (t *transaction) revert(s step) error {
+	if keepsSources(t.mode) {
+		// 删除写入的副本或链接，覆盖时恢复原有的文件
+		if err := os.Remove(s.to); err != nil {
+			return err
+		}
+		if backup, ok := t.backups[s.to]; ok {
+			return filestatus.RenameFile(backup, s.to)
+		}
+		return nil
+	}
+	switch s.resolution {
+	case planner.ResolutionDedupe:
+		return filestatus.CopyFile(s.to, s.from)
+	case planner.ResolutionOverwrite:
+		if err := filestatus.RenameFile(s.to, s.from); err != nil {
+			return err
+		}
+		if backup, ok := t.backups[s.to]; ok {
+			return filestatus.RenameFile(backup, s.to)
+		}
+		return nil
+	}
+	return filestatus.RenameFile(s.to, s.from)
+}
@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rename-tool/common/planner"
+	"rename-tool/setting/model"
+)
+
+func TestTransactionRollsBackAfterFailure(t *testing.T) {
+	// 源文件逐层变浅，各层依次执行：第 0、1 项先完成，第 2 项的目标在执行时已被占用而失败，第 3 项不再执行
+	sources := []string{"a/b/c/f0", "a/b/f1", "a/f2", "f3"}
+	const failing = 2
+
+	for _, mode := range []model.ExecMode{model.ExecRename, model.ExecCopy, model.ExecSymlink, model.ExecHardlink} {
+		t.Run(string(mode)+"mode", func(t *testing.T) {
+			useTempJournal(t)
+			dir, out := t.TempDir(), t.TempDir()
+			writeTree(t, dir, append(sources, "a/b/kept", "a/g2")...)
+			writeTree(t, out, "a/f2")
+			before, outBefore := readTree(t, dir), readTree(t, out)
+
+			plan := &planner.Plan{Config: model.RenameConfig{Transactional: true, Mode: mode, SelectedDir: dir, OutputDir: out}}
+			for i, rel := range sources {
+				source := filepath.Join(dir, filepath.FromSlash(rel))
+				target := filepath.Join(filepath.Dir(source), "g"+rel[len(rel)-1:])
+				if mode != model.ExecRename {
+					target = filepath.Join(out, filepath.FromSlash(rel))
+				}
+				entry := planner.Entry{Source: source, Target: target}
+				if i == 1 && mode == model.ExecRename {
+					// 覆盖的文件先移到备份名，回滚时恢复
+					entry.Target, entry.Resolution = filepath.Join(dir, "a", "b", "kept"), planner.ResolutionOverwrite
+				}
+				plan.Entries = append(plan.Entries, entry)
+			}
+
+			rolledBack := make(map[string]bool)
+			for _, r := range runPlan(t, plan) {
+				rel, _ := filepath.Rel(dir, r.Source)
+				rel = filepath.ToSlash(rel)
+				switch {
+				case r.RollbackErr != nil:
+					t.Errorf("%s: rollback failed: %v", rel, r.RollbackErr)
+				case r.RolledBack:
+					rolledBack[rel] = true
+				case rel == sources[failing] && r.Err == nil:
+					t.Errorf("%s: expected a failure", rel)
+				case rel == sources[3] && !errors.Is(r.Err, ErrAborted):
+					t.Errorf("%s: err = %v, want ErrAborted", rel, r.Err)
+				}
+			}
+			for _, rel := range sources[:failing] {
+				if !rolledBack[rel] {
+					t.Errorf("%s was not rolled back", rel)
+				}
+			}
+
+			// 所选目录恢复原样（包括被覆盖的文件），输出文件夹中没有留下副本、链接或新建的文件夹
+			sameTree(t, dir, before)
+			sameTree(t, out, outBefore)
+			if _, err := os.Stat(filepath.Join(out, "a", "b")); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("created output folder left behind: %v", err)
+			}
+		})
+	}
+}
+
+// sameTree 检查目录中的文件与 want 完全一致
+func sameTree(t *testing.T, dir string, want map[string]string) {
+	t.Helper()
+	got := readTree(t, dir)
+	if len(got) != len(want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+	for rel, content := range want {
+		if got[rel] != content {
+			t.Errorf("%s holds %q, want %q", rel, got[rel], content)
+		}
+	}
+}
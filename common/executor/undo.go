@@ -0,0 +1,248 @@
+package executor
+
+import (
+	"errors"
+	"os"
+
+	"rename-tool/common/antisamename"
+	"rename-tool/common/filestatus"
+	"rename-tool/common/journal"
+	"rename-tool/setting/model"
+)
+
+// 无法撤销或重做的原因
+var (
+	ErrNotRenamed     = errors.New("file was not renamed by this operation")
+	ErrNotUndone      = errors.New("file has not been undone")
+	ErrRenamedMissing = errors.New("renamed file no longer exists")
+	ErrOriginalTaken  = errors.New("original name is used by another file")
+	ErrOriginalGone   = errors.New("original file no longer exists")
+	ErrNewNameTaken   = errors.New("new name is used by another file")
+	ErrNotDuplicate   = errors.New("file is no longer identical to the kept copy")
+)
+
+// CheckUndo 判断记录当前能否撤销，不能时返回原因
+// 删除的重复文件通过复制保留的副本恢复；创建的文件夹只在为空时删除，删除的空文件夹重新创建
+// 复制或链接模式的副本和链接直接删除，源文件不受影响
+func CheckUndo(entry *journal.EntryLog) error {
+	if entry.State != journal.KindDone {
+		return ErrNotRenamed
+	}
+	if isOutput(entry) {
+		if _, err := os.Lstat(entry.Target); err != nil {
+			return ErrRenamedMissing
+		}
+		return nil
+	}
+	if entry.Resolution == journal.ResolutionRmdir {
+		if _, err := os.Lstat(entry.Source); err == nil {
+			return ErrOriginalTaken
+		}
+		return nil
+	}
+	if entry.Resolution == journal.ResolutionMkdir {
+		empty, err := isEmptyDir(entry.Target)
+		if err != nil {
+			return ErrRenamedMissing
+		}
+		if !empty {
+			return ErrFolderNotEmpty
+		}
+		return nil
+	}
+	if entry.Resolution == journal.ResolutionDedupe {
+		if _, err := os.Stat(entry.Target); err != nil {
+			return ErrRenamedMissing
+		}
+		if _, err := os.Lstat(entry.Source); err == nil {
+			return ErrOriginalTaken
+		}
+		return nil
+	}
+	return checkMove(entry.Target, entry.Source, ErrRenamedMissing, ErrOriginalTaken)
+}
+
+// CheckRedo 判断已撤销的记录当前能否重做，不能时返回原因
+func CheckRedo(entry *journal.EntryLog) error {
+	if entry.State != journal.KindUndone {
+		return ErrNotUndone
+	}
+	if isOutput(entry) {
+		if _, err := os.Stat(entry.Source); err != nil {
+			return ErrOriginalGone
+		}
+		if _, err := os.Lstat(entry.Target); err == nil && entry.Resolution != journal.ResolutionOverwrite {
+			return ErrNewNameTaken
+		}
+		return nil
+	}
+	if entry.Resolution == journal.ResolutionMkdir {
+		if _, err := os.Lstat(entry.Target); err == nil {
+			return ErrNewNameTaken
+		}
+		return nil
+	}
+	if entry.Resolution == journal.ResolutionRmdir {
+		empty, err := isEmptyDir(entry.Source)
+		if err != nil {
+			return ErrOriginalGone
+		}
+		if !empty {
+			return ErrFolderNotEmpty
+		}
+		return nil
+	}
+	if entry.Resolution == journal.ResolutionDedupe {
+		same, err := antisamename.SameContent(entry.Source, entry.Target)
+		if err != nil {
+			return ErrOriginalGone
+		}
+		if !same {
+			return ErrNotDuplicate
+		}
+		return nil
+	}
+	if entry.Resolution == journal.ResolutionOverwrite {
+		if _, err := os.Lstat(entry.Source); err != nil {
+			return ErrOriginalGone
+		}
+		return nil
+	}
+	return checkMove(entry.Source, entry.Target, ErrOriginalGone, ErrNewNameTaken)
+}
+
+// Undo 将选定记录改回原名，按与执行相反的顺序处理，并写入 undone 记录
+// 无法撤销的记录作为失败结果返回，日志中保持原状态
+func Undo(op *journal.OperationLog, entries []*journal.EntryLog) ([]Result, error) {
+	ordered := operationOrder(op, entries)
+	for i, j := 0, len(ordered)-1; i < j; i, j = i+1, j-1 {
+		ordered[i], ordered[j] = ordered[j], ordered[i]
+	}
+
+	return replay(op, ordered, func(w *journal.Operation, entry *journal.EntryLog) error {
+		if err := CheckUndo(entry); err != nil {
+			return err
+		}
+		var err error
+		switch {
+		case isOutput(entry):
+			err = os.Remove(entry.Target)
+		case entry.Resolution == journal.ResolutionDedupe:
+			err = filestatus.CopyFile(entry.Target, entry.Source)
+		case entry.Resolution == journal.ResolutionMkdir:
+			err = os.Remove(entry.Target)
+		case entry.Resolution == journal.ResolutionRmdir:
+			err = os.Mkdir(entry.Source, 0755)
+		default:
+			err = filestatus.RenameFile(entry.Target, entry.Source)
+		}
+		if err != nil {
+			return err
+		}
+		entry.State = journal.KindUndone
+		removeRenameLog(entry.Source, entry.Target)
+		return w.Undone(entry.Source, entry.Target)
+	})
+}
+
+// Redo 重新执行选定的已撤销记录，按原执行顺序处理，并写入 done 记录
+func Redo(op *journal.OperationLog, entries []*journal.EntryLog) ([]Result, error) {
+	return replay(op, operationOrder(op, entries), func(w *journal.Operation, entry *journal.EntryLog) error {
+		if err := CheckRedo(entry); err != nil {
+			return err
+		}
+		if err := w.Pending(entry.Source, entry.Target, entry.Resolution); err != nil {
+			return err
+		}
+		var err error
+		switch {
+		case isOutput(entry):
+			err = rewrite(entry)
+		case entry.Resolution == journal.ResolutionDedupe:
+			err = os.Remove(entry.Source)
+		case entry.Resolution == journal.ResolutionOverwrite:
+			err = filestatus.ReplaceFile(entry.Source, entry.Target)
+		case entry.Resolution == journal.ResolutionMkdir:
+			err = os.Mkdir(entry.Target, 0755)
+		case entry.Resolution == journal.ResolutionRmdir:
+			err = os.Remove(entry.Source)
+		default:
+			err = filestatus.RenameFile(entry.Source, entry.Target)
+		}
+		if err != nil {
+			w.Undone(entry.Source, entry.Target) // 恢复为已撤销状态
+			return err
+		}
+		entry.State = journal.KindDone
+		if entry.Resolution != journal.ResolutionMkdir && entry.Resolution != journal.ResolutionRmdir {
+			appendRenameLog(entry.Source, entry.Target, entry.Resolution)
+		}
+		return w.Done(entry.Source, entry.Target, entry.Resolution)
+	})
+}
+
+// replay 打开操作日志并逐条执行 fn
+func replay(op *journal.OperationLog, entries []*journal.EntryLog, fn func(*journal.Operation, *journal.EntryLog) error) ([]Result, error) {
+	w, err := journal.Resume(op.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer w.Close()
+
+	results := make([]Result, 0, len(entries))
+	for _, entry := range entries {
+		results = append(results, Result{Source: entry.Source, Target: entry.Target, Err: fn(w, entry)})
+	}
+	return results, nil
+}
+
+// operationOrder 按记录在操作中的原始顺序排列选中的记录
+func operationOrder(op *journal.OperationLog, entries []*journal.EntryLog) []*journal.EntryLog {
+	selected := make(map[*journal.EntryLog]bool, len(entries))
+	for _, entry := range entries {
+		selected[entry] = true
+	}
+	ordered := make([]*journal.EntryLog, 0, len(entries))
+	for _, entry := range op.Entries {
+		if selected[entry] {
+			ordered = append(ordered, entry)
+		}
+	}
+	return ordered
+}
+
+// checkMove 检查 from 是否存在、to 是否被其他文件占用
+func checkMove(from, to string, missing, taken error) error {
+	fromInfo, err := os.Lstat(from)
+	if err != nil {
+		return missing
+	}
+	if toInfo, err := os.Lstat(to); err == nil && !os.SameFile(fromInfo, toInfo) {
+		return taken
+	}
+	return nil
+}
+
+// isOutput 记录是否为复制或链接模式写入的副本或链接（不含创建或删除文件夹的记录）
+func isOutput(entry *journal.EntryLog) bool {
+	return entry.Mode != "" &&
+		entry.Resolution != journal.ResolutionMkdir &&
+		entry.Resolution != journal.ResolutionRmdir
+}
+
+// rewrite 重做复制或链接：重新写入副本或链接，覆盖时经临时名替换目标
+func rewrite(entry *journal.EntryLog) error {
+	mode := model.ExecMode(entry.Mode)
+	if entry.Resolution != journal.ResolutionOverwrite {
+		return writeOutput(mode, entry.Source, entry.Target)
+	}
+	temp := tempPath(entry.Target)
+	if err := writeOutput(mode, entry.Source, temp); err != nil {
+		return err
+	}
+	if err := filestatus.ReplaceFile(temp, entry.Target); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	return nil
+}
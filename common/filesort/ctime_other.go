@@ -0,0 +1,13 @@
+//go:build !windows
+
+package filesort
+
+import (
+	"os"
+	"time"
+)
+
+// creationTime 非 Windows 平台的文件系统接口不提供创建时间，使用修改时间
+func creationTime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package filesort
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// creationTime 返回文件创建时间
+func creationTime(info os.FileInfo) time.Time {
+	if data, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return time.Unix(0, data.CreationTime.Nanoseconds())
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,211 @@
+package filesort
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/text/collate"
+
+	"rename-tool/common/pathgen"
+	"rename-tool/setting/model"
+)
+
+// Keys 可选的自动排序依据，顺序即界面下拉框顺序（手动顺序另行处理）
+var Keys = []model.SortKey{
+	model.SortName,
+	model.SortNatural,
+	model.SortModified,
+	model.SortCreated,
+	model.SortSize,
+	model.SortExif,
+}
+
+// ParseKey 解析排序依据，空字符串表示默认的文件名排序
+func ParseKey(name string) (model.SortKey, error) {
+	if name == "" {
+		return model.SortName, nil
+	}
+	for _, key := range Keys {
+		if string(key) == name {
+			return key, nil
+		}
+	}
+	return "", fmt.Errorf("unknown sort key %q", name)
+}
+
+// Manual 按手动顺序排列：order 中列出的文件（相对 dir 的路径）按给定顺序在前，
+// 其余文件（例如保存顺序之后新增的文件）按自然顺序排在后面
+func Manual(files []string, dir string, order []string) []string {
+	position := make(map[string]int, len(order))
+	for i, rel := range order {
+		position[filepath.ToSlash(rel)] = i
+	}
+
+	listed := make([]string, len(order))
+	var rest []string
+	for _, file := range files {
+		if i, ok := position[RelPath(dir, file)]; ok && listed[i] == "" {
+			listed[i] = file
+		} else {
+			rest = append(rest, file)
+		}
+	}
+
+	sorted := make([]string, 0, len(files))
+	for _, file := range listed {
+		if file != "" {
+			sorted = append(sorted, file)
+		}
+	}
+	return append(sorted, Sort(rest, model.SortNatural, false)...)
+}
+
+// RelPath 返回 file 相对 dir 的路径（/ 分隔），用于保存手动顺序；无法计算时返回原路径
+func RelPath(dir, file string) string {
+	rel, err := filepath.Rel(dir, file)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// item 文件及其排序值，每个文件只读取一次
+type item struct {
+	path string
+	dir  [][]byte  // 所在文件夹各级名称的排序键，递归列出时先按文件夹分组
+	name []byte    // 文件名的排序键（按界面语言的排序规则生成）
+	time time.Time // 修改/创建/拍摄时间
+	size int64
+}
+
+// Sort 按指定依据对文件排序，返回新的切片
+// 子文件夹中的文件按文件夹分组（所在文件夹的文件在前，子文件夹按自然顺序），组内再按排序依据排列，
+// 不同文件夹的文件不会交错；排序值相同时依次按文件名的自然顺序和完整路径排序，
+// 保证结果与目录读取顺序无关、每次运行一致；计划按此顺序依次生成目标名，序号因此与工作协程数量无关
+func Sort(files []string, key model.SortKey, descending bool) []string {
+	col := newCollator(key == model.SortNatural)
+	folders := newCollator(true)
+	dirs := make(map[string][][]byte)
+	var buf collate.Buffer
+	items := make([]item, len(files))
+	for i, path := range files {
+		items[i] = newItem(path, key)
+		items[i].name = col.KeyFromString(&buf, filepath.Base(path))
+
+		dir := filepath.Dir(path)
+		if _, ok := dirs[dir]; !ok {
+			dirs[dir] = dirKey(folders, &buf, dir)
+		}
+		items[i].dir = dirs[dir]
+	}
+
+	less := compare(key)
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if c := compareDirs(a.dir, b.dir); c != 0 {
+			return c < 0
+		}
+		// 只有大小写不同的文件夹排序键相同，仍要分开
+		if da, db := filepath.Dir(a.path), filepath.Dir(b.path); da != db {
+			return da < db
+		}
+		if descending {
+			a, b = b, a
+		}
+		if c := less(a, b); c != 0 {
+			return c < 0
+		}
+		if c := naturalCompare(filepath.Base(a.path), filepath.Base(b.path)); c != 0 {
+			return c < 0
+		}
+		return a.path < b.path
+	})
+
+	sorted := make([]string, len(items))
+	for i, it := range items {
+		sorted[i] = it.path
+	}
+	return sorted
+}
+
+// dirKey 文件夹路径各级名称的排序键
+func dirKey(col *collate.Collator, buf *collate.Buffer, dir string) [][]byte {
+	var key [][]byte
+	for _, name := range strings.FieldsFunc(filepath.ToSlash(dir), func(r rune) bool { return r == '/' }) {
+		key = append(key, col.KeyFromString(buf, name))
+	}
+	return key
+}
+
+// compareDirs 逐级比较文件夹，上级文件夹在其子文件夹之前
+func compareDirs(a, b [][]byte) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := bytes.Compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
+
+// newItem 读取排序所需的属性；读取失败时使用零值，文件排在最前（升序）
+func newItem(path string, key model.SortKey) item {
+	it := item{path: path}
+	switch key {
+	case model.SortModified, model.SortCreated, model.SortSize, model.SortExif:
+	default:
+		return it
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return it
+	}
+	it.size = info.Size()
+	switch key {
+	case model.SortModified:
+		it.time = info.ModTime()
+	case model.SortCreated:
+		it.time = creationTime(info)
+	case model.SortExif:
+		it.time = info.ModTime()
+		if meta, err := pathgen.ReadImageMetadata(path); err == nil && !meta.DateTaken.IsZero() {
+			it.time = meta.DateTaken
+		}
+	}
+	return it
+}
+
+// compare 返回排序依据对应的比较函数（负数表示 a 在前）
+func compare(key model.SortKey) func(a, b item) int {
+	switch key {
+	case model.SortModified, model.SortCreated, model.SortExif:
+		return func(a, b item) int { return a.time.Compare(b.time) }
+	case model.SortSize:
+		return func(a, b item) int {
+			switch {
+			case a.size < b.size:
+				return -1
+			case a.size > b.size:
+				return 1
+			}
+			return 0
+		}
+	}
+	return func(a, b item) int { return bytes.Compare(a.name, b.name) }
+}
+
+// Strings 按界面语言的自然排序规则排列字符串（用于格式列表等），原地排序
+func Strings(list []string) {
+	col := newCollator(true)
+	sort.SliceStable(list, func(i, j int) bool {
+		if c := col.CompareString(list[i], list[j]); c != 0 {
+			return c < 0
+		}
+		return naturalCompare(list[i], list[j]) < 0
+	})
+}
@@ -0,0 +1,94 @@
+package filesort
+
+import (
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+
+	"rename-tool/setting/i18n"
+)
+
+// newCollator 按当前界面语言创建排序规则：中文按拼音、日文按五十音，不区分大小写
+// numeric 为 true 时连续数字按数值比较（file2 在 file10 之前）
+func newCollator(numeric bool) *collate.Collator {
+	options := []collate.Option{collate.IgnoreCase}
+	if numeric {
+		options = append(options, collate.Numeric)
+	}
+	return collate.New(language.Make(i18n.GetManager().CurrentLang()), options...)
+}
+
+// naturalCompare 自然排序比较：连续数字按数值比较，其余部分按字符比较
+// 数值相同时前导零少的在前（file2 < file02），保证结果确定
+// 排序规则认为相同的名称（只有大小写或前导零不同）用它区分先后
+func naturalCompare(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			na, nextI := digitRun(a, i)
+			nb, nextJ := digitRun(b, j)
+			if c := compareNumbers(na, nb); c != 0 {
+				return c
+			}
+			i, j = nextI, nextJ
+			continue
+		}
+		if a[i] != b[j] {
+			if a[i] < b[j] {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+	switch {
+	case len(a)-i < len(b)-j:
+		return -1
+	case len(a)-i > len(b)-j:
+		return 1
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+// digitRun 返回从 start 开始的连续数字及其后的位置
+func digitRun(s string, start int) (string, int) {
+	end := start
+	for end < len(s) && isDigit(s[end]) {
+		end++
+	}
+	return s[start:end], end
+}
+
+// compareNumbers 比较两个十进制数字串的数值，不受长度限制
+func compareNumbers(a, b string) int {
+	ta, tb := trimZeros(a), trimZeros(b)
+	switch {
+	case len(ta) != len(tb):
+		if len(ta) < len(tb) {
+			return -1
+		}
+		return 1
+	case ta < tb:
+		return -1
+	case ta > tb:
+		return 1
+	}
+	return 0
+}
+
+func trimZeros(s string) string {
+	for len(s) > 1 && s[0] == '0' {
+		s = s[1:]
+	}
+	return s
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,79 @@
+package filesort
+
+import (
+	"reflect"
+	"testing"
+
+	"rename-tool/setting/i18n"
+	"rename-tool/setting/model"
+)
+
+// useLanguage 切换界面语言，测试结束后恢复
+func useLanguage(t *testing.T, lang string) {
+	t.Helper()
+	manager := i18n.GetManager()
+	previous := manager.CurrentLang()
+	manager.SetLanguage(lang)
+	t.Cleanup(func() { manager.SetLanguage(previous) })
+}
+
+func TestStringsCollation(t *testing.T) {
+	tests := []struct {
+		lang string
+		in   []string
+		want []string
+	}{
+		{"en", []string{"file10", "File2", "file1"}, []string{"file1", "File2", "file10"}},
+		// 中文按拼音：北京 bei、上海 shang、天津 tian
+		{"zh", []string{"天津", "上海", "北京"}, []string{"北京", "上海", "天津"}},
+		// 日文按五十音，平假名与片假名混排
+		{"ja", []string{"さくら", "カメラ", "あめ"}, []string{"あめ", "カメラ", "さくら"}},
+		// 排序规则认为相同的名称由自然顺序区分：大写在前，前导零少的在前
+		{"en", []string{"a.txt", "A.txt", "file02", "file2"}, []string{"A.txt", "a.txt", "file2", "file02"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			useLanguage(t, tt.lang)
+			got := append([]string{}, tt.in...)
+			Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Strings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortTieBreak(t *testing.T) {
+	useLanguage(t, "en")
+	// 按名称排序时忽略大小写，只有大小写或前导零不同的文件按自然顺序排列，与输入顺序无关
+	want := []string{"IMG2.jpg", "img2.jpg", "img02.jpg", "img10.jpg"}
+	for _, in := range [][]string{
+		{"img10.jpg", "img02.jpg", "img2.jpg", "IMG2.jpg"},
+		{"img2.jpg", "IMG2.jpg", "img10.jpg", "img02.jpg"},
+	} {
+		if got := Sort(in, model.SortNatural, false); !reflect.DeepEqual(got, want) {
+			t.Errorf("Sort(%v) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestNaturalCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"file2", "file10", -1},
+		{"file10", "file2", 1},
+		{"file2", "file02", -1},
+		{"file02", "file2", 1},
+		{"a", "a", 0},
+		{"A", "a", -1},
+		{"x99999999999999999999", "x100000000000000000000", -1}, // 超出 int64 的数字
+		{"v1.2", "v1.10", -1},
+	}
+	for _, tt := range tests {
+		if got := naturalCompare(tt.a, tt.b); got != tt.want {
+			t.Errorf("naturalCompare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package filesort
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"rename-tool/setting/model"
+)
+
+// writeFiles 按相对路径创建文件，修改时间依次递增一分钟
+func writeFiles(t *testing.T, dir string, files ...string) []string {
+	t.Helper()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	paths := make([]string, len(files))
+	for i, rel := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(rel), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		modified := start.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+		paths[i] = path
+	}
+	return paths
+}
+
+// relPaths 把结果转换为相对路径，便于比较
+func relPaths(dir string, files []string) []string {
+	list := make([]string, len(files))
+	for i, file := range files {
+		list[i] = RelPath(dir, file)
+	}
+	return list
+}
+
+func TestSortGroupsFolders(t *testing.T) {
+	dir := t.TempDir()
+	// 修改时间按列出的顺序递增，按时间排序时不同文件夹的文件会交错
+	files := writeFiles(t, dir, "a/1.jpg", "b/1.jpg", "a/2.jpg", "top.jpg", "a/c/3.jpg", "dir10/x.jpg", "dir2/x.jpg")
+
+	tests := []struct {
+		name       string
+		key        model.SortKey
+		descending bool
+		want       []string
+	}{
+		{"modified", model.SortModified, false,
+			[]string{"top.jpg", "a/1.jpg", "a/2.jpg", "a/c/3.jpg", "b/1.jpg", "dir2/x.jpg", "dir10/x.jpg"}},
+		// 降序只作用于文件夹内的文件，文件夹仍按自然顺序
+		{"modified descending", model.SortModified, true,
+			[]string{"top.jpg", "a/2.jpg", "a/1.jpg", "a/c/3.jpg", "b/1.jpg", "dir2/x.jpg", "dir10/x.jpg"}},
+		{"natural", model.SortNatural, false,
+			[]string{"top.jpg", "a/1.jpg", "a/2.jpg", "a/c/3.jpg", "b/1.jpg", "dir2/x.jpg", "dir10/x.jpg"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 输入顺序打乱，结果与目录读取顺序无关
+			input := append([]string{}, files...)
+			for i, j := 0, len(input)-1; i < j; i, j = i+1, j-1 {
+				input[i], input[j] = input[j], input[i]
+			}
+			if got := relPaths(dir, Sort(input, tt.key, tt.descending)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sort = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortKeepsCaseOnlyFoldersApart(t *testing.T) {
+	// 区分大小写的文件系统上 A 与 a 是两个文件夹，排序规则不区分大小写，仍不能交错
+	files := []string{"a/1", "A/2", "a/3", "A/4"}
+	for i := range files {
+		files[i] = filepath.FromSlash(files[i])
+	}
+	got := relPaths(".", Sort(files, model.SortName, false))
+	want := []string{"A/2", "A/4", "a/1", "a/3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Sort = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,193 @@
+package filestatus
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+)
+
+const errorNotSameDevice syscall.Errno = 17 // Windows ERROR_NOT_SAME_DEVICE
+
+// ProgressFunc reports how many bytes of the current file have been copied.
+type ProgressFunc func(copied, total int64)
+
+// errChecksumMismatch the copy differs from the data read from the source.
+var errChecksumMismatch = errors.New("copy does not match the source (checksum mismatch)")
+
+// errPartialExists a file already has the name the copy is written under.
+var errPartialExists = errors.New("a file already has the name of the temporary copy, it is not overwritten")
+
+// isCrossDeviceError checks whether a rename failed because the target is on another drive.
+func isCrossDeviceError(err error) bool {
+	if errors.Is(err, syscall.EXDEV) {
+		return true
+	}
+	return runtime.GOOS == "windows" && errors.Is(err, errorNotSameDevice)
+}
+
+// PartialCopyPath returns where a copy to target is written until it is complete
+// and verified. The copy only ever creates a new file there, never reuses an
+// existing one, so a file left there by an interrupted copy can simply be removed.
+func PartialCopyPath(target string) string {
+	dir, base := filepath.Split(target)
+	return filepath.Join(dir, "."+base+".copying")
+}
+
+// moveAcrossDevices moves a file to another drive: it copies the file next to
+// newPath, verifies size and checksum, renames the copy into place and only
+// then deletes the source. If the source cannot be deleted the copy is removed
+// again, so exactly one of the two names holds the file.
+func moveAcrossDevices(oldPath, newPath string, progress ProgressFunc) error {
+	info, err := os.Lstat(oldPath)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s: only files can be moved to another drive", oldPath)
+	}
+
+	partial, err := copyVerified(oldPath, newPath, progress)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(partial, newPath); err != nil {
+		os.Remove(partial)
+		return err
+	}
+	if err := os.Chtimes(newPath, info.ModTime(), info.ModTime()); err != nil {
+		os.Remove(newPath)
+		return err
+	}
+	if err := removeWithRetry(oldPath); err != nil {
+		os.Remove(newPath)
+		return err
+	}
+	return nil
+}
+
+// copyVerified copies oldPath to PartialCopyPath(newPath), keeping permissions,
+// and checks the written file against the size and SHA-256 checksum of the data
+// read from the source. The modification time is set by the caller only after
+// the copy has its final name: until then the partial file is as new as the
+// batch writing it, which is how recovery tells it from a file of the same name.
+func copyVerified(oldPath, newPath string, progress ProgressFunc) (string, error) {
+	info, err := os.Stat(oldPath)
+	if err != nil {
+		return "", err
+	}
+	in, err := os.Open(oldPath)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	// An existing file under the partial name belongs to someone else (or to a
+	// copy that is still running); it is neither overwritten nor removed.
+	partial := PartialCopyPath(newPath)
+	out, err := os.OpenFile(partial, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return "", fmt.Errorf("%s: %w", partial, errPartialExists)
+	}
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.New()
+	_, err = io.Copy(&progressWriter{w: out, total: info.Size(), progress: progress}, io.TeeReader(in, sum))
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = verifyCopy(partial, info.Size(), sum.Sum(nil))
+	}
+	if err == nil {
+		err = os.Chmod(partial, info.Mode().Perm())
+	}
+	if err != nil {
+		os.Remove(partial)
+		return "", err
+	}
+	return partial, nil
+}
+
+// verifyCopy re-reads the copy and compares its size and checksum.
+func verifyCopy(path string, size int64, sum []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() != size {
+		return fmt.Errorf("%s: copied %d of %d bytes", path, info.Size(), size)
+	}
+	copied, err := checksum(path)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(copied, sum) {
+		return fmt.Errorf("%s: %w", path, errChecksumMismatch)
+	}
+	return nil
+}
+
+// FinishMove completes a move to another drive that was interrupted after the
+// copy was in place but before the source was deleted: if newPath holds an
+// identical copy of oldPath, oldPath is deleted. It reports whether the move
+// is now complete.
+func FinishMove(oldPath, newPath string) bool {
+	oldInfo, err := os.Lstat(oldPath)
+	if err != nil || !oldInfo.Mode().IsRegular() {
+		return false
+	}
+	newInfo, err := os.Lstat(newPath)
+	if err != nil || !newInfo.Mode().IsRegular() || os.SameFile(oldInfo, newInfo) || oldInfo.Size() != newInfo.Size() {
+		return false
+	}
+	oldSum, err := checksum(oldPath)
+	if err != nil {
+		return false
+	}
+	newSum, err := checksum(newPath)
+	if err != nil || !bytes.Equal(oldSum, newSum) {
+		return false
+	}
+	return os.Remove(oldPath) == nil
+}
+
+// checksum returns the SHA-256 checksum of a file.
+func checksum(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sum := sha256.New()
+	if _, err := io.Copy(sum, f); err != nil {
+		return nil, err
+	}
+	return sum.Sum(nil), nil
+}
+
+// progressWriter reports the bytes written so far after each write.
+type progressWriter struct {
+	w        io.Writer
+	copied   int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.copied += int64(n)
+	if p.progress != nil {
+		p.progress(p.copied, p.total)
+	}
+	return n, err
+}
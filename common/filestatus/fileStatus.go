@@ -4,8 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"rename-tool/common/antisamename"
 	"rename-tool/setting/config"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -27,12 +27,49 @@ func IsFileBusyError(err error) bool {
 	return isKnownFileBusyMessage(err.Error())
 }
 
+// RenameFile renames oldPath to exactly newPath, retrying while the file is busy.
+// It never picks another name: an existing target (other than a case-only change
+// of the same file) is an error, so the journal always records the real target.
+// A target on another drive is handled by copying, verifying and deleting.
 func RenameFile(oldPath, newPath string) error {
+	return RenameFileWithProgress(oldPath, newPath, nil)
+}
+
+// RenameFileWithProgress is RenameFile reporting the copy progress when the
+// file has to be copied to another drive.
+func RenameFileWithProgress(oldPath, newPath string, progress ProgressFunc) error {
 	if oldPath == newPath {
 		return nil
 	}
-	newPath = antisamename.GenerateUniquePath(newPath)
+	if newInfo, err := os.Lstat(newPath); err == nil && !caseOnlyChange(oldPath, newPath, newInfo) {
+		return fmt.Errorf("%s: %s → %s: %w", "rename_failed_format", oldPath, newPath, os.ErrExist)
+	}
+	return renameWithRetry(oldPath, newPath, progress)
+}
+
+// caseOnlyChange reports whether newPath differs from oldPath only in case and
+// names the same file, as it does on a case-insensitive file system. On a
+// case-sensitive one A.txt and a.txt are different files and must not be replaced.
+func caseOnlyChange(oldPath, newPath string, newInfo os.FileInfo) bool {
+	if !strings.EqualFold(oldPath, newPath) {
+		return false
+	}
+	oldInfo, err := os.Lstat(oldPath)
+	return err == nil && os.SameFile(oldInfo, newInfo)
+}
 
+// ReplaceFile renames oldPath to newPath, replacing an existing file at newPath.
+// Used only when the user chose to overwrite a conflicting file.
+func ReplaceFile(oldPath, newPath string) error {
+	if oldPath == newPath {
+		return nil
+	}
+	return renameWithRetry(oldPath, newPath, nil)
+}
+
+// renameWithRetry retries os.Rename with backoff while the file is busy,
+// and falls back to copy and delete when the target is on another drive.
+func renameWithRetry(oldPath, newPath string, progress ProgressFunc) error {
 	var err error
 	delay := config.RetryDelay
 	for i := 0; i < config.MaxRetryAttempts; i++ {
@@ -40,6 +77,9 @@ func RenameFile(oldPath, newPath string) error {
 		if err == nil {
 			return nil
 		}
+		if isCrossDeviceError(err) {
+			return moveAcrossDevices(oldPath, newPath, progress)
+		}
 		if !IsFileBusyError(err) {
 			break
 		}
@@ -47,6 +87,48 @@ func RenameFile(oldPath, newPath string) error {
 		delay *= 2
 	}
 	////================================
-	return fmt.Errorf("%s: %s → %s", "rename_failed_format", oldPath, newPath)
+	return fmt.Errorf("%s: %s → %s: %w", "rename_failed_format", oldPath, newPath, err)
+
+}
 
+// removeWithRetry retries os.Remove with backoff while the file is busy.
+func removeWithRetry(path string) error {
+	var err error
+	delay := config.RetryDelay
+	for i := 0; i < config.MaxRetryAttempts; i++ {
+		err = os.Remove(path)
+		if err == nil || !IsFileBusyError(err) {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+	return err
+}
+
+// CopyFile copies oldPath to exactly newPath, keeping its permissions and
+// modification time. The data is written and verified under PartialCopyPath
+// and renamed into place, so newPath never holds a partial copy. Like
+// RenameFile, an existing newPath is an error.
+func CopyFile(oldPath, newPath string) error {
+	if _, err := os.Lstat(newPath); err == nil {
+		return fmt.Errorf("%s: %s → %s: %w", "copy_failed_format", oldPath, newPath, os.ErrExist)
+	}
+	info, err := os.Stat(oldPath)
+	if err != nil {
+		return err
+	}
+	partial, err := copyVerified(oldPath, newPath, nil)
+	if err != nil {
+		return err
+	}
+	if err := RenameFile(partial, newPath); err != nil {
+		os.Remove(partial)
+		return err
+	}
+	if err := os.Chtimes(newPath, info.ModTime(), info.ModTime()); err != nil {
+		os.Remove(newPath)
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,42 @@
+package filestatus
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenameFileCaseOnly(t *testing.T) {
+	dir := t.TempDir()
+	lower, upper := filepath.Join(dir, "a.txt"), filepath.Join(dir, "A.txt")
+	if err := os.WriteFile(lower, []byte("lower"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(upper, []byte("upper"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Lstat(lower); info != nil {
+		if other, _ := os.Lstat(upper); os.SameFile(info, other) {
+			t.Skip("case-insensitive file system")
+		}
+	}
+
+	// 区分大小写时 A.txt 是另一个文件，不能被覆盖
+	if err := RenameFile(lower, upper); !errors.Is(err, os.ErrExist) {
+		t.Fatalf("RenameFile onto a different file = %v, want ErrExist", err)
+	}
+	if data, _ := os.ReadFile(upper); string(data) != "upper" {
+		t.Fatalf("A.txt = %q, was overwritten", data)
+	}
+
+	if err := os.Remove(upper); err != nil {
+		t.Fatal(err)
+	}
+	if err := RenameFile(lower, upper); err != nil {
+		t.Fatalf("RenameFile case only: %v", err)
+	}
+	if data, _ := os.ReadFile(upper); string(data) != "lower" {
+		t.Errorf("A.txt = %q, want %q", data, "lower")
+	}
+}
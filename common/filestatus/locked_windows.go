@@ -0,0 +1,25 @@
+//go:build windows
+
+package filestatus
+
+import "syscall"
+
+const accessDelete = 0x00010000 // Windows DELETE access right, required to rename or remove
+
+// IsLocked reports whether another process holds path open without sharing
+// delete access, so that renaming or removing it would fail. The check opens
+// the file the same way a rename does and closes it immediately.
+func IsLocked(path string) bool {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return false
+	}
+	h, err := syscall.CreateFile(name, accessDelete,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil, syscall.OPEN_EXISTING, syscall.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return IsFileBusyError(err)
+	}
+	syscall.CloseHandle(h)
+	return false
+}
@@ -0,0 +1,67 @@
+package jobfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"rename-tool/common/pathgen"
+	"rename-tool/setting/model"
+)
+
+// Version 当前任务文件格式版本
+const Version = 1
+
+// Extension 任务文件扩展名
+const Extension = ".renamejob"
+
+// Job 保存的重命名任务：完整的重命名配置（含手动顺序）和扫描选项，
+// 可在界面中重新加载执行，或通过命令行 renamer run 执行
+type Job struct {
+	Version   int                `json:"version"`
+	Recursive bool               `json:"recursive"`
+	Config    model.RenameConfig `json:"config"`
+}
+
+// New 创建当前版本的任务
+func New(config model.RenameConfig, recursive bool) Job {
+	return Job{Version: Version, Recursive: recursive, Config: config}
+}
+
+// Save 写入任务文件；先写临时文件再替换，避免写入中断留下损坏的任务
+func Save(path string, job Job) error {
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return err
+	}
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	// Windows 需要先删除目标文件
+	_ = os.Remove(path)
+	return os.Rename(temp, path)
+}
+
+// Load 读取并校验任务文件
+func Load(path string) (Job, error) {
+	var job Job
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return job, err
+	}
+	if err := json.Unmarshal(data, &job); err != nil {
+		return job, fmt.Errorf("invalid job file %s: %w", path, err)
+	}
+	if job.Version < 1 || job.Version > Version {
+		return job, fmt.Errorf("unsupported job file version %d", job.Version)
+	}
+	if job.Config.SelectedDir == "" {
+		return job, errors.New("job file has no directory")
+	}
+	if _, err := pathgen.GetPathGenerator(job.Config.Type); err != nil {
+		return job, err
+	}
+	return job, nil
+}
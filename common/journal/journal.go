@@ -0,0 +1,189 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+)
+
+// 记录类型
+const (
+	KindBegin   = "begin"   // 批量操作开始
+	KindPlanned = "planned" // 预写的计划项，执行前全部写入，用于中断后继续或回滚
+	KindPending = "pending" // 即将重命名（先于实际操作写入）
+	KindDone    = "done"    // 重命名成功
+	KindFailed  = "failed"  // 重命名失败
+	KindSkipped = "skipped" // 因冲突处理策略跳过
+	KindUndone  = "undone"  // 已撤销
+	KindEnd     = "end"     // 批量操作正常结束
+	KindAbort   = "abort"   // 批量操作中断，启动时检测并确认
+)
+
+// Record 日志中的一行
+type Record struct {
+	Op     string    `json:"op"`
+	Kind   string    `json:"kind"`
+	Time   time.Time `json:"time"`
+	Source string    `json:"src,omitempty"`
+	Target string    `json:"dst,omitempty"`
+	Error  string    `json:"err,omitempty"`
+	Res    string    `json:"res,omitempty"`    // 冲突处理结果（overwrite、suffix、dedupe、skip）
+	Type   string    `json:"type,omitempty"`   // begin：重命名类型
+	Mode   string    `json:"mode,omitempty"`   // begin：执行方式，为空表示原地重命名
+	Dir    string    `json:"dir,omitempty"`    // begin：所选目录
+	Total  int       `json:"total,omitempty"`  // begin：计划项数量
+	PID    int       `json:"pid,omitempty"`    // begin：执行进程，用于判断是否仍在运行
+	PStart int64     `json:"pstart,omitempty"` // begin：执行进程的启动时间（纳秒），重启后 PID 可能被其他进程复用
+}
+
+// Operation 正在写入的批量操作，方法可并发调用；nil 时所有方法为空操作
+type Operation struct {
+	ID string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Begin 开始一个新的批量操作并写入 begin 记录
+func Begin(renameType, mode, dir string, total int) (*Operation, error) {
+	op, err := open(newOperationID())
+	if err != nil {
+		return nil, err
+	}
+	pid := os.Getpid()
+	rec := Record{Kind: KindBegin, Type: renameType, Mode: mode, Dir: dir, Total: total, PID: pid, PStart: processStart(pid)}
+	if err := op.write(rec, true); err != nil {
+		op.file.Close()
+		return nil, err
+	}
+	return op, nil
+}
+
+// Resume 重新打开已有操作，用于追加撤销或中断确认记录
+func Resume(id string) (*Operation, error) {
+	return open(id)
+}
+
+// Planned 预写日志中的计划项
+type Planned struct {
+	Source     string
+	Target     string
+	Resolution string
+}
+
+// Plan 在执行前写入整个批次的计划并同步到磁盘，中断后据此找出尚未完成的文件
+func (o *Operation) Plan(entries []Planned) error {
+	for _, p := range entries {
+		if err := o.write(Record{Kind: KindPlanned, Source: p.Source, Target: p.Target, Res: p.Resolution}, false); err != nil {
+			return err
+		}
+	}
+	return o.sync()
+}
+
+// Pending 在重命名前写入并同步到磁盘，断电后也能据此找出可能已被修改的文件；
+// done 等结果记录不同步，丢失时由 RecoverInterrupted 对照磁盘补写
+func (o *Operation) Pending(source, target, resolution string) error {
+	return o.write(Record{Kind: KindPending, Source: source, Target: target, Res: resolution}, true)
+}
+
+// Done 记录重命名成功
+func (o *Operation) Done(source, target, resolution string) error {
+	return o.write(Record{Kind: KindDone, Source: source, Target: target, Res: resolution}, false)
+}
+
+// Failed 记录重命名失败
+func (o *Operation) Failed(source, target, resolution string, cause error) error {
+	return o.write(Record{Kind: KindFailed, Source: source, Target: target, Res: resolution, Error: errorText(cause)}, false)
+}
+
+// Skipped 记录因冲突处理策略未执行的重命名
+func (o *Operation) Skipped(source, target, resolution string) error {
+	return o.write(Record{Kind: KindSkipped, Source: source, Target: target, Res: resolution}, false)
+}
+
+// Undone 记录已撤销（target 已改回 source）
+func (o *Operation) Undone(source, target string) error {
+	return o.write(Record{Kind: KindUndone, Source: source, Target: target}, false)
+}
+
+// End 写入结束记录并关闭
+func (o *Operation) End() error {
+	return o.finish(KindEnd)
+}
+
+// Abort 将中断的操作标记为已确认并关闭
+func (o *Operation) Abort() error {
+	return o.finish(KindAbort)
+}
+
+// Close 只关闭文件，不写入结束记录
+func (o *Operation) Close() error {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.file.Close()
+}
+
+// finish 写入结束类记录并关闭
+func (o *Operation) finish(kind string) error {
+	if o == nil {
+		return nil
+	}
+	err := o.write(Record{Kind: kind}, true)
+	if cerr := o.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// write 追加一条记录；每条记录直接写入系统（不经用户态缓冲），程序崩溃也不会丢失
+// sync 为 true 时同步到磁盘，断电也不会丢失；用于开始、结束和 pending 记录，结果记录不逐条刷盘
+func (o *Operation) write(rec Record, sync bool) error {
+	if o == nil {
+		return nil
+	}
+	rec.Op = o.ID
+	rec.Time = time.Now()
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, err := o.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write journal: %w", err)
+	}
+	if sync {
+		return o.file.Sync()
+	}
+	return nil
+}
+
+// sync 将已写入的记录同步到磁盘
+func (o *Operation) sync() error {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.file.Sync()
+}
+
+// newOperationID 生成按时间排序的操作 ID
+func newOperationID() string {
+	return fmt.Sprintf("%s-%04x", time.Now().Format("20060102-150405.000"), rand.Intn(0x10000))
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,429 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"rename-tool/common/applog"
+	"rename-tool/common/filestatus"
+)
+
+// errInterrupted 中断时尚未确认完成的重命名
+var errInterrupted = errors.New("interrupted before the rename completed")
+
+// 日志中记录的冲突处理结果（与 planner.Resolution 一致）
+const (
+	ResolutionOverwrite = "overwrite" // 覆盖了目标位置原有的文件
+	ResolutionDedupe    = "dedupe"    // 源文件是重复文件，已被删除而不是移动
+	ResolutionMkdir     = "mkdir"     // 执行时创建的文件夹（Target），Source 为空，撤销时删除
+	ResolutionRmdir     = "rmdir"     // 执行后删除的空文件夹（Source），Target 为空，撤销时重新创建
+)
+
+// 日志中记录的执行方式（与 model.ExecMode 一致），为空表示原地重命名
+// 其余方式下源文件不变，撤销时删除 Target
+const (
+	ModeCopy     = "copy"     // Target 是源文件的副本
+	ModeSymlink  = "symlink"  // Target 是指向源文件的符号链接
+	ModeHardlink = "hardlink" // Target 是源文件的硬链接
+)
+
+// 操作状态
+const (
+	StateRunning     = "running"
+	StateFinished    = "finished"
+	StateInterrupted = "interrupted"
+)
+
+// OperationLog 从日志重建的批量操作
+type OperationLog struct {
+	ID      string
+	Type    string
+	Mode    string // 执行方式
+	Dir     string
+	Total   int
+	PID     int
+	PStart  int64 // 执行进程的启动时间，旧版本日志中为 0
+	Started time.Time
+	State   string
+	Entries []*EntryLog // 按首次写入顺序
+	Planned []Planned   // 预写的完整计划，旧版本日志中为空
+}
+
+// EntryLog 单个文件的最新状态（KindPending/KindDone/KindFailed/KindSkipped/KindUndone）
+type EntryLog struct {
+	Source     string
+	Target     string
+	State      string
+	Resolution string // 冲突处理结果，为空表示普通重命名
+	Mode       string // 所属操作的执行方式，不为空时 Target 是副本或链接
+	Error      string
+	Time       time.Time
+}
+
+// Undoable 返回可撤销的记录，最近的在前
+func (op *OperationLog) Undoable() []*EntryLog {
+	var entries []*EntryLog
+	for i := len(op.Entries) - 1; i >= 0; i-- {
+		if op.Entries[i].State == KindDone {
+			entries = append(entries, op.Entries[i])
+		}
+	}
+	return entries
+}
+
+// Count 统计处于指定状态的记录数
+func (op *OperationLog) Count(state string) int {
+	n := 0
+	for _, entry := range op.Entries {
+		if entry.State == state {
+			n++
+		}
+	}
+	return n
+}
+
+// Remaining 返回计划中尚未完成的计划项，Source 为文件当前所在位置
+// 经临时名执行的计划项中断在中间步骤时，文件位于临时名，从临时名继续
+// 没有结果记录（断电时未写入磁盘）但源已不存在、目标已存在的计划项视为已完成
+func (op *OperationLog) Remaining() []Planned {
+	done := make(map[[2]string]bool)
+	moved := make(map[string]string) // 源 -> 已完成的中间步骤目标（临时名）
+	for _, entry := range op.Entries {
+		if entry.State == KindDone || entry.State == KindSkipped {
+			done[[2]string{entry.Source, entry.Target}] = true
+			moved[entry.Source] = entry.Target
+		}
+	}
+
+	var remaining []Planned
+	for _, p := range op.Planned {
+		if done[[2]string{p.Source, p.Target}] {
+			continue
+		}
+		if _, err := os.Lstat(p.Source); err != nil {
+			if temp, ok := moved[p.Source]; ok && done[[2]string{temp, p.Target}] {
+				continue
+			} else if ok {
+				p.Source = temp
+			} else if op.plannedOnDisk(p) {
+				continue
+			}
+		}
+		remaining = append(remaining, p)
+	}
+	return remaining
+}
+
+// Load 读取全部日志并按操作分组，按开始时间排序；日志不存在时返回空
+// 末尾不完整的行（写入时崩溃）会被忽略
+func Load() ([]*OperationLog, error) {
+	file, err := os.Open(applog.GetJournalPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	ops := make(map[string]*OperationLog)
+	entries := make(map[string]map[[2]string]*EntryLog)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec Record
+		if json.Unmarshal(scanner.Bytes(), &rec) != nil || rec.Op == "" {
+			continue
+		}
+		op := ops[rec.Op]
+		if op == nil {
+			op = &OperationLog{ID: rec.Op, Started: rec.Time, State: StateRunning}
+			ops[rec.Op] = op
+			entries[rec.Op] = make(map[[2]string]*EntryLog)
+		}
+
+		switch rec.Kind {
+		case KindBegin:
+			op.Type, op.Mode, op.Dir, op.Total, op.PID, op.PStart, op.Started = rec.Type, rec.Mode, rec.Dir, rec.Total, rec.PID, rec.PStart, rec.Time
+		case KindPlanned:
+			op.Planned = append(op.Planned, Planned{Source: rec.Source, Target: rec.Target, Resolution: rec.Res})
+		case KindEnd:
+			op.State = StateFinished
+		case KindAbort:
+			op.State = StateInterrupted
+		case KindPending, KindDone, KindFailed, KindSkipped, KindUndone:
+			key := [2]string{rec.Source, rec.Target}
+			entry := entries[rec.Op][key]
+			if entry == nil {
+				entry = &EntryLog{Source: rec.Source, Target: rec.Target, Mode: op.Mode}
+				entries[rec.Op][key] = entry
+				op.Entries = append(op.Entries, entry)
+			}
+			entry.State, entry.Error, entry.Time = rec.Kind, rec.Error, rec.Time
+			if rec.Res != "" {
+				entry.Resolution = rec.Res
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	list := make([]*OperationLog, 0, len(ops))
+	for _, op := range ops {
+		list = append(list, op)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if !list[i].Started.Equal(list[j].Started) {
+			return list[i].Started.Before(list[j].Started)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+// LastUndoable 返回最近一个仍有可撤销记录的操作，没有时返回 nil
+// 目标文件已全部被删除或移走的操作会被跳过，避免挡住更早的操作
+func LastUndoable() (*OperationLog, error) {
+	ops, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(ops) - 1; i >= 0; i-- {
+		if ops[i].State == StateRunning {
+			continue
+		}
+		for _, entry := range ops[i].Undoable() {
+			if _, err := os.Lstat(entry.Target); err == nil {
+				return ops[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Interrupted 返回已确认中断、且有预写计划可以继续或回滚的操作，最近的在前
+func Interrupted() ([]*OperationLog, error) {
+	ops, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	var list []*OperationLog
+	for i := len(ops) - 1; i >= 0; i-- {
+		if ops[i].State == StateInterrupted && len(ops[i].Planned) > 0 {
+			list = append(list, ops[i])
+		}
+	}
+	return list, nil
+}
+
+// Find 按 ID 查找操作
+func Find(id string) (*OperationLog, error) {
+	ops, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range ops {
+		if op.ID == id {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("operation %s not found in the journal", id)
+}
+
+// RecoverInterrupted 检测上次未正常结束的批量操作（执行进程已不存在）
+// 根据磁盘状态补写 pending 记录的结果，使已完成的文件可以撤销，然后将操作标记为中断
+// 移到其他磁盘时已写完副本的文件完成移动，未写完的副本被删除
+func RecoverInterrupted() ([]*OperationLog, error) {
+	ops, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var recovered []*OperationLog
+	for _, op := range ops {
+		if op.State != StateRunning || processAlive(op.PID, op.PStart) {
+			continue
+		}
+		w, err := Resume(op.ID)
+		if err != nil {
+			return recovered, err
+		}
+		for _, entry := range op.Entries {
+			if entry.State != KindPending {
+				continue
+			}
+			if completedOnDisk(entry) || finishMove(entry) {
+				entry.State = KindDone
+				err = w.Done(entry.Source, entry.Target, entry.Resolution)
+			} else {
+				removePartialCopy(entry, op.Started)
+				entry.State, entry.Error = KindFailed, errInterrupted.Error()
+				err = w.Failed(entry.Source, entry.Target, entry.Resolution, errInterrupted)
+			}
+			if err != nil {
+				w.Close()
+				return recovered, err
+			}
+		}
+		// 结果记录不逐条刷盘，断电后已完成的文件可能没有任何记录：对照磁盘补写，使回滚能够恢复它们
+		if err := op.recoverUnrecorded(w); err != nil {
+			w.Close()
+			return recovered, err
+		}
+		if err := w.Abort(); err != nil {
+			return recovered, err
+		}
+		op.State = StateInterrupted
+		recovered = append(recovered, op)
+	}
+	return recovered, nil
+}
+
+// recoverUnrecorded 为没有任何记录、但磁盘上已完成的计划项补写 done 记录
+func (op *OperationLog) recoverUnrecorded(w *Operation) error {
+	recorded := make(map[string]bool, len(op.Entries))
+	for _, entry := range op.Entries {
+		recorded[entry.Source] = true
+	}
+	for _, p := range op.Planned {
+		if recorded[p.Source] || !op.plannedOnDisk(p) {
+			continue
+		}
+		if err := w.Done(p.Source, p.Target, p.Resolution); err != nil {
+			return err
+		}
+		op.Entries = append(op.Entries, &EntryLog{Source: p.Source, Target: p.Target, State: KindDone, Resolution: p.Resolution, Mode: op.Mode, Time: time.Now()})
+	}
+	return nil
+}
+
+// plannedOnDisk 判断没有结果记录的计划项是否已生效（原地重命名时源已不存在且目标已存在）
+func (op *OperationLog) plannedOnDisk(p Planned) bool {
+	return completedOnDisk(&EntryLog{Source: p.Source, Target: p.Target, Resolution: p.Resolution, Mode: op.Mode})
+}
+
+// completedOnDisk 判断中断前记录是否已生效；删除重复文件或空文件夹时只需源已不存在，创建文件夹时只需文件夹存在
+// 副本或链接只需检查目标是否已指向源文件
+func completedOnDisk(entry *EntryLog) bool {
+	if entry.Resolution == ResolutionMkdir {
+		info, err := os.Lstat(entry.Target)
+		return err == nil && info.IsDir()
+	}
+	if entry.Resolution == ResolutionDedupe || entry.Resolution == ResolutionRmdir {
+		_, err := os.Lstat(entry.Source)
+		return errors.Is(err, os.ErrNotExist)
+	}
+	switch entry.Mode {
+	case ModeCopy:
+		return copiedOnDisk(entry.Source, entry.Target)
+	case ModeSymlink:
+		link, err := os.Readlink(entry.Target)
+		return err == nil && link == entry.Source
+	case ModeHardlink:
+		return sameFile(entry.Source, entry.Target)
+	}
+	return renamedOnDisk(entry.Source, entry.Target)
+}
+
+// finishMove 移到其他磁盘时在删除源文件前中断：目标已是校验一致的完整副本时删除源文件，完成这次移动
+func finishMove(entry *EntryLog) bool {
+	if entry.Mode != "" || entry.Source == "" || entry.Target == "" || entry.Resolution == ResolutionDedupe {
+		return false
+	}
+	return filestatus.FinishMove(entry.Source, entry.Target)
+}
+
+// removePartialCopy 删除中断时未写完的副本（移到其他磁盘或复制模式）
+// 副本只以新文件创建，改为目标名之后才设置修改时间，写入期间修改时间不早于批次开始；
+// 更早的同名文件不是本次写入的，保留不动（留出文件系统时间精度的余量）
+func removePartialCopy(entry *EntryLog, started time.Time) {
+	if entry.Target == "" {
+		return
+	}
+	partial := filestatus.PartialCopyPath(entry.Target)
+	info, err := os.Lstat(partial)
+	if err != nil || !info.Mode().IsRegular() || info.ModTime().Before(started.Add(-2*time.Second)) {
+		return
+	}
+	if err := os.Remove(partial); err != nil && !errors.Is(err, os.ErrNotExist) && applog.Logger != nil {
+		applog.Logger.Printf("[JOURNAL] remove partial copy of %s: %v", entry.Target, err)
+	}
+}
+
+// copiedOnDisk 判断副本是否已经写入：副本先写入临时名再改为目标，并保留修改时间
+// 覆盖时目标原本就存在，需与源比较大小和修改时间
+func copiedOnDisk(source, target string) bool {
+	targetInfo, err := os.Lstat(target)
+	if err != nil {
+		return false
+	}
+	sourceInfo, err := os.Stat(source)
+	if err != nil {
+		return false
+	}
+	return targetInfo.Size() == sourceInfo.Size() && targetInfo.ModTime().Equal(sourceInfo.ModTime())
+}
+
+// sameFile 判断两个路径是否为同一个文件（硬链接）
+func sameFile(a, b string) bool {
+	aInfo, err := os.Lstat(a)
+	if err != nil {
+		return false
+	}
+	bInfo, err := os.Lstat(b)
+	return err == nil && os.SameFile(aInfo, bInfo)
+}
+
+// renamedOnDisk 判断重命名是否已经生效：目标存在且源文件已不存在（仅大小写变化时源路径仍可访问）
+func renamedOnDisk(source, target string) bool {
+	targetInfo, err := os.Lstat(target)
+	if err != nil {
+		return false
+	}
+	sourceInfo, err := os.Lstat(source)
+	if err != nil {
+		return true
+	}
+	if !os.SameFile(sourceInfo, targetInfo) {
+		return false
+	}
+	// 不区分大小写的文件系统上只改大小写：通过目录项名称判断
+	return nameOnDisk(target)
+}
+
+// nameOnDisk 判断目录中是否存在与路径大小写完全一致的条目
+func nameOnDisk(path string) bool {
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return false
+	}
+	defer dir.Close()
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return false
+	}
+	base := filepath.Base(path)
+	for _, name := range names {
+		if name == base {
+			return true
+		}
+	}
+	return false
+}
+
+// open 以追加模式打开日志
+func open(id string) (*Operation, error) {
+	file, err := os.OpenFile(applog.GetJournalPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Operation{ID: id, file: file}, nil
+}
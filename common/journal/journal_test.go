@@ -0,0 +1,195 @@
+package journal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"rename-tool/common/applog"
+)
+
+// writeJournal 把记录直接写成日志文件，tail 追加在末尾（模拟写入时崩溃留下的半行）
+func writeJournal(t *testing.T, tail string, records ...Record) {
+	t.Helper()
+	t.Setenv("APPDATA", t.TempDir())
+	var data []byte
+	for i, rec := range records {
+		rec.Time = time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC)
+		line, err := json.Marshal(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+	if err := os.WriteFile(applog.GetJournalPath(), append(data, tail...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// touch 创建文件
+func touch(t *testing.T, paths ...string) {
+	t.Helper()
+	for _, path := range paths {
+		if err := os.WriteFile(path, []byte(filepath.Base(path)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// loadOne 读取日志并返回唯一的操作
+func loadOne(t *testing.T) *OperationLog {
+	t.Helper()
+	ops, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(ops) != 1 {
+		t.Fatalf("Load returned %d operations, want 1", len(ops))
+	}
+	return ops[0]
+}
+
+// sources 返回记录的源路径
+func sources(entries []*EntryLog) []string {
+	var list []string
+	for _, entry := range entries {
+		list = append(list, entry.Source)
+	}
+	return list
+}
+
+func TestLoadStates(t *testing.T) {
+	tests := []struct {
+		name  string
+		kinds []string // begin 之后依次写入的记录
+		want  string
+	}{
+		{"finished", []string{KindEnd}, StateFinished},
+		{"no end record", nil, StateRunning},
+		{"abort", []string{KindAbort}, StateInterrupted},
+		// 中断的操作继续执行或回滚后追加 end
+		{"abort followed by end", []string{KindAbort, KindEnd}, StateFinished},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := []Record{{Op: "a", Kind: KindBegin, Total: 1}, {Op: "a", Kind: KindPlanned, Source: "x", Target: "y"}}
+			for _, kind := range tt.kinds {
+				records = append(records, Record{Op: "a", Kind: kind})
+			}
+			writeJournal(t, "", records...)
+
+			op := loadOne(t)
+			if op.State != tt.want {
+				t.Errorf("State = %s, want %s", op.State, tt.want)
+			}
+			interrupted, err := Interrupted()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(interrupted) == 1; got != (tt.want == StateInterrupted) {
+				t.Errorf("Interrupted returned %d operations", len(interrupted))
+			}
+		})
+	}
+}
+
+func TestLoadTornLastLine(t *testing.T) {
+	dir := t.TempDir()
+	a, b, c, d := filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "c"), filepath.Join(dir, "d")
+	touch(t, b, c)
+
+	// 截断在第二个文件的 done 记录中间：该记录被忽略，文件停留在 pending
+	torn, _ := json.Marshal(Record{Op: "op", Kind: KindDone, Source: c, Target: d})
+	writeJournal(t, string(torn[:len(torn)/2]),
+		Record{Op: "op", Kind: KindBegin, Total: 2},
+		Record{Op: "op", Kind: KindPlanned, Source: a, Target: b},
+		Record{Op: "op", Kind: KindPlanned, Source: c, Target: d},
+		Record{Op: "op", Kind: KindPending, Source: a, Target: b},
+		Record{Op: "op", Kind: KindDone, Source: a, Target: b},
+		Record{Op: "op", Kind: KindPending, Source: c, Target: d},
+	)
+
+	op := loadOne(t)
+	if op.State != StateRunning {
+		t.Errorf("State = %s, want %s", op.State, StateRunning)
+	}
+	if len(op.Entries) != 2 || op.Entries[1].State != KindPending {
+		t.Errorf("entries = %+v, want the second one pending", op.Entries)
+	}
+	if got := sources(op.Undoable()); !reflect.DeepEqual(got, []string{a}) {
+		t.Errorf("Undoable = %v, want [%s]", got, a)
+	}
+	if got := op.Remaining(); !reflect.DeepEqual(got, []Planned{{Source: c, Target: d}}) {
+		t.Errorf("Remaining = %v", got)
+	}
+}
+
+func TestRemainingChecksTheDisk(t *testing.T) {
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name) }
+	// a -> b 没有结果记录但已生效；c -> d 已完成到临时名；e -> f 尚未执行
+	touch(t, path("b"), path("tmp"), path("e"))
+
+	writeJournal(t, "",
+		Record{Op: "op", Kind: KindBegin, Total: 3},
+		Record{Op: "op", Kind: KindPlanned, Source: path("a"), Target: path("b")},
+		Record{Op: "op", Kind: KindPlanned, Source: path("c"), Target: path("d")},
+		Record{Op: "op", Kind: KindPlanned, Source: path("e"), Target: path("f")},
+		Record{Op: "op", Kind: KindDone, Source: path("c"), Target: path("tmp")},
+		Record{Op: "op", Kind: KindAbort},
+	)
+
+	want := []Planned{{Source: path("tmp"), Target: path("d")}, {Source: path("e"), Target: path("f")}}
+	if got := loadOne(t).Remaining(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Remaining = %v, want %v", got, want)
+	}
+}
+
+func TestRecoverInterrupted(t *testing.T) {
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name) }
+	// a -> b 已生效、c -> d 未生效（都只有 pending）；e -> f 没有记录但已生效；g -> h 尚未开始
+	touch(t, path("b"), path("c"), path("f"), path("g"))
+
+	writeJournal(t, "",
+		Record{Op: "op", Kind: KindBegin, Total: 4}, // PID 为 0：执行进程已不存在
+		Record{Op: "op", Kind: KindPlanned, Source: path("a"), Target: path("b")},
+		Record{Op: "op", Kind: KindPlanned, Source: path("c"), Target: path("d")},
+		Record{Op: "op", Kind: KindPlanned, Source: path("e"), Target: path("f")},
+		Record{Op: "op", Kind: KindPlanned, Source: path("g"), Target: path("h")},
+		Record{Op: "op", Kind: KindPending, Source: path("a"), Target: path("b")},
+		Record{Op: "op", Kind: KindPending, Source: path("c"), Target: path("d")},
+	)
+
+	recovered, err := RecoverInterrupted()
+	if err != nil {
+		t.Fatalf("RecoverInterrupted: %v", err)
+	}
+	if len(recovered) != 1 {
+		t.Fatalf("recovered %d operations, want 1", len(recovered))
+	}
+
+	// 补写的记录已追加到日志，重新读取后结果一致
+	op := loadOne(t)
+	if op.State != StateInterrupted {
+		t.Errorf("State = %s, want %s", op.State, StateInterrupted)
+	}
+	if got, want := sources(op.Undoable()), []string{path("e"), path("a")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Undoable = %v, want %v", got, want)
+	}
+	if got := op.Count(KindFailed); got != 1 {
+		t.Errorf("%d failed entries, want 1", got)
+	}
+	want := []Planned{{Source: path("c"), Target: path("d")}, {Source: path("g"), Target: path("h")}}
+	if got := op.Remaining(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Remaining = %v, want %v", got, want)
+	}
+
+	// 已标记中断的操作不会再次恢复
+	if again, err := RecoverInterrupted(); err != nil || len(again) != 0 {
+		t.Errorf("second RecoverInterrupted = %d operations, %v", len(again), err)
+	}
+}
@@ -0,0 +1,70 @@
+//go:build !windows
+
+package journal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// clockTicks /proc 中进程启动时间的单位（USER_HZ，Linux 上固定为 100）
+const clockTicks = 100
+
+// processAlive 判断记录中的执行进程是否仍在运行
+// started 不为 0 时还要求启动时间一致，重启后被复用的 PID 不算
+func processAlive(pid int, started int64) bool {
+	if pid <= 0 {
+		return false
+	}
+	if err := syscall.Kill(pid, 0); err != nil && err != syscall.EPERM {
+		return false
+	}
+	if now := processStart(pid); started != 0 && now != 0 {
+		return now == started
+	}
+	return true
+}
+
+// processStart 返回进程的启动时间（纳秒），从 /proc 读取，不支持时返回 0
+func processStart(pid int) int64 {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0
+	}
+	// 进程名可能含空格，从最后一个 ) 之后按空格拆分，第 22 项为启动时间（相对开机，单位 clockTicks）
+	i := bytes.LastIndexByte(stat, ')')
+	if i < 0 {
+		return 0
+	}
+	fields := bytes.Fields(stat[i+1:])
+	if len(fields) < 20 {
+		return 0
+	}
+	ticks, err := strconv.ParseInt(string(fields[19]), 10, 64)
+	if err != nil {
+		return 0
+	}
+	boot := bootTime()
+	if boot == 0 {
+		return 0
+	}
+	return boot*1e9 + ticks*(1e9/clockTicks)
+}
+
+// bootTime 返回开机时间（Unix 秒），读取失败时返回 0
+func bootTime() int64 {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return 0
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if value, ok := bytes.CutPrefix(line, []byte("btime ")); ok {
+			boot, _ := strconv.ParseInt(string(bytes.TrimSpace(value)), 10, 64)
+			return boot
+		}
+	}
+	return 0
+}
@@ -0,0 +1,48 @@
+//go:build windows
+
+package journal
+
+import "golang.org/x/sys/windows"
+
+// stillActive GetExitCodeProcess 对运行中进程返回的退出码
+const stillActive = 259
+
+// processAlive 判断记录中的执行进程是否仍在运行
+// started 不为 0 时还要求启动时间一致，重启后被复用的 PID 不算
+func processAlive(pid int, started int64) bool {
+	if pid <= 0 {
+		return false
+	}
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer windows.CloseHandle(handle)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(handle, &code); err != nil || code != stillActive {
+		return false
+	}
+	if now := startTime(handle); started != 0 && now != 0 {
+		return now == started
+	}
+	return true
+}
+
+// processStart 返回进程的启动时间（纳秒），无法获取时返回 0
+func processStart(pid int) int64 {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return 0
+	}
+	defer windows.CloseHandle(handle)
+	return startTime(handle)
+}
+
+func startTime(handle windows.Handle) int64 {
+	var creation, exit, kernel, user windows.Filetime
+	if err := windows.GetProcessTimes(handle, &creation, &exit, &kernel, &user); err != nil {
+		return 0
+	}
+	return creation.Nanoseconds()
+}
@@ -59,7 +59,11 @@ func buildBody() fyne.CanvasObject {
 		{buttonTr("insertLetter"), utils.ShowInsertCharRename},
 		{buttonTr("deleteLetter"), utils.ShowDeleteCharRename},
 		{buttonTr("regexReplace"), utils.ShowRegexReplace},
+		{buttonTr("pipelineRename"), utils.ShowPipelineRename},
+		{buttonTr("templateRename"), utils.ShowTemplateRename},
+		{buttonTr("flattenDirs"), utils.ShowFlatten},
 		{buttonTr("undoRename"), utils.UndoRename},
+		{buttonTr("undoHistory"), utils.ShowUndoHistory},
 		{buttonTr("logSaved"), utils.SaveLogs},
 		{buttonTr("exit"), func() { global.MyApp.Quit() }},
 	}
@@ -0,0 +1,550 @@
+package pathgen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// AudioMetadata 音频标签，缺失的字段为零值
+type AudioMetadata struct {
+	Artist string
+	Album  string
+	Title  string
+	Track  int
+	Disc   int
+	Year   int
+}
+
+// empty 判断是否没有读到任何标签
+func (m *AudioMetadata) empty() bool {
+	return m.Artist == "" && m.Album == "" && m.Title == "" && m.Track == 0 && m.Disc == 0 && m.Year == 0
+}
+
+var (
+	errUnsupportedAudio = errors.New("unsupported audio format")
+	errNoAudioTags      = errors.New("no audio tags found")
+)
+
+// ReadAudioMetadata 读取 MP3(ID3v1/v2)、FLAC、OGG(Vorbis/Opus) 与 M4A 文件中的标签
+// 只读取标签所在的块，跳过音频数据与封面图片
+func ReadAudioMetadata(path string) (*AudioMetadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+
+	head := make([]byte, 12)
+	if _, err := io.ReadFull(f, head); err != nil {
+		return nil, errUnsupportedAudio
+	}
+
+	meta := &AudioMetadata{}
+	switch {
+	case string(head[:3]) == "ID3":
+		err = readID3v2(f, size, meta)
+		// ID3v2 缺失的字段再尝试 ID3v1 补全
+		readID3v1(f, size, meta)
+	case string(head[:4]) == "fLaC":
+		err = readFLACTags(f, size, meta)
+	case string(head[:4]) == "OggS":
+		err = readOggTags(f, meta)
+	case string(head[4:8]) == "ftyp":
+		err = readMP4Tags(f, size, meta)
+	case head[0] == 0xFF && head[1]&0xE0 == 0xE0: // 无 ID3v2 的 MP3 帧
+		readID3v1(f, size, meta)
+	default:
+		return nil, errUnsupportedAudio
+	}
+	if err != nil {
+		return nil, err
+	}
+	if meta.empty() {
+		return nil, errNoAudioTags
+	}
+	return meta, nil
+}
+
+// ---------- ID3 ----------
+
+// syncsafe 解析 ID3v2 的 7 位编码整数
+func syncsafe(b []byte) int64 {
+	var v int64
+	for _, c := range b {
+		v = v<<7 | int64(c&0x7F)
+	}
+	return v
+}
+
+// readID3v2 逐帧读取 ID3v2.2/2.3/2.4 标签，只读取需要的文本帧
+func readID3v2(f *os.File, size int64, meta *AudioMetadata) error {
+	hdr := make([]byte, 10)
+	if _, err := f.ReadAt(hdr, 0); err != nil {
+		return err
+	}
+	version := hdr[3]
+	flags := hdr[5]
+	end := 10 + syncsafe(hdr[6:10])
+	if end > size {
+		end = size
+	}
+	if version < 2 || version > 4 {
+		return errors.New("unsupported id3v2 version")
+	}
+	unsync := flags&0x80 != 0
+
+	off := int64(10)
+	// 跳过扩展头
+	if flags&0x40 != 0 && version >= 3 {
+		ext := make([]byte, 4)
+		if _, err := f.ReadAt(ext, off); err != nil {
+			return err
+		}
+		if version == 4 {
+			off += syncsafe(ext)
+		} else {
+			off += int64(binary.BigEndian.Uint32(ext)) + 4
+		}
+	}
+
+	idLen, headerLen := int64(4), int64(10)
+	if version == 2 {
+		idLen, headerLen = 3, 6
+	}
+	frameHdr := make([]byte, headerLen)
+
+	for off+headerLen <= end {
+		if _, err := f.ReadAt(frameHdr, off); err != nil {
+			return nil
+		}
+		if frameHdr[0] == 0 { // 填充区
+			return nil
+		}
+		id := string(frameHdr[:idLen])
+
+		var frameSize int64
+		var frameFlags uint16
+		switch version {
+		case 2:
+			frameSize = int64(frameHdr[3])<<16 | int64(frameHdr[4])<<8 | int64(frameHdr[5])
+		case 3:
+			frameSize = int64(binary.BigEndian.Uint32(frameHdr[4:8]))
+			frameFlags = binary.BigEndian.Uint16(frameHdr[8:10])
+		case 4:
+			frameSize = syncsafe(frameHdr[4:8])
+			frameFlags = binary.BigEndian.Uint16(frameHdr[8:10])
+		}
+		body := off + headerLen
+		off = body + frameSize
+		if frameSize <= 0 || off > end {
+			return nil
+		}
+
+		field := id3Field(id)
+		if field == "" || frameSize > 1<<16 {
+			continue
+		}
+		data := make([]byte, frameSize)
+		if _, err := f.ReadAt(data, body); err != nil {
+			return nil
+		}
+
+		// 跳过压缩/加密帧，去掉分组标识与数据长度指示
+		switch version {
+		case 3:
+			if frameFlags&0x00C0 != 0 {
+				continue
+			}
+			if frameFlags&0x0020 != 0 && len(data) > 0 {
+				data = data[1:]
+			}
+		case 4:
+			if frameFlags&0x000C != 0 {
+				continue
+			}
+			if frameFlags&0x0040 != 0 && len(data) > 0 {
+				data = data[1:]
+			}
+			if frameFlags&0x0001 != 0 && len(data) >= 4 {
+				data = data[4:]
+			}
+			if frameFlags&0x0002 != 0 {
+				data = removeUnsync(data)
+			}
+		}
+		if unsync && version < 4 {
+			data = removeUnsync(data)
+		}
+
+		setAudioField(meta, field, decodeID3Text(data))
+	}
+	return nil
+}
+
+// id3Field 将 ID3v2 帧 ID 映射为标签字段
+func id3Field(id string) string {
+	switch id {
+	case "TPE1", "TP1":
+		return "artist"
+	case "TALB", "TAL":
+		return "album"
+	case "TIT2", "TT2":
+		return "title"
+	case "TRCK", "TRK":
+		return "track"
+	case "TPOS", "TPA":
+		return "disc"
+	case "TYER", "TYE", "TDRC", "TDOR":
+		return "year"
+	}
+	return ""
+}
+
+// removeUnsync 还原 ID3 的反同步编码（0xFF 0x00 → 0xFF）
+func removeUnsync(data []byte) []byte {
+	return bytes.ReplaceAll(data, []byte{0xFF, 0x00}, []byte{0xFF})
+}
+
+// decodeID3Text 按编码字节解码文本帧，多个值时只取第一个
+func decodeID3Text(data []byte) string {
+	if len(data) < 2 {
+		return ""
+	}
+	encoding, data := data[0], data[1:]
+
+	var text string
+	switch encoding {
+	case 1, 2: // UTF-16（带 BOM）/ UTF-16BE
+		var order binary.ByteOrder = binary.BigEndian
+		if len(data) >= 2 && data[0] == 0xFF && data[1] == 0xFE {
+			order, data = binary.LittleEndian, data[2:]
+		} else if len(data) >= 2 && data[0] == 0xFE && data[1] == 0xFF {
+			data = data[2:]
+		}
+		units := make([]uint16, 0, len(data)/2)
+		for i := 0; i+1 < len(data); i += 2 {
+			u := order.Uint16(data[i:])
+			if u == 0 {
+				break
+			}
+			units = append(units, u)
+		}
+		text = string(utf16.Decode(units))
+	case 3: // UTF-8
+		text, _, _ = strings.Cut(string(data), "\x00")
+	default: // ISO-8859-1
+		text = decodeLatin1(data)
+	}
+	return strings.TrimSpace(text)
+}
+
+// decodeLatin1 将 ISO-8859-1 字节转换为字符串，遇到 NUL 截止
+func decodeLatin1(data []byte) string {
+	runes := make([]rune, 0, len(data))
+	for _, b := range data {
+		if b == 0 {
+			break
+		}
+		runes = append(runes, rune(b))
+	}
+	return string(runes)
+}
+
+// readID3v1 读取文件末尾 128 字节的 ID3v1 标签，只补全尚未读到的字段
+func readID3v1(f *os.File, size int64, meta *AudioMetadata) {
+	if size < 128 {
+		return
+	}
+	tag := make([]byte, 128)
+	if _, err := f.ReadAt(tag, size-128); err != nil || string(tag[:3]) != "TAG" {
+		return
+	}
+	text := func(b []byte) string {
+		return strings.TrimSpace(decodeLatin1(b))
+	}
+	if meta.Title == "" {
+		meta.Title = text(tag[3:33])
+	}
+	if meta.Artist == "" {
+		meta.Artist = text(tag[33:63])
+	}
+	if meta.Album == "" {
+		meta.Album = text(tag[63:93])
+	}
+	if meta.Year == 0 {
+		meta.Year = parseLeadingInt(text(tag[93:97]))
+	}
+	// ID3v1.1：注释第 29 字节为 0 时，第 30 字节是音轨号
+	if meta.Track == 0 && tag[125] == 0 && tag[126] != 0 {
+		meta.Track = int(tag[126])
+	}
+}
+
+// ---------- Vorbis comment（FLAC / OGG） ----------
+
+// readFLACTags 遍历 FLAC 元数据块，读取 VORBIS_COMMENT
+func readFLACTags(f *os.File, size int64, meta *AudioMetadata) error {
+	hdr := make([]byte, 4)
+	for off := int64(4); off+4 <= size; {
+		if _, err := f.ReadAt(hdr, off); err != nil {
+			return err
+		}
+		last := hdr[0]&0x80 != 0
+		blockType := hdr[0] & 0x7F
+		length := int64(hdr[1])<<16 | int64(hdr[2])<<8 | int64(hdr[3])
+		body := off + 4
+
+		if blockType == 4 {
+			if length > maxMetadataBlock {
+				return errors.New("vorbis comment block too large")
+			}
+			data := make([]byte, length)
+			if _, err := f.ReadAt(data, body); err != nil {
+				return err
+			}
+			parseVorbisComments(data, meta)
+			return nil
+		}
+		if last {
+			break
+		}
+		off = body + length
+	}
+	return nil
+}
+
+// readOggTags 拼接 OGG 页中的数据包，从第二个数据包（注释头）读取 Vorbis/Opus 标签
+func readOggTags(f *os.File, meta *AudioMetadata) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	var packet []byte
+	packetIndex := 0
+	hdr := make([]byte, 27)
+	for pages := 0; pages < 256; pages++ {
+		if _, err := io.ReadFull(f, hdr); err != nil || string(hdr[:4]) != "OggS" {
+			break
+		}
+		segTable := make([]byte, hdr[26])
+		if _, err := io.ReadFull(f, segTable); err != nil {
+			break
+		}
+		for _, seg := range segTable {
+			data := make([]byte, seg)
+			if _, err := io.ReadFull(f, data); err != nil {
+				return nil
+			}
+			if packetIndex == 1 && len(packet) < maxMetadataBlock {
+				packet = append(packet, data...)
+			}
+			// 小于 255 的段表示数据包结束
+			if seg < 255 {
+				if packetIndex == 1 {
+					parseOggCommentPacket(packet, meta)
+					return nil
+				}
+				packetIndex++
+			}
+		}
+	}
+	// 注释包过大被截断时，尽量解析已读部分
+	if len(packet) > 0 {
+		parseOggCommentPacket(packet, meta)
+	}
+	return nil
+}
+
+// parseOggCommentPacket 去掉 Vorbis/Opus 注释包头后解析注释
+func parseOggCommentPacket(packet []byte, meta *AudioMetadata) {
+	switch {
+	case bytes.HasPrefix(packet, []byte("\x03vorbis")):
+		parseVorbisComments(packet[7:], meta)
+	case bytes.HasPrefix(packet, []byte("OpusTags")):
+		parseVorbisComments(packet[8:], meta)
+	}
+}
+
+// parseVorbisComments 解析 Vorbis 注释（小端长度 + KEY=value），容忍截断
+func parseVorbisComments(data []byte, meta *AudioMetadata) {
+	if len(data) < 4 {
+		return
+	}
+	vendorLen := int(binary.LittleEndian.Uint32(data))
+	pos := 4 + vendorLen
+	if pos+4 > len(data) || vendorLen < 0 {
+		return
+	}
+	count := int(binary.LittleEndian.Uint32(data[pos:]))
+	pos += 4
+
+	for i := 0; i < count && pos+4 <= len(data); i++ {
+		n := int(binary.LittleEndian.Uint32(data[pos:]))
+		pos += 4
+		if n < 0 || pos+n > len(data) {
+			return
+		}
+		key, value, ok := strings.Cut(string(data[pos:pos+n]), "=")
+		pos += n
+		if !ok {
+			continue
+		}
+		switch strings.ToUpper(key) {
+		case "ARTIST":
+			setAudioField(meta, "artist", value)
+		case "ALBUM":
+			setAudioField(meta, "album", value)
+		case "TITLE":
+			setAudioField(meta, "title", value)
+		case "TRACKNUMBER":
+			setAudioField(meta, "track", value)
+		case "DISCNUMBER":
+			setAudioField(meta, "disc", value)
+		case "DATE", "YEAR":
+			setAudioField(meta, "year", value)
+		}
+	}
+}
+
+// ---------- MP4 / M4A ----------
+
+// readMP4Tags 读取 moov/udta/meta/ilst 中的 iTunes 风格标签
+func readMP4Tags(f *os.File, size int64, meta *AudioMetadata) error {
+	top, err := readBMFFBoxes(f, 0, size)
+	if err != nil && len(top) == 0 {
+		return err
+	}
+	moov, ok := findBMFFBox(top, "moov")
+	if !ok {
+		return errors.New("mp4 moov box not found")
+	}
+	moovChildren, _ := readBMFFChildren(f, moov, 0)
+	udta, ok := findBMFFBox(moovChildren, "udta")
+	if !ok {
+		return nil
+	}
+	udtaChildren, _ := readBMFFChildren(f, udta, 0)
+	metaBox, ok := findBMFFBox(udtaChildren, "meta")
+	if !ok {
+		return nil
+	}
+
+	// ISO 的 meta 是 full box；部分 QuickTime 文件省略了版本与标志
+	skip := int64(4)
+	probe := make([]byte, 8)
+	if _, err := f.ReadAt(probe, metaBox.body); err == nil && string(probe[4:8]) == "hdlr" {
+		skip = 0
+	}
+	metaChildren, _ := readBMFFChildren(f, metaBox, skip)
+	ilst, ok := findBMFFBox(metaChildren, "ilst")
+	if !ok {
+		return nil
+	}
+	items, _ := readBMFFChildren(f, ilst, 0)
+
+	for _, item := range items {
+		field := mp4Field(item.typ)
+		if field == "" || item.size() > 1<<16 {
+			continue
+		}
+		dataBoxes, _ := readBMFFChildren(f, item, 0)
+		dataBox, ok := findBMFFBox(dataBoxes, "data")
+		if !ok || dataBox.size() < 8 {
+			continue
+		}
+		data, err := readBMFFBody(f, dataBox, 1<<16)
+		if err != nil {
+			continue
+		}
+		value := data[8:] // 类型标识 4 字节 + 区域 4 字节
+
+		switch field {
+		case "track", "disc":
+			// 二进制：2 字节保留 + 2 字节序号 + 2 字节总数
+			if len(value) >= 4 {
+				setAudioField(meta, field, strconv.Itoa(int(binary.BigEndian.Uint16(value[2:4]))))
+			}
+		default:
+			setAudioField(meta, field, string(value))
+		}
+	}
+	return nil
+}
+
+// mp4Field 将 ilst 条目类型映射为标签字段
+func mp4Field(typ string) string {
+	switch typ {
+	case "\xa9ART":
+		return "artist"
+	case "\xa9alb":
+		return "album"
+	case "\xa9nam":
+		return "title"
+	case "trkn":
+		return "track"
+	case "disk":
+		return "disc"
+	case "\xa9day":
+		return "year"
+	}
+	return ""
+}
+
+// ---------- 公共 ----------
+
+// setAudioField 写入字段，已有值时保留先读到的值
+// 音轨/碟号支持 "3/12" 形式，年份取开头的数字
+func setAudioField(meta *AudioMetadata, field, value string) {
+	value = strings.TrimSpace(strings.TrimRight(value, "\x00"))
+	if value == "" {
+		return
+	}
+	switch field {
+	case "artist":
+		if meta.Artist == "" {
+			meta.Artist = value
+		}
+	case "album":
+		if meta.Album == "" {
+			meta.Album = value
+		}
+	case "title":
+		if meta.Title == "" {
+			meta.Title = value
+		}
+	case "track":
+		if meta.Track == 0 {
+			meta.Track = parseLeadingInt(value)
+		}
+	case "disc":
+		if meta.Disc == 0 {
+			meta.Disc = parseLeadingInt(value)
+		}
+	case "year":
+		if meta.Year == 0 {
+			meta.Year = parseLeadingInt(value)
+		}
+	}
+}
+
+// parseLeadingInt 解析字符串开头的数字，例如 "3/12" → 3，"2004-05-01" → 2004
+func parseLeadingInt(value string) int {
+	end := 0
+	for end < len(value) && value[end] >= '0' && value[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(value[:end])
+	return n
+}
@@ -0,0 +1,203 @@
+package pathgen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+// toSyncsafe 生成 ID3v2 的 4 字节 7 位编码整数
+func toSyncsafe(n int) []byte {
+	return []byte{byte(n >> 21 & 0x7F), byte(n >> 14 & 0x7F), byte(n >> 7 & 0x7F), byte(n & 0x7F)}
+}
+
+// id3v2Fixture 生成 ID3v2.3 或 2.4 标签，frames 为帧 ID 与完整的帧数据（含编码字节）
+func id3v2Fixture(version byte, frames [][2]string) []byte {
+	var body []byte
+	for _, fr := range frames {
+		body = append(body, fr[0]...)
+		if version == 4 {
+			body = append(body, toSyncsafe(len(fr[1]))...)
+		} else {
+			body = append(body, be32(uint32(len(fr[1])))...)
+		}
+		body = append(body, 0, 0)
+		body = append(body, fr[1]...)
+	}
+	body = append(body, make([]byte, 16)...) // 填充区
+	hdr := append([]byte{'I', 'D', '3', version, 0, 0}, toSyncsafe(len(body))...)
+	return append(hdr, body...)
+}
+
+// id3v22Fixture 生成使用 3 字符帧 ID 与 3 字节长度的 ID3v2.2 标签
+func id3v22Fixture(frames [][2]string) []byte {
+	var body []byte
+	for _, fr := range frames {
+		n := len(fr[1])
+		body = append(body, fr[0]...)
+		body = append(body, byte(n>>16), byte(n>>8), byte(n))
+		body = append(body, fr[1]...)
+	}
+	hdr := append([]byte{'I', 'D', '3', 2, 0, 0}, toSyncsafe(len(body))...)
+	return append(hdr, body...)
+}
+
+// utf16Text 带 BOM 的小端 UTF-16 文本帧数据
+func utf16Text(s string) string {
+	b := []byte{1, 0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = binary.LittleEndian.AppendUint16(b, u)
+	}
+	return string(b)
+}
+
+// id3v1Fixture 文件末尾的 128 字节 ID3v1.1 标签
+func id3v1Fixture(title, artist, album, year string, track byte) []byte {
+	tag := make([]byte, 128)
+	copy(tag, "TAG")
+	copy(tag[3:33], title)
+	copy(tag[33:63], artist)
+	copy(tag[63:93], album)
+	copy(tag[93:97], year)
+	tag[126] = track
+	return tag
+}
+
+// vorbisComments 生成 Vorbis 注释：厂商字符串加 KEY=value 列表
+func vorbisComments(comments ...string) []byte {
+	le := binary.LittleEndian
+	b := le.AppendUint32(nil, 6)
+	b = append(b, "vendor"...)
+	b = le.AppendUint32(b, uint32(len(comments)))
+	for _, c := range comments {
+		b = le.AppendUint32(b, uint32(len(c)))
+		b = append(b, c...)
+	}
+	return b
+}
+
+func flacFixture(comments []byte) []byte {
+	block := func(typ byte, last bool, data []byte) []byte {
+		if last {
+			typ |= 0x80
+		}
+		return append([]byte{typ, byte(len(data) >> 16), byte(len(data) >> 8), byte(len(data))}, data...)
+	}
+	b := []byte("fLaC")
+	b = append(b, block(0, false, make([]byte, 34))...) // STREAMINFO
+	return append(b, block(4, true, comments)...)
+}
+
+// oggFixture 把数据包放在一个 OGG 页中，每个数据包都小于 255 字节
+func oggFixture(packets ...[]byte) []byte {
+	hdr := make([]byte, 27)
+	copy(hdr, "OggS")
+	hdr[26] = byte(len(packets))
+	var segs, data []byte
+	for _, p := range packets {
+		segs = append(segs, byte(len(p)))
+		data = append(data, p...)
+	}
+	return bytes.Join([][]byte{hdr, segs, data}, nil)
+}
+
+// m4aFixture moov/udta/meta/ilst 中的 iTunes 标签
+func m4aFixture() []byte {
+	item := func(typ string, value []byte) []byte {
+		return box(typ, box("data", be32(1), be32(0), value))
+	}
+	ilst := box("ilst",
+		item("\xa9ART", []byte("Daft Punk")),
+		item("\xa9alb", []byte("Discovery")),
+		item("\xa9nam", []byte("One More Time")),
+		item("trkn", []byte{0, 0, 0, 1, 0, 14, 0, 0}),
+		item("disk", []byte{0, 0, 0, 1, 0, 1}),
+		item("\xa9day", []byte("2001-03-12")),
+	)
+	meta := fullBox("meta", 0, fullBox("hdlr", 0, be32(0), []byte("mdirappl"), make([]byte, 9)), ilst)
+	return append(box("ftyp", []byte("M4A "), be32(0)), box("moov", box("udta", meta))...)
+}
+
+func TestReadAudioMetadata(t *testing.T) {
+	full := AudioMetadata{Artist: "Daft Punk", Album: "Discovery", Title: "One More Time", Track: 1, Disc: 1, Year: 2001}
+	comments := vorbisComments("ARTIST=Daft Punk", "album=Discovery", "TITLE=One More Time",
+		"TRACKNUMBER=1/14", "DISCNUMBER=1", "DATE=2001-03-12", "NOEQUALS")
+
+	tests := []struct {
+		name string
+		file string
+		data []byte
+		want AudioMetadata
+	}{
+		{"id3v2.3", "a.mp3", id3v2Fixture(3, [][2]string{
+			{"TPE1", "\x00Daft Punk"}, {"TALB", utf16Text("Discovery")}, {"TIT2", "\x03One More Time\x00"},
+			{"TRCK", "\x001/14"}, {"TPOS", "\x001"}, {"TYER", "\x002001"}, {"COMM", "\x00ignored"},
+		}), full},
+		{"id3v2.4", "a.mp3", id3v2Fixture(4, [][2]string{
+			{"TPE1", "\x03Daft Punk"}, {"TDRC", "\x032001-03-12"},
+		}), AudioMetadata{Artist: "Daft Punk", Year: 2001}},
+		{"id3v2.2", "a.mp3", id3v22Fixture([][2]string{{"TT2", "\x00One More Time"}, {"TRK", "\x005"}}),
+			AudioMetadata{Title: "One More Time", Track: 5}},
+		{"id3v2 completed by id3v1", "a.mp3",
+			append(id3v2Fixture(3, [][2]string{{"TIT2", "\x00From v2"}}), id3v1Fixture("From v1", "Artist", "Album", "1999", 7)...),
+			AudioMetadata{Title: "From v2", Artist: "Artist", Album: "Album", Year: 1999, Track: 7}},
+		{"id3v1 only", "a.mp3",
+			append([]byte{0xFF, 0xFB, 0x90, 0x00}, id3v1Fixture("Title", "Artist", "Album", "1999", 3)...),
+			AudioMetadata{Title: "Title", Artist: "Artist", Album: "Album", Year: 1999, Track: 3}},
+		{"flac", "a.flac", flacFixture(comments), AudioMetadata{Artist: "Daft Punk", Album: "Discovery", Title: "One More Time", Track: 1, Disc: 1, Year: 2001}},
+		{"ogg vorbis", "a.ogg", oggFixture([]byte("\x01vorbis header"), append([]byte("\x03vorbis"), comments...)), full},
+		{"opus", "a.opus", oggFixture([]byte("OpusHead"), append([]byte("OpusTags"), comments...)), full},
+		{"m4a", "a.m4a", m4aFixture(), full},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadAudioMetadata(writeFixture(t, tt.file, tt.data))
+			if err != nil {
+				t.Fatalf("ReadAudioMetadata: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadAudioMetadataErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"text", []byte("just some text, not audio")},
+		{"id3v2 bad version", append([]byte{'I', 'D', '3', 9, 0, 0}, toSyncsafe(0)...)},
+		{"id3v2 without text frames", id3v2Fixture(3, [][2]string{{"COMM", "\x00comment"}})},
+		{"flac without comments", []byte("fLaC\x80\x00\x00\x00")},
+		{"m4a without moov", box("ftyp", []byte("M4A "), be32(0))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadAudioMetadata(writeFixture(t, "x", tt.data)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func FuzzReadAudioMetadata(f *testing.F) {
+	comments := vorbisComments("ARTIST=a", "TRACKNUMBER=1")
+	for _, seed := range [][]byte{
+		id3v2Fixture(3, [][2]string{{"TPE1", "\x00a"}, {"TALB", utf16Text("b")}}),
+		id3v2Fixture(4, [][2]string{{"TIT2", "\x03c"}}),
+		id3v22Fixture([][2]string{{"TT2", "\x00c"}}),
+		flacFixture(comments),
+		oggFixture([]byte("OpusHead"), append([]byte("OpusTags"), comments...)),
+		m4aFixture(),
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// 只要求不崩溃：损坏的文件返回错误或部分标签
+		ReadAudioMetadata(writeFixture(t, "fuzz", data))
+	})
+}
@@ -0,0 +1,25 @@
+package pathgen
+
+import (
+	"rename-tool/setting/model"
+)
+
+// BatchPathGenerator 处理序列重命名的路径生成
+// 序号依赖调用顺序，同一实例应按文件顺序依次调用
+type BatchPathGenerator struct {
+	BasePathGenerator
+	counter  int
+	counters map[string]int
+}
+
+// NewBatchPathGenerator 创建计数器从零开始的序列生成器
+func NewBatchPathGenerator() *BatchPathGenerator {
+	return &BatchPathGenerator{counters: make(map[string]int)}
+}
+
+// GeneratePath 生成序列重命名后的新路径，并推进计数器
+func (g *BatchPathGenerator) GeneratePath(file string, config model.RenameConfig) (string, error) {
+	current := g.counter
+	g.counter++
+	return GenerateBatchRenamePath(file, config, current, g.counters)
+}
@@ -0,0 +1,96 @@
+package pathgen
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// maxBMFFBoxes 单层最多解析的盒子数量，防止异常文件导致长时间扫描
+const maxBMFFBoxes = 4096
+
+// bmffBox ISO BMFF（MP4/MOV/HEIC）盒子的位置信息
+type bmffBox struct {
+	typ   string
+	start int64 // 盒子起始偏移
+	body  int64 // 数据起始偏移（跳过盒子头）
+	end   int64 // 盒子结束偏移
+}
+
+// size 返回盒子数据部分的长度
+func (b bmffBox) size() int64 {
+	return b.end - b.body
+}
+
+// readBMFFBoxes 读取 [start, end) 范围内的同级盒子，只读盒子头，不读取数据
+func readBMFFBoxes(r io.ReaderAt, start, end int64) ([]bmffBox, error) {
+	var boxes []bmffBox
+	hdr := make([]byte, 16)
+
+	for off := start; off+8 <= end && len(boxes) < maxBMFFBoxes; {
+		if _, err := r.ReadAt(hdr[:8], off); err != nil {
+			return boxes, err
+		}
+		size := int64(binary.BigEndian.Uint32(hdr[:4]))
+		headerLen := int64(8)
+		switch size {
+		case 0: // 延伸到范围末尾
+			size = end - off
+		case 1: // 64 位长度
+			if _, err := r.ReadAt(hdr[8:16], off+8); err != nil {
+				return boxes, err
+			}
+			size = int64(binary.BigEndian.Uint64(hdr[8:16]))
+			headerLen = 16
+		}
+		// 截断的文件：收缩到范围末尾；剩余部分连盒子头都放不下时视为损坏
+		if size > end-off {
+			size = end - off
+		}
+		if size < headerLen {
+			return boxes, errors.New("malformed box header")
+		}
+
+		boxes = append(boxes, bmffBox{
+			typ:   string(hdr[4:8]),
+			start: off,
+			body:  off + headerLen,
+			end:   off + size,
+		})
+		off += size
+	}
+	return boxes, nil
+}
+
+// findBMFFBox 返回第一个指定类型的盒子
+func findBMFFBox(boxes []bmffBox, typ string) (bmffBox, bool) {
+	for _, box := range boxes {
+		if box.typ == typ {
+			return box, true
+		}
+	}
+	return bmffBox{}, false
+}
+
+// readBMFFChildren 读取子盒子，skip 为子盒子之前需要跳过的字节数（full box 为 4）
+func readBMFFChildren(r io.ReaderAt, box bmffBox, skip int64) ([]bmffBox, error) {
+	if box.body+skip > box.end {
+		return nil, errors.New("malformed box header")
+	}
+	return readBMFFBoxes(r, box.body+skip, box.end)
+}
+
+// readBMFFBody 读取盒子数据，超过 limit 时返回错误
+func readBMFFBody(r io.ReaderAt, box bmffBox, limit int64) ([]byte, error) {
+	if box.size() < 0 {
+		return nil, errors.New("malformed box header")
+	}
+	if box.size() > limit {
+		return nil, errors.New("box too large")
+	}
+	buf := make([]byte, box.size())
+	if n, err := r.ReadAt(buf, box.body); n < len(buf) {
+		return nil, err
+	}
+	return buf, nil
+}
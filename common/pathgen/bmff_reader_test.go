@@ -0,0 +1,115 @@
+package pathgen
+
+import (
+	"bytes"
+	"testing"
+)
+
+// box64 生成使用 64 位长度的盒子
+func box64(typ string, body []byte) []byte {
+	b := append(be32(1), typ...)
+	b = append(b, be32(0)...)
+	b = append(b, be32(uint32(16+len(body)))...)
+	return append(b, body...)
+}
+
+func TestReadBMFFBoxes(t *testing.T) {
+	type want struct {
+		typ  string
+		body int64
+		end  int64
+	}
+	// 64 位长度的盒子只剩 12 字节：收缩后放不下 16 字节的盒子头
+	short64 := append(append(be32(1), "mvhd"...), be32(0)...)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    []want
+		wantErr bool
+	}{
+		{"siblings", append(box("ftyp", []byte("isom")), box("free", nil)...),
+			[]want{{"ftyp", 8, 12}, {"free", 20, 20}}, false},
+		{"size zero extends to end", append(be32(0), "mdat1234"...),
+			[]want{{"mdat", 8, 12}}, false},
+		{"64-bit size", box64("mdat", []byte("data")),
+			[]want{{"mdat", 16, 20}}, false},
+		{"truncated box shrinks to end", append(be32(100), "moov1234"...),
+			[]want{{"moov", 8, 12}}, false},
+		{"trailing bytes shorter than a header", append(box("free", nil), 0, 0, 0),
+			[]want{{"free", 8, 8}}, false},
+		{"size smaller than header", append(be32(4), "free"...), nil, true},
+		{"64-bit size shrunk below header", short64, nil, true},
+		{"64-bit size shrunk below header before next box", append(short64, box("free", be32(0xFFFFFFFF))...), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			boxes, err := readBMFFBoxes(bytes.NewReader(tt.data), 0, int64(len(tt.data)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(boxes) != len(tt.want) {
+				t.Fatalf("got %d boxes, want %d", len(boxes), len(tt.want))
+			}
+			for i, w := range tt.want {
+				b := boxes[i]
+				if b.typ != w.typ || b.body != w.body || b.end != w.end {
+					t.Errorf("box %d = %s [%d, %d), want %s [%d, %d)", i, b.typ, b.body, b.end, w.typ, w.body, w.end)
+				}
+			}
+		})
+	}
+}
+
+func TestReadBMFFChildrenAndBody(t *testing.T) {
+	data := fullBox("meta", 0, box("pitm", be16(1)), box("free", nil))
+	r := bytes.NewReader(data)
+	top, err := readBMFFBoxes(r, 0, int64(len(data)))
+	if err != nil || len(top) != 1 {
+		t.Fatalf("readBMFFBoxes = %v, %v", top, err)
+	}
+
+	children, err := readBMFFChildren(r, top[0], 4)
+	if err != nil || len(children) != 2 || children[0].typ != "pitm" || children[1].typ != "free" {
+		t.Fatalf("readBMFFChildren = %v, %v", children, err)
+	}
+	body, err := readBMFFBody(r, children[0], 16)
+	if err != nil || !bytes.Equal(body, be16(1)) {
+		t.Errorf("readBMFFBody = %v, %v", body, err)
+	}
+
+	if _, err := readBMFFBody(r, top[0], 4); err == nil {
+		t.Error("readBMFFBody over the limit: expected an error")
+	}
+	if _, err := readBMFFChildren(r, children[1], 4); err == nil {
+		t.Error("readBMFFChildren skipping past the box: expected an error")
+	}
+	if _, err := readBMFFBody(r, bmffBox{typ: "bad", body: 16, end: 12}, 16); err == nil {
+		t.Error("readBMFFBody with a negative size: expected an error")
+	}
+}
+
+func FuzzReadBMFFBoxes(f *testing.F) {
+	f.Add(heicFixture(cameraTIFF()))
+	f.Add(box64("mdat", []byte("data")))
+	f.Add(append(box("moov", append(append(be32(1), "mvhd"...), be32(0)...)), box("free", be32(0xFFFFFFFF))...))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r := bytes.NewReader(data)
+		boxes, _ := readBMFFBoxes(r, 0, int64(len(data)))
+		for _, b := range boxes {
+			if b.start > b.body || b.body > b.end || b.end > int64(len(data)) {
+				t.Fatalf("box %s out of range: [%d, %d, %d) in %d bytes", b.typ, b.start, b.body, b.end, len(data))
+			}
+			readBMFFBody(r, b, 1<<16)
+			for _, skip := range []int64{0, 4} {
+				children, _ := readBMFFChildren(r, b, skip)
+				for _, c := range children {
+					readBMFFBody(r, c, 1<<16)
+				}
+			}
+		}
+	})
+}
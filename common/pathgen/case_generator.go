@@ -12,8 +12,11 @@ func (g *CasePathGenerator) TransformName(name, caseType string) string {
 	}
 
 	ext := filepath.Ext(name)
-	base := strings.TrimSuffix(name, ext)
+	return transformCase(strings.TrimSuffix(name, ext), caseType) + ext
+}
 
+// transformCase 转换不含扩展名的名称，不识别的类型原样返回
+func transformCase(base, caseType string) string {
 	switch strings.ToLower(caseType) {
 	case "upper":
 		base = strings.ToUpper(base)
@@ -23,11 +26,8 @@ func (g *CasePathGenerator) TransformName(name, caseType string) string {
 		base = transformWords(base, true, false)
 	case "camel":
 		base = transformWords(base, true, true)
-	default:
-		// 不识别的类型，原样返回
-		return name
 	}
-	return base + ext
+	return base
 }
 
 // transformWords 将文件名按空格拆分并进行首字母转换
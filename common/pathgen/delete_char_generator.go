@@ -12,7 +12,7 @@ type DeleteCharPathGenerator struct {
 
 // GeneratePath 生成删除字符后的新路径
 func (g *DeleteCharPathGenerator) GeneratePath(file string, config model.RenameConfig) (string, error) {
-	dirPath, nameWithoutExt, ext := g.splitPath(file)
+	dirPath, nameWithoutExt, ext := g.splitPath(file, config.Folder)
 
 	runes := []rune(nameWithoutExt)
 	if config.DeleteStartPosition >= len(runes) {
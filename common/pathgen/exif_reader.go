@@ -0,0 +1,723 @@
+package pathgen
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ImageMetadata 图片内嵌元数据，缺失的字段为零值
+type ImageMetadata struct {
+	DateTaken   time.Time
+	Make        string
+	Model       string
+	Lens        string
+	ISO         int
+	Orientation int
+	Width       int
+	Height      int
+}
+
+// maxMetadataBlock 单个元数据块（EXIF、meta 盒子等）的最大读取长度
+const maxMetadataBlock = 4 << 20
+
+var errUnsupportedImage = errors.New("unsupported image format")
+
+// ReadImageMetadata 读取 JPEG/TIFF/PNG/WebP/HEIC 文件中的 EXIF 与像素尺寸
+// 只读取文件头部和元数据块，不会加载图像数据
+func ReadImageMetadata(path string) (*ImageMetadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, 12)
+	if _, err := io.ReadFull(f, head); err != nil {
+		return nil, errUnsupportedImage
+	}
+
+	meta := &ImageMetadata{}
+	switch {
+	case head[0] == 0xFF && head[1] == 0xD8:
+		err = readJPEGMetadata(f, meta)
+	case string(head[:4]) == "II*\x00" || string(head[:4]) == "MM\x00*":
+		err = parseTIFF(f, info.Size(), meta, true)
+	case string(head[:8]) == "\x89PNG\r\n\x1a\n":
+		err = readPNGMetadata(f, info.Size(), meta)
+	case string(head[:4]) == "RIFF" && string(head[8:12]) == "WEBP":
+		err = readWebPMetadata(f, info.Size(), meta)
+	case string(head[4:8]) == "ftyp":
+		err = readHEIFMetadata(f, info.Size(), meta)
+	default:
+		return nil, errUnsupportedImage
+	}
+	if err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// readJPEGMetadata 顺序扫描 JPEG 标记段，读取 APP1(EXIF) 与 SOF 中的尺寸
+func readJPEGMetadata(f *os.File, meta *ImageMetadata) error {
+	if _, err := f.Seek(2, io.SeekStart); err != nil {
+		return err
+	}
+	r := bufio.NewReader(f)
+
+	for {
+		// 标记以 0xFF 开头，可能带有填充的 0xFF
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil
+		}
+		if b != 0xFF {
+			continue
+		}
+		marker, err := r.ReadByte()
+		for err == nil && marker == 0xFF {
+			marker, err = r.ReadByte()
+		}
+		if err != nil {
+			return nil
+		}
+
+		switch {
+		case marker == 0xD9 || marker == 0xDA: // EOI / SOS：之后是图像数据
+			return nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7): // 无长度的标记
+			continue
+		}
+
+		var lenBuf [2]byte
+		if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+			return nil
+		}
+		segLen := int(binary.BigEndian.Uint16(lenBuf[:])) - 2
+		if segLen < 0 {
+			return errors.New("malformed jpeg segment")
+		}
+
+		switch {
+		case marker == 0xE1:
+			seg := make([]byte, segLen)
+			if _, err := io.ReadFull(r, seg); err != nil {
+				return nil
+			}
+			if bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+				tiff := seg[6:]
+				_ = parseTIFF(bytes.NewReader(tiff), int64(len(tiff)), meta, false)
+			}
+		case isJPEGFrameMarker(marker):
+			frame := make([]byte, segLen)
+			if _, err := io.ReadFull(r, frame); err != nil || len(frame) < 5 {
+				return nil
+			}
+			meta.Height = int(binary.BigEndian.Uint16(frame[1:3]))
+			meta.Width = int(binary.BigEndian.Uint16(frame[3:5]))
+			// EXIF 总在帧头之前，读到帧头即可结束
+			return nil
+		default:
+			if _, err := r.Discard(segLen); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// isJPEGFrameMarker 判断是否为 SOFn 帧头标记
+func isJPEGFrameMarker(marker byte) bool {
+	return marker >= 0xC0 && marker <= 0xCF &&
+		marker != 0xC4 && marker != 0xC8 && marker != 0xCC
+}
+
+// readPNGMetadata 读取 IHDR 中的尺寸和 eXIf 块中的 EXIF
+func readPNGMetadata(f *os.File, size int64, meta *ImageMetadata) error {
+	hdr := make([]byte, 8)
+	for off := int64(8); off+8 <= size; {
+		if _, err := f.ReadAt(hdr, off); err != nil {
+			return nil
+		}
+		length := int64(binary.BigEndian.Uint32(hdr[:4]))
+		typ := string(hdr[4:8])
+		body := off + 8
+
+		switch typ {
+		case "IHDR":
+			data := make([]byte, 8)
+			if _, err := f.ReadAt(data, body); err == nil {
+				meta.Width = int(binary.BigEndian.Uint32(data[:4]))
+				meta.Height = int(binary.BigEndian.Uint32(data[4:8]))
+			}
+		case "eXIf":
+			if length <= maxMetadataBlock {
+				_ = parseTIFF(io.NewSectionReader(f, body, length), length, meta, false)
+			}
+		case "IEND":
+			return nil
+		}
+		off = body + length + 4 // 数据 + CRC
+	}
+	return nil
+}
+
+// readWebPMetadata 读取 VP8X/VP8/VP8L 中的尺寸和 EXIF 块
+func readWebPMetadata(f *os.File, size int64, meta *ImageMetadata) error {
+	hdr := make([]byte, 8)
+	for off := int64(12); off+8 <= size; {
+		if _, err := f.ReadAt(hdr, off); err != nil {
+			return nil
+		}
+		typ := string(hdr[:4])
+		length := int64(binary.LittleEndian.Uint32(hdr[4:8]))
+		body := off + 8
+
+		switch typ {
+		case "VP8X":
+			data := make([]byte, 10)
+			if _, err := f.ReadAt(data, body); err == nil {
+				meta.Width = int(uint32(data[4])|uint32(data[5])<<8|uint32(data[6])<<16) + 1
+				meta.Height = int(uint32(data[7])|uint32(data[8])<<8|uint32(data[9])<<16) + 1
+			}
+		case "VP8 ":
+			data := make([]byte, 10)
+			if _, err := f.ReadAt(data, body); err == nil && meta.Width == 0 {
+				meta.Width = int(binary.LittleEndian.Uint16(data[6:8]) & 0x3FFF)
+				meta.Height = int(binary.LittleEndian.Uint16(data[8:10]) & 0x3FFF)
+			}
+		case "VP8L":
+			data := make([]byte, 5)
+			if _, err := f.ReadAt(data, body); err == nil && data[0] == 0x2F && meta.Width == 0 {
+				bits := binary.LittleEndian.Uint32(data[1:5])
+				meta.Width = int(bits&0x3FFF) + 1
+				meta.Height = int((bits>>14)&0x3FFF) + 1
+			}
+		case "EXIF":
+			if length <= maxMetadataBlock {
+				data := make([]byte, length)
+				if _, err := f.ReadAt(data, body); err == nil {
+					data = bytes.TrimPrefix(data, []byte("Exif\x00\x00"))
+					_ = parseTIFF(bytes.NewReader(data), int64(len(data)), meta, false)
+				}
+			}
+		}
+		off = body + length + length%2 // 块按偶数字节对齐
+	}
+	return nil
+}
+
+// readHEIFMetadata 从 HEIC/AVIF 的 meta 盒子中定位 Exif 项与主图像的 ispe 尺寸
+func readHEIFMetadata(f *os.File, size int64, meta *ImageMetadata) error {
+	top, err := readBMFFBoxes(f, 0, size)
+	if err != nil && len(top) == 0 {
+		return err
+	}
+	metaBox, ok := findBMFFBox(top, "meta")
+	if !ok {
+		return errors.New("heif meta box not found")
+	}
+	data, err := readBMFFBody(f, metaBox, maxMetadataBlock)
+	if err != nil {
+		return err
+	}
+	// meta 是 full box，子盒子前有 4 字节版本与标志
+	children, err := readBMFFBoxes(bytes.NewReader(data), 4, int64(len(data)))
+	if err != nil && len(children) == 0 {
+		return err
+	}
+
+	primary := uint32(0)
+	if box, ok := findBMFFBox(children, "pitm"); ok {
+		primary = parseHEIFPrimaryItem(data[box.body:box.end])
+	}
+	if box, ok := findBMFFBox(children, "iprp"); ok {
+		meta.Width, meta.Height = parseHEIFImageSize(data[box.body:box.end], primary)
+	}
+
+	exifID, ok := uint32(0), false
+	if box, found := findBMFFBox(children, "iinf"); found {
+		exifID, ok = findHEIFItem(data[box.body:box.end], "Exif")
+	}
+	if !ok {
+		return nil
+	}
+	box, found := findBMFFBox(children, "iloc")
+	if !found {
+		return nil
+	}
+	offset, length, ok := locateHEIFItem(data[box.body:box.end], exifID)
+	if !ok || length <= 4 || length > maxMetadataBlock {
+		return nil
+	}
+
+	exif := make([]byte, length)
+	if _, err := f.ReadAt(exif, offset); err != nil {
+		return nil
+	}
+	// Exif 项以 4 字节的 TIFF 头偏移开头
+	skip := int64(binary.BigEndian.Uint32(exif[:4])) + 4
+	if skip >= length {
+		return nil
+	}
+	tiff := exif[skip:]
+	return parseTIFF(bytes.NewReader(tiff), int64(len(tiff)), meta, false)
+}
+
+// parseHEIFPrimaryItem 解析 pitm 盒子中的主图像 ID
+func parseHEIFPrimaryItem(body []byte) uint32 {
+	if len(body) < 6 {
+		return 0
+	}
+	if body[0] == 0 {
+		return uint32(binary.BigEndian.Uint16(body[4:6]))
+	}
+	if len(body) < 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint32(body[4:8])
+}
+
+// findHEIFItem 在 iinf 中查找指定类型的项，返回项 ID
+func findHEIFItem(body []byte, itemType string) (uint32, bool) {
+	if len(body) < 6 {
+		return 0, false
+	}
+	skip := int64(6) // 版本标志 + 16 位数量
+	if body[0] != 0 {
+		skip = 8
+	}
+	entries, _ := readBMFFBoxes(bytes.NewReader(body), skip, int64(len(body)))
+	for _, entry := range entries {
+		if entry.typ != "infe" {
+			continue
+		}
+		e := body[entry.body:entry.end]
+		if len(e) < 4 {
+			continue
+		}
+		var id uint32
+		var typ []byte
+		switch e[0] {
+		case 2:
+			if len(e) < 12 {
+				continue
+			}
+			id = uint32(binary.BigEndian.Uint16(e[4:6]))
+			typ = e[8:12]
+		case 3:
+			if len(e) < 14 {
+				continue
+			}
+			id = binary.BigEndian.Uint32(e[4:8])
+			typ = e[10:14]
+		default:
+			continue
+		}
+		if string(typ) == itemType {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// parseHEIFImageSize 通过 ipma 找到主图像关联的 ispe 属性；找不到时取最大的 ispe
+func parseHEIFImageSize(body []byte, primary uint32) (width, height int) {
+	boxes, _ := readBMFFBoxes(bytes.NewReader(body), 0, int64(len(body)))
+
+	var props []bmffBox
+	if ipco, ok := findBMFFBox(boxes, "ipco"); ok {
+		props, _ = readBMFFBoxes(bytes.NewReader(body), ipco.body, ipco.end)
+	}
+	ispeSize := func(box bmffBox) (int, int) {
+		if box.typ != "ispe" || box.size() < 12 {
+			return 0, 0
+		}
+		e := body[box.body:box.end]
+		return int(binary.BigEndian.Uint32(e[4:8])), int(binary.BigEndian.Uint32(e[8:12]))
+	}
+
+	if ipma, ok := findBMFFBox(boxes, "ipma"); ok && primary != 0 {
+		for _, index := range heifItemProperties(body[ipma.body:ipma.end], primary) {
+			if index > 0 && index <= len(props) {
+				if w, h := ispeSize(props[index-1]); w > 0 {
+					return w, h
+				}
+			}
+		}
+	}
+
+	for _, prop := range props {
+		if w, h := ispeSize(prop); w*h > width*height {
+			width, height = w, h
+		}
+	}
+	return width, height
+}
+
+// heifItemProperties 解析 ipma，返回指定项关联的属性序号（从 1 开始）
+func heifItemProperties(body []byte, itemID uint32) []int {
+	if len(body) < 8 {
+		return nil
+	}
+	version, flags := body[0], body[3]
+	count := binary.BigEndian.Uint32(body[4:8])
+	pos := 8
+
+	for i := uint32(0); i < count; i++ {
+		var id uint32
+		if version < 1 {
+			if pos+2 > len(body) {
+				return nil
+			}
+			id = uint32(binary.BigEndian.Uint16(body[pos:]))
+			pos += 2
+		} else {
+			if pos+4 > len(body) {
+				return nil
+			}
+			id = binary.BigEndian.Uint32(body[pos:])
+			pos += 4
+		}
+		if pos >= len(body) {
+			return nil
+		}
+		assocCount := int(body[pos])
+		pos++
+
+		var indexes []int
+		for j := 0; j < assocCount; j++ {
+			if flags&1 != 0 {
+				if pos+2 > len(body) {
+					return nil
+				}
+				indexes = append(indexes, int(binary.BigEndian.Uint16(body[pos:])&0x7FFF))
+				pos += 2
+			} else {
+				if pos >= len(body) {
+					return nil
+				}
+				indexes = append(indexes, int(body[pos]&0x7F))
+				pos++
+			}
+		}
+		if id == itemID {
+			return indexes
+		}
+	}
+	return nil
+}
+
+// locateHEIFItem 解析 iloc，返回指定项第一个数据段在文件中的位置
+func locateHEIFItem(body []byte, itemID uint32) (offset, length int64, ok bool) {
+	if len(body) < 8 {
+		return 0, 0, false
+	}
+	version := body[0]
+	offsetSize := int(body[4] >> 4)
+	lengthSize := int(body[4] & 0x0F)
+	baseOffsetSize := int(body[5] >> 4)
+	indexSize := 0
+	if version == 1 || version == 2 {
+		indexSize = int(body[5] & 0x0F)
+	}
+	pos := 6
+
+	readUint := func(n int) (uint64, bool) {
+		if n == 0 {
+			return 0, true
+		}
+		if n != 4 && n != 8 && n != 2 || pos+n > len(body) {
+			return 0, false
+		}
+		var v uint64
+		for _, b := range body[pos : pos+n] {
+			v = v<<8 | uint64(b)
+		}
+		pos += n
+		return v, true
+	}
+
+	var count uint64
+	if version < 2 {
+		count, ok = readUint(2)
+	} else {
+		count, ok = readUint(4)
+	}
+	if !ok {
+		return 0, 0, false
+	}
+
+	for i := uint64(0); i < count; i++ {
+		idSize := 2
+		if version == 2 {
+			idSize = 4
+		}
+		id, ok := readUint(idSize)
+		if !ok {
+			return 0, 0, false
+		}
+		method := uint64(0)
+		if version == 1 || version == 2 {
+			if method, ok = readUint(2); !ok {
+				return 0, 0, false
+			}
+			method &= 0x0F
+		}
+		if _, ok = readUint(2); !ok { // data_reference_index
+			return 0, 0, false
+		}
+		base, ok := readUint(baseOffsetSize)
+		if !ok {
+			return 0, 0, false
+		}
+		extents, ok := readUint(2)
+		if !ok {
+			return 0, 0, false
+		}
+
+		for j := uint64(0); j < extents; j++ {
+			if _, ok = readUint(indexSize); !ok {
+				return 0, 0, false
+			}
+			extOffset, ok := readUint(offsetSize)
+			if !ok {
+				return 0, 0, false
+			}
+			extLength, ok := readUint(lengthSize)
+			if !ok {
+				return 0, 0, false
+			}
+			// 只支持按文件偏移存储（construction_method 0）的第一个数据段
+			if uint32(id) == itemID && j == 0 && method == 0 {
+				return int64(base + extOffset), int64(extLength), true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// EXIF/TIFF 标签
+const (
+	tagImageWidth        = 0x0100
+	tagImageLength       = 0x0101
+	tagMake              = 0x010F
+	tagModel             = 0x0110
+	tagOrientation       = 0x0112
+	tagDateTime          = 0x0132
+	tagExifIFD           = 0x8769
+	tagISO               = 0x8827
+	tagDateTimeOriginal  = 0x9003
+	tagDateTimeDigitized = 0x9004
+	tagPixelXDimension   = 0xA002
+	tagPixelYDimension   = 0xA003
+	tagLensModel         = 0xA434
+)
+
+// maxIFDEntries 单个 IFD 最多读取的条目数
+const maxIFDEntries = 1024
+
+// tiffEntry IFD 中的单个条目
+type tiffEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value [4]byte
+}
+
+// tiffParser 在有限范围内解析 TIFF 结构，所有偏移都做越界检查
+type tiffParser struct {
+	r     io.ReaderAt
+	size  int64
+	order binary.ByteOrder
+}
+
+// parseTIFF 解析 TIFF 头与 IFD0/EXIF IFD，useImageSize 表示 IFD0 的尺寸即图像尺寸（TIFF 文件本身）
+func parseTIFF(r io.ReaderAt, size int64, meta *ImageMetadata, useImageSize bool) error {
+	hdr := make([]byte, 8)
+	if size < 8 {
+		return errors.New("tiff header too short")
+	}
+	if _, err := r.ReadAt(hdr, 0); err != nil {
+		return err
+	}
+
+	p := &tiffParser{r: r, size: size}
+	switch string(hdr[:2]) {
+	case "II":
+		p.order = binary.LittleEndian
+	case "MM":
+		p.order = binary.BigEndian
+	default:
+		return errors.New("invalid tiff byte order")
+	}
+	if p.order.Uint16(hdr[2:4]) != 42 {
+		return errors.New("invalid tiff magic")
+	}
+
+	ifd0, err := p.readIFD(int64(p.order.Uint32(hdr[4:8])))
+	if err != nil {
+		return err
+	}
+
+	var dateTime string
+	var exifOffset int64
+	for _, e := range ifd0 {
+		switch e.tag {
+		case tagMake:
+			meta.Make = p.ascii(e)
+		case tagModel:
+			meta.Model = p.ascii(e)
+		case tagOrientation:
+			meta.Orientation, _ = p.uintValue(e)
+		case tagDateTime:
+			dateTime = p.ascii(e)
+		case tagExifIFD:
+			v, _ := p.uintValue(e)
+			exifOffset = int64(v)
+		case tagImageWidth:
+			if v, ok := p.uintValue(e); ok && useImageSize {
+				meta.Width = v
+			}
+		case tagImageLength:
+			if v, ok := p.uintValue(e); ok && useImageSize {
+				meta.Height = v
+			}
+		}
+	}
+
+	var original, digitized string
+	if exifOffset > 0 {
+		exifIFD, _ := p.readIFD(exifOffset)
+		for _, e := range exifIFD {
+			switch e.tag {
+			case tagDateTimeOriginal:
+				original = p.ascii(e)
+			case tagDateTimeDigitized:
+				digitized = p.ascii(e)
+			case tagISO:
+				meta.ISO, _ = p.uintValue(e)
+			case tagLensModel:
+				meta.Lens = p.ascii(e)
+			case tagPixelXDimension:
+				if v, ok := p.uintValue(e); ok && meta.Width == 0 {
+					meta.Width = v
+				}
+			case tagPixelYDimension:
+				if v, ok := p.uintValue(e); ok && meta.Height == 0 {
+					meta.Height = v
+				}
+			}
+		}
+	}
+
+	// 拍摄时间优先级：DateTimeOriginal > DateTimeDigitized > DateTime
+	for _, s := range []string{original, digitized, dateTime} {
+		if t, err := time.ParseInLocation("2006:01:02 15:04:05", s, time.Local); err == nil {
+			meta.DateTaken = t
+			break
+		}
+	}
+	return nil
+}
+
+// readIFD 读取指定偏移处的 IFD 条目
+func (p *tiffParser) readIFD(offset int64) ([]tiffEntry, error) {
+	if offset <= 0 || offset+2 > p.size {
+		return nil, errors.New("ifd offset out of range")
+	}
+	var countBuf [2]byte
+	if _, err := p.r.ReadAt(countBuf[:], offset); err != nil {
+		return nil, err
+	}
+	count := int64(p.order.Uint16(countBuf[:]))
+	if count > maxIFDEntries || offset+2+count*12 > p.size {
+		return nil, errors.New("ifd out of range")
+	}
+
+	buf := make([]byte, count*12)
+	if _, err := p.r.ReadAt(buf, offset+2); err != nil {
+		return nil, err
+	}
+	entries := make([]tiffEntry, count)
+	for i := range entries {
+		b := buf[i*12:]
+		entries[i] = tiffEntry{
+			tag:   p.order.Uint16(b[0:2]),
+			typ:   p.order.Uint16(b[2:4]),
+			count: p.order.Uint32(b[4:8]),
+		}
+		copy(entries[i].value[:], b[8:12])
+	}
+	return entries, nil
+}
+
+// valueBytes 返回条目的原始数据：不超过 4 字节时直接存放在条目中，否则按偏移读取
+func (p *tiffParser) valueBytes(e tiffEntry, limit int64) ([]byte, bool) {
+	unit := int64(0)
+	switch e.typ {
+	case 1, 2, 6, 7: // BYTE ASCII SBYTE UNDEFINED
+		unit = 1
+	case 3, 8: // SHORT SSHORT
+		unit = 2
+	case 4, 9: // LONG SLONG
+		unit = 4
+	default:
+		return nil, false
+	}
+	n := unit * int64(e.count)
+	if n == 0 || n > limit {
+		return nil, false
+	}
+	if n <= 4 {
+		return e.value[:n], true
+	}
+	offset := int64(p.order.Uint32(e.value[:]))
+	if offset+n > p.size {
+		return nil, false
+	}
+	buf := make([]byte, n)
+	if _, err := p.r.ReadAt(buf, offset); err != nil {
+		return nil, false
+	}
+	return buf, true
+}
+
+// ascii 读取 ASCII 条目，去掉结尾的 NUL 与空白
+func (p *tiffParser) ascii(e tiffEntry) string {
+	if e.typ != 2 {
+		return ""
+	}
+	b, ok := p.valueBytes(e, 256)
+	if !ok {
+		return ""
+	}
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// uintValue 读取 SHORT/LONG 条目的第一个值
+func (p *tiffParser) uintValue(e tiffEntry) (int, bool) {
+	b, ok := p.valueBytes(e, 64)
+	if !ok {
+		return 0, false
+	}
+	switch e.typ {
+	case 3:
+		return int(p.order.Uint16(b)), true
+	case 4:
+		return int(p.order.Uint32(b)), true
+	}
+	return 0, false
+}
@@ -0,0 +1,244 @@
+package pathgen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testTag 测试用的 TIFF 条目，value 为完整的数据
+type testTag struct {
+	tag   uint16
+	typ   uint16
+	value []byte
+}
+
+func asciiTag(tag uint16, s string) testTag {
+	return testTag{tag, 2, []byte(s + "\x00")}
+}
+
+func shortTag(tag uint16, v uint16) testTag {
+	return testTag{tag, 3, binary.LittleEndian.AppendUint16(nil, v)}
+}
+
+func longTag(tag uint16, v uint32) testTag {
+	return testTag{tag, 4, binary.LittleEndian.AppendUint32(nil, v)}
+}
+
+// tiffFixture 生成小端序 TIFF：IFD0、可选的 EXIF IFD，超过 4 字节的数据放在末尾
+func tiffFixture(ifd0, exif []testTag) []byte {
+	le := binary.LittleEndian
+	if len(exif) > 0 {
+		ifd0 = append(ifd0, testTag{tagExifIFD, 4, nil})
+	}
+	ifd0Off := 8
+	exifOff := ifd0Off + 2 + len(ifd0)*12 + 4
+	dataOff := exifOff
+	if len(exif) > 0 {
+		dataOff += 2 + len(exif)*12 + 4
+	}
+
+	buf := make([]byte, dataOff)
+	copy(buf, "II*\x00")
+	le.PutUint32(buf[4:], uint32(ifd0Off))
+	var data []byte
+	write := func(at int, tags []testTag) {
+		le.PutUint16(buf[at:], uint16(len(tags)))
+		for i, tag := range tags {
+			e := buf[at+2+i*12:]
+			value := tag.value
+			if tag.tag == tagExifIFD {
+				value = le.AppendUint32(nil, uint32(exifOff))
+			}
+			unit := map[uint16]int{2: 1, 3: 2, 4: 4}[tag.typ]
+			le.PutUint16(e[0:], tag.tag)
+			le.PutUint16(e[2:], tag.typ)
+			le.PutUint32(e[4:], uint32(len(value)/unit))
+			if len(value) <= 4 {
+				copy(e[8:12], value)
+			} else {
+				le.PutUint32(e[8:], uint32(dataOff+len(data)))
+				data = append(data, value...)
+			}
+		}
+	}
+	write(ifd0Off, ifd0)
+	if len(exif) > 0 {
+		write(exifOff, exif)
+	}
+	return append(buf, data...)
+}
+
+// cameraTIFF 常见相机 EXIF：厂商、型号、方向、拍摄时间、ISO、镜头与像素尺寸
+func cameraTIFF() []byte {
+	return tiffFixture(
+		[]testTag{asciiTag(tagMake, "Canon"), asciiTag(tagModel, "EOS R5"), shortTag(tagOrientation, 6),
+			asciiTag(tagDateTime, "2024:05:02 08:00:00")},
+		[]testTag{asciiTag(tagDateTimeOriginal, "2024:05:01 10:20:30"), shortTag(tagISO, 200),
+			asciiTag(tagLensModel, "RF24-70mm"), longTag(tagPixelXDimension, 640), longTag(tagPixelYDimension, 480)},
+	)
+}
+
+// box 生成 ISO BMFF 盒子
+func box(typ string, parts ...[]byte) []byte {
+	body := bytes.Join(parts, nil)
+	b := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	return append(append(b, typ...), body...)
+}
+
+// fullBox 生成带版本与标志的盒子
+func fullBox(typ string, version byte, parts ...[]byte) []byte {
+	return box(typ, append([][]byte{{version, 0, 0, 0}}, parts...)...)
+}
+
+func be16(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+func be32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+
+func jpegFixture(tiff []byte) []byte {
+	app1 := append([]byte("Exif\x00\x00"), tiff...)
+	b := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	b = append(b, be16(uint16(len(app1)+2))...)
+	b = append(b, app1...)
+	// SOF0：精度、高、宽、分量数
+	b = append(b, 0xFF, 0xC0, 0x00, 0x08, 8)
+	b = append(b, be16(300)...)
+	b = append(b, be16(400)...)
+	b = append(b, 1)
+	return append(b, 0xFF, 0xD9)
+}
+
+func pngFixture(tiff []byte) []byte {
+	chunk := func(typ string, data []byte) []byte {
+		b := append(be32(uint32(len(data))), typ...)
+		return append(append(b, data...), 0, 0, 0, 0) // CRC 不校验
+	}
+	ihdr := append(append(be32(1920), be32(1080)...), 8, 2, 0, 0, 0)
+	b := []byte("\x89PNG\r\n\x1a\n")
+	b = append(b, chunk("IHDR", ihdr)...)
+	b = append(b, chunk("eXIf", tiff)...)
+	return append(b, chunk("IEND", nil)...)
+}
+
+func webpFixture(tiff []byte) []byte {
+	chunk := func(typ string, data []byte) []byte {
+		b := append([]byte(typ), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+		b = append(b, data...)
+		if len(data)%2 == 1 {
+			b = append(b, 0)
+		}
+		return b
+	}
+	// VP8X：4 字节标志，之后是 24 位的宽减一、高减一
+	vp8x := []byte{0, 0, 0, 0, 0x7F, 0x02, 0x00, 0xDF, 0x01, 0x00}
+	body := append([]byte("WEBP"), chunk("VP8X", vp8x)...)
+	body = append(body, chunk("EXIF", append([]byte("Exif\x00\x00"), tiff...))...)
+	return append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...), body...)
+}
+
+// heicFixture 主图像为项 1（ispe 4032x3024），Exif 为项 2，数据位于 mdat
+func heicFixture(tiff []byte) []byte {
+	ftyp := box("ftyp", []byte("heic"), be32(0), []byte("mif1heic"))
+	infe := fullBox("infe", 2, be16(2), be16(0), []byte("Exif"), []byte{0})
+	ipco := box("ipco", fullBox("ispe", 0, be32(4032), be32(3024)))
+	ipma := fullBox("ipma", 0, be32(1), be16(1), []byte{1, 1})
+	exif := append(be32(0), tiff...)
+	meta := func(offset uint32) []byte {
+		iloc := fullBox("iloc", 0, []byte{0x44, 0x00}, be16(1), be16(2), be16(0), be16(1), be32(offset), be32(uint32(len(exif))))
+		return fullBox("meta", 0,
+			fullBox("hdlr", 0, be32(0), []byte("pict"), make([]byte, 13)),
+			fullBox("pitm", 0, be16(1)),
+			fullBox("iinf", 0, be16(1), infe),
+			box("iprp", ipco, ipma),
+			iloc,
+		)
+	}
+	offset := len(ftyp) + len(meta(0)) + 8
+	return bytes.Join([][]byte{ftyp, meta(uint32(offset)), box("mdat", exif)}, nil)
+}
+
+// writeFixture 把测试数据写入临时文件
+func writeFixture(t testing.TB, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadImageMetadata(t *testing.T) {
+	taken := time.Date(2024, 5, 1, 10, 20, 30, 0, time.Local)
+	camera := ImageMetadata{DateTaken: taken, Make: "Canon", Model: "EOS R5", Lens: "RF24-70mm", ISO: 200, Orientation: 6}
+	withSize := func(m ImageMetadata, w, h int) ImageMetadata {
+		m.Width, m.Height = w, h
+		return m
+	}
+
+	tests := []struct {
+		name string
+		file string
+		data []byte
+		want ImageMetadata
+	}{
+		{"jpeg", "a.jpg", jpegFixture(cameraTIFF()), withSize(camera, 400, 300)},
+		{"png", "a.png", pngFixture(cameraTIFF()), withSize(camera, 1920, 1080)},
+		{"webp", "a.webp", webpFixture(cameraTIFF()), withSize(camera, 640, 480)},
+		{"heic", "a.heic", heicFixture(cameraTIFF()), withSize(camera, 4032, 3024)},
+		{"tiff", "a.tif", tiffFixture([]testTag{longTag(tagImageWidth, 800), shortTag(tagImageLength, 600),
+			asciiTag(tagDateTime, "2024:05:01 10:20:30")}, nil), ImageMetadata{DateTaken: taken, Width: 800, Height: 600}},
+		{"jpeg without exif", "b.jpg", []byte{0xFF, 0xD8, 0xFF, 0xC0, 0x00, 0x08, 8, 0x00, 0x10, 0x00, 0x20, 1, 0xFF, 0xD9},
+			ImageMetadata{Width: 32, Height: 16}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadImageMetadata(writeFixture(t, tt.file, tt.data))
+			if err != nil {
+				t.Fatalf("ReadImageMetadata: %v", err)
+			}
+			if !got.DateTaken.Equal(tt.want.DateTaken) {
+				t.Errorf("DateTaken = %v, want %v", got.DateTaken, tt.want.DateTaken)
+			}
+			got.DateTaken, tt.want.DateTaken = time.Time{}, time.Time{}
+			if *got != tt.want {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadImageMetadataErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"text", []byte("just some text, not an image")},
+		{"bad tiff magic", []byte("II\x2b\x00\x08\x00\x00\x00\x00\x00\x00\x00")},
+		{"tiff ifd out of range", []byte("II*\x00\xff\xff\x00\x00\x00\x00\x00\x00")},
+		{"heic without meta", box("ftyp", []byte("heic"), be32(0))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadImageMetadata(writeFixture(t, "x", tt.data)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func FuzzReadImageMetadata(f *testing.F) {
+	for _, seed := range [][]byte{
+		jpegFixture(cameraTIFF()), pngFixture(cameraTIFF()), webpFixture(cameraTIFF()),
+		heicFixture(cameraTIFF()), cameraTIFF(),
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// 只要求不崩溃：损坏的文件返回错误或部分元数据
+		ReadImageMetadata(writeFixture(t, "fuzz", data))
+	})
+}
@@ -11,6 +11,9 @@ type ExtensionPathGenerator struct {
 
 // GeneratePath 生成修改扩展名后的新路径
 func (g *ExtensionPathGenerator) GeneratePath(file string, config model.RenameConfig) (string, error) {
-	dirPath, nameWithoutExt, _ := g.splitPath(file)
+	if config.Folder {
+		return file, nil // 文件夹没有扩展名，保持原名
+	}
+	dirPath, nameWithoutExt, _ := g.splitPath(file, false)
 	return g.joinPath(dirPath, nameWithoutExt, config.NewExtension), nil
 }
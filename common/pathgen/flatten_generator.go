@@ -0,0 +1,21 @@
+package pathgen
+
+import (
+	"path/filepath"
+
+	"rename-tool/setting/model"
+)
+
+// FlattenPathGenerator 扁平化：把子文件夹中的文件移到所选目录，名称不变
+// 重名由冲突处理策略解决，例如加上相对路径前缀（sub_dir_file.jpg）
+type FlattenPathGenerator struct {
+	BasePathGenerator
+}
+
+// GeneratePath 生成所选目录下的同名路径；文件夹本身保持不动
+func (g *FlattenPathGenerator) GeneratePath(file string, config model.RenameConfig) (string, error) {
+	if config.Folder {
+		return file, nil
+	}
+	return filepath.Join(config.SelectedDir, filepath.Base(file)), nil
+}
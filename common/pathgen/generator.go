@@ -17,9 +17,12 @@ type PathGenerator interface {
 type BasePathGenerator struct{}
 
 // splitPath 将文件路径分割为目录路径、文件名（不含扩展名）和扩展名
-func (g *BasePathGenerator) splitPath(file string) (dirPath, nameWithoutExt, ext string) {
+// 文件夹没有扩展名，名称中的点属于名称本身
+func (g *BasePathGenerator) splitPath(file string, folder bool) (dirPath, nameWithoutExt, ext string) {
 	dirPath, oldName := filepath.Split(file)
-	ext = filepath.Ext(oldName)
+	if !folder {
+		ext = filepath.Ext(oldName)
+	}
 	nameWithoutExt = oldName[:len(oldName)-len(ext)]
 	return dirPath, nameWithoutExt, ext
 }
@@ -32,6 +35,8 @@ func (g *BasePathGenerator) joinPath(dirPath, nameWithoutExt, ext string) string
 // GetPathGenerator 工厂函数，根据重命名类型返回对应的生成器
 func GetPathGenerator(renameType model.RenameType) (PathGenerator, error) {
 	switch renameType {
+	case model.RenameTypeBatch:
+		return NewBatchPathGenerator(), nil
 	case model.RenameTypeExtension:
 		return &ExtensionPathGenerator{}, nil
 	case model.RenameTypeCase:
@@ -42,6 +47,12 @@ func GetPathGenerator(renameType model.RenameType) (PathGenerator, error) {
 		return &ReplacePathGenerator{}, nil
 	case model.RenameTypeDeleteChar:
 		return &DeleteCharPathGenerator{}, nil
+	case model.RenameTypePipeline:
+		return &PipelinePathGenerator{}, nil
+	case model.RenameTypeTemplate:
+		return &TemplatePathGenerator{}, nil
+	case model.RenameTypeFlatten:
+		return &FlattenPathGenerator{}, nil
 	default:
 		return nil, fmt.Errorf("unsupported rename type: %v", renameType)
 	}
@@ -64,9 +75,8 @@ func incrementFormatCounter(ext string, counters map[string]int) {
 
 // GenerateBatchRenamePath 生成批量重命名的新路径
 func GenerateBatchRenamePath(file string, config model.RenameConfig, counter int, counters map[string]int) (string, error) {
-	dirPath, oldName := filepath.Split(file)
-	ext := filepath.Ext(oldName)
-	nameWithoutExt := oldName[:len(oldName)-len(ext)]
+	var g BasePathGenerator
+	dirPath, nameWithoutExt, ext := g.splitPath(file, config.Folder)
 
 	var parts []string
 
@@ -182,26 +192,3 @@ func GenerateDeleteCharRenamePath(file string, config model.RenameConfig) (strin
 	}
 	return generator.GeneratePath(file, config)
 }
-
-// CheckDuplicateNames 检查重名文件
-func CheckDuplicateNames(files []string, config model.RenameConfig) ([]string, error) {
-	nameMap := make(map[string]string)
-	var duplicates []string
-
-	for _, file := range files {
-		newPath, err := GenerateReplaceRenamePath(file, config)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate path for %s: %w", file, err)
-		}
-		_, newName := filepath.Split(newPath)
-
-		if oldFile, exists := nameMap[newName]; exists {
-			duplicates = append(duplicates, fmt.Sprintf("%s 和 %s 将重命名为相同的名称: %s",
-				filepath.Base(oldFile), filepath.Base(file), newName))
-		} else {
-			nameMap[newName] = file
-		}
-	}
-
-	return duplicates, nil
-}
@@ -13,7 +13,7 @@ type InsertCharPathGenerator struct {
 
 // GeneratePath 生成插入字符后的新路径
 func (g *InsertCharPathGenerator) GeneratePath(file string, config model.RenameConfig) (string, error) {
-	dirPath, nameWithoutExt, ext := g.splitPath(file)
+	dirPath, nameWithoutExt, ext := g.splitPath(file, config.Folder)
 
 	// 将文件名转换为rune切片以正确处理Unicode字符
 	runes := []rune(nameWithoutExt)
@@ -0,0 +1,233 @@
+package pathgen
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// invalidNameChars 文件名中不允许出现的字符（以 Windows 为准）
+const invalidNameChars = `\/:*?"<>|`
+
+func init() {
+	// 图片元数据
+	registerTemplateToken(imageDateToken, "taken", "exifdate")
+	registerTemplateToken(imageTextToken("make", func(m *ImageMetadata) string { return m.Make }), "make")
+	registerTemplateToken(imageTextToken("model", func(m *ImageMetadata) string { return m.Model }), "model")
+	registerTemplateToken(imageTextToken("lens", func(m *ImageMetadata) string { return m.Lens }), "lens")
+	registerTemplateToken(imageNumberToken("iso", func(m *ImageMetadata) int { return m.ISO }), "iso")
+	registerTemplateToken(imageNumberToken("orientation", func(m *ImageMetadata) int { return m.Orientation }), "orientation")
+	registerTemplateToken(dimensionToken("width", func(m *ImageMetadata) int { return m.Width }, func(m *VideoMetadata) int { return m.Width }), "width")
+	registerTemplateToken(dimensionToken("height", func(m *ImageMetadata) int { return m.Height }, func(m *VideoMetadata) int { return m.Height }), "height")
+
+	// 音频标签
+	registerTemplateToken(audioTextToken("artist", func(m *AudioMetadata) string { return m.Artist }), "artist")
+	registerTemplateToken(audioTextToken("album", func(m *AudioMetadata) string { return m.Album }), "album")
+	registerTemplateToken(audioTextToken("title", func(m *AudioMetadata) string { return m.Title }), "title")
+	registerTemplateToken(audioNumberToken("track", func(m *AudioMetadata) int { return m.Track }), "track")
+	registerTemplateToken(audioNumberToken("disc", func(m *AudioMetadata) int { return m.Disc }), "disc")
+	registerTemplateToken(audioNumberToken("year", func(m *AudioMetadata) int { return m.Year }), "audioyear")
+
+	// 视频容器
+	registerTemplateToken(videoCreatedToken, "created")
+	registerTemplateToken(videoDurationToken, "duration")
+	registerTemplateToken(videoResolutionToken, "resolution")
+	registerTemplateToken(videoFrameRateToken, "fps")
+	registerTemplateToken(videoCodecToken, "codec")
+}
+
+// imageDateToken {taken:YYYY-MM-DD_hhmmss}：拍摄时间
+func imageDateToken(ctx *templateContext, arg string) (string, error) {
+	meta, err := ctx.imageMetadata()
+	if err != nil || meta.DateTaken.IsZero() {
+		return metadataFallback(ctx, "date taken", err)
+	}
+	return formatTemplateDate(meta.DateTaken, arg), nil
+}
+
+// imageTextToken 创建读取图片文本字段的令牌，支持文本修饰符
+func imageTextToken(field string, get func(*ImageMetadata) string) templateToken {
+	return func(ctx *templateContext, arg string) (string, error) {
+		meta, err := ctx.imageMetadata()
+		if err != nil || get(meta) == "" {
+			return metadataFallback(ctx, field, err)
+		}
+		return applyTextModifiers(sanitizeNameComponent(get(meta)), arg)
+	}
+}
+
+// imageNumberToken 创建读取图片数值字段的令牌，参数为补零位数
+func imageNumberToken(field string, get func(*ImageMetadata) int) templateToken {
+	return func(ctx *templateContext, arg string) (string, error) {
+		meta, err := ctx.imageMetadata()
+		if err != nil || get(meta) == 0 {
+			return metadataFallback(ctx, field, err)
+		}
+		return formatNumber(get(meta), arg)
+	}
+}
+
+// dimensionToken 宽高令牌：优先读取图片元数据，不是图片时读取视频轨道尺寸
+func dimensionToken(field string, image func(*ImageMetadata) int, video func(*VideoMetadata) int) templateToken {
+	return func(ctx *templateContext, arg string) (string, error) {
+		if meta, err := ctx.imageMetadata(); err == nil && image(meta) > 0 {
+			return formatNumber(image(meta), arg)
+		}
+		meta, err := ctx.videoMetadata()
+		if err != nil || video(meta) == 0 {
+			return metadataFallback(ctx, field, err)
+		}
+		return formatNumber(video(meta), arg)
+	}
+}
+
+// audioTextToken 创建读取音频文本标签的令牌，支持文本修饰符
+func audioTextToken(field string, get func(*AudioMetadata) string) templateToken {
+	return func(ctx *templateContext, arg string) (string, error) {
+		meta, err := ctx.audioMetadata()
+		if err != nil || get(meta) == "" {
+			return metadataFallback(ctx, field, err)
+		}
+		return applyTextModifiers(sanitizeNameComponent(get(meta)), arg)
+	}
+}
+
+// audioNumberToken 创建读取音频数值标签的令牌，参数为补零位数
+func audioNumberToken(field string, get func(*AudioMetadata) int) templateToken {
+	return func(ctx *templateContext, arg string) (string, error) {
+		meta, err := ctx.audioMetadata()
+		if err != nil || get(meta) == 0 {
+			return metadataFallback(ctx, field, err)
+		}
+		return formatNumber(get(meta), arg)
+	}
+}
+
+// metadataFallback 元数据缺失时使用配置的替代值；未配置时作为该文件的错误返回
+func metadataFallback(ctx *templateContext, field string, err error) (string, error) {
+	if ctx.config.MetadataFallback != "" {
+		return ctx.config.MetadataFallback, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("no %s metadata: %w", field, err)
+	}
+	return "", fmt.Errorf("no %s metadata", field)
+}
+
+// maxNumberWidth 补零位数上限，文件名本身最长 255 个字符
+const maxNumberWidth = 255
+
+// formatNumber 按参数中的位数补零输出数字
+func formatNumber(value int, arg string) (string, error) {
+	if arg == "" {
+		return strconv.Itoa(value), nil
+	}
+	width, err := strconv.Atoi(arg)
+	if err != nil || width < 0 || width > maxNumberWidth {
+		return "", fmt.Errorf("invalid number width %q", arg)
+	}
+	return fmt.Sprintf("%0*d", width, value), nil
+}
+
+// sanitizeNameComponent 将元数据中的非法文件名字符和控制字符替换为 "-"
+func sanitizeNameComponent(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(invalidNameChars, r) {
+			return '-'
+		}
+		return r
+	}, value)
+	return strings.TrimSpace(value)
+}
+
+// videoCreatedToken {created:YYYY-MM-DD}：视频创建时间
+func videoCreatedToken(ctx *templateContext, arg string) (string, error) {
+	meta, err := ctx.videoMetadata()
+	if err != nil || meta.Created.IsZero() {
+		return metadataFallback(ctx, "creation time", err)
+	}
+	return formatTemplateDate(meta.Created, arg), nil
+}
+
+// videoDurationToken {duration}：视频时长
+// 无参数时输出 00m45s（超过一小时为 1h02m05s），参数 s 输出总秒数，
+// 其他参数按 hh/mm/ss 占位符格式化，例如 {duration:hh-mm-ss}
+func videoDurationToken(ctx *templateContext, arg string) (string, error) {
+	meta, err := ctx.videoMetadata()
+	if err != nil || meta.Duration <= 0 {
+		return metadataFallback(ctx, "duration", err)
+	}
+	return formatDuration(meta.Duration, arg), nil
+}
+
+// videoResolutionToken {resolution}：视频分辨率，例如 1920x1080
+func videoResolutionToken(ctx *templateContext, arg string) (string, error) {
+	meta, err := ctx.videoMetadata()
+	if err != nil || meta.Width == 0 || meta.Height == 0 {
+		return metadataFallback(ctx, "resolution", err)
+	}
+	return fmt.Sprintf("%dx%d", meta.Width, meta.Height), nil
+}
+
+// videoFrameRateToken {fps}：平均帧率，参数为小数位数，默认去掉多余的零（29.97、30）
+func videoFrameRateToken(ctx *templateContext, arg string) (string, error) {
+	meta, err := ctx.videoMetadata()
+	if err != nil || meta.FrameRate <= 0 {
+		return metadataFallback(ctx, "frame rate", err)
+	}
+	if arg == "" {
+		return strconv.FormatFloat(math.Round(meta.FrameRate*100)/100, 'f', -1, 64), nil
+	}
+	precision, err := strconv.Atoi(arg)
+	if err != nil || precision < 0 || precision > maxNumberWidth {
+		return "", fmt.Errorf("invalid fps precision %q", arg)
+	}
+	return strconv.FormatFloat(meta.FrameRate, 'f', precision, 64), nil
+}
+
+// videoCodecToken {codec}：视频编码（h264、hevc、vp9、av1 等），支持文本修饰符
+func videoCodecToken(ctx *templateContext, arg string) (string, error) {
+	meta, err := ctx.videoMetadata()
+	if err != nil || meta.Codec == "" {
+		return metadataFallback(ctx, "codec", err)
+	}
+	return applyTextModifiers(meta.Codec, arg)
+}
+
+// formatDuration 按 hh/mm/ss 占位符格式化时长；布局中没有 hh 时分钟数累计小时
+func formatDuration(d time.Duration, layout string) string {
+	total := int64(d.Round(time.Second) / time.Second)
+	switch layout {
+	case "":
+		if total >= 3600 {
+			return fmt.Sprintf("%dh%02dm%02ds", total/3600, total/60%60, total%60)
+		}
+		return fmt.Sprintf("%02dm%02ds", total/60, total%60)
+	case "s":
+		return strconv.FormatInt(total, 10)
+	}
+
+	hours, minutes := total/3600, total/60%60
+	if !strings.Contains(layout, "hh") {
+		hours, minutes = 0, total/60
+	}
+	var b strings.Builder
+	for i := 0; i < len(layout); {
+		switch {
+		case strings.HasPrefix(layout[i:], "hh"):
+			fmt.Fprintf(&b, "%02d", hours)
+		case strings.HasPrefix(layout[i:], "mm"):
+			fmt.Fprintf(&b, "%02d", minutes)
+		case strings.HasPrefix(layout[i:], "ss"):
+			fmt.Fprintf(&b, "%02d", total%60)
+		default:
+			b.WriteByte(layout[i])
+			i++
+			continue
+		}
+		i += 2
+	}
+	return b.String()
+}
@@ -0,0 +1,162 @@
+package pathgen
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rename-tool/setting/model"
+)
+
+// renderTemplate 用新的生成器渲染一个文件，返回新名称（不含文件夹）
+func renderTemplate(t *testing.T, file, template, fallback string) (string, error) {
+	t.Helper()
+	g := &TemplatePathGenerator{}
+	path, err := g.GeneratePath(file, model.RenameConfig{Template: template, MetadataFallback: fallback})
+	if err != nil {
+		return "", err
+	}
+	return filepath.Base(path), nil
+}
+
+func TestMetadataTokens(t *testing.T) {
+	photo := writeFixture(t, "IMG_1.jpg", jpegFixture(cameraTIFF())) // 拍摄时间 2024-05-01 10:20:30
+	song := writeFixture(t, "song.mp3", id3v2Fixture(3, [][2]string{
+		{"TPE1", "\x00AC/DC: Live?"}, {"TIT2", "\x00 T.N.T \x01"}, {"TRCK", "\x003/12"},
+	}))
+
+	tests := []struct {
+		file     string
+		template string
+		want     string
+	}{
+		{photo, "{taken}", "2024-05-01"},
+		{photo, "{taken:YYYY-MM-DD_hhmmss}", "2024-05-01_102030"},
+		{photo, "{taken:YYMMDD}-{name}{ext}", "240501-IMG_1.jpg"},
+		{photo, "{exifdate:hh.mm}", "10.20"},
+		{photo, "{make:upper}_{model}", "CANON_EOS R5"},
+		{photo, "{iso:5}", "00200"},
+		{photo, "{width}x{height}", "400x300"}, // JPEG 以 SOF 中的实际尺寸为准
+		// 标签中的非法字符和控制字符替换为 -，首尾空白去掉
+		{song, "{artist}", "AC-DC- Live-"},
+		{song, "{title}", "T.N.T -"},
+		{song, "{track:2} {title:lower}", "03 t.n.t -"},
+	}
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.file)+" "+tt.template, func(t *testing.T) {
+			got, err := renderTemplate(t, tt.file, tt.template, "")
+			if err != nil {
+				t.Fatalf("GeneratePath: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataFallback(t *testing.T) {
+	notes := writeFixture(t, "notes.txt", []byte("text"))
+	photo := writeFixture(t, "IMG_1.jpg", jpegFixture(cameraTIFF()))
+
+	tests := []struct {
+		file     string
+		template string
+		fallback string
+		want     string // 为空表示该文件报错
+		wantErr  string
+	}{
+		{notes, "{taken}_{name}{ext}", "", "", "no date taken metadata"},
+		{notes, "{taken}_{name}{ext}", "unknown", "unknown_notes.txt", ""},
+		{notes, "{artist} - {title}{ext}", "x", "x - x.txt", ""},
+		{photo, "{artist}{ext}", "", "", "no artist metadata"},
+		// 有元数据时不使用替代值
+		{photo, "{make}{ext}", "unknown", "Canon.jpg", ""},
+		{photo, "{fps}{ext}", "", "", "no frame rate metadata"},
+	}
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.file)+" "+tt.template, func(t *testing.T) {
+			got, err := renderTemplate(t, tt.file, tt.template, tt.fallback)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeNameComponent(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Canon", "Canon"},
+		{`a\b/c:d*e?f"g<h>i|j`, "a-b-c-d-e-f-g-h-i-j"},
+		{"tab\there\nnew", "tab-here-new"},
+		{"  padded  ", "padded"},
+		{"日本語", "日本語"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeNameComponent(tt.in); got != tt.want {
+			t.Errorf("sanitizeNameComponent(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		value   int
+		arg     string
+		want    string
+		wantErr bool
+	}{
+		{7, "", "7", false},
+		{7, "3", "007", false},
+		{1234, "2", "1234", false},
+		{7, "0", "7", false},
+		{7, "-1", "", true},
+		{7, "x", "", true},
+		{7, "255", strings.Repeat("0", 254) + "7", false},
+		{7, "256", "", true},
+		{7, "999999999", "", true},
+	}
+	for _, tt := range tests {
+		got, err := formatNumber(tt.value, tt.arg)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("formatNumber(%d, %q) = %q, %v, want %q", tt.value, tt.arg, got, err, tt.want)
+		}
+	}
+}
+
+func TestVideoTokens(t *testing.T) {
+	video := writeFixture(t, "clip.mp4", mp4Fixture())
+
+	tests := []struct {
+		template string
+		want     string // 为空表示报错
+	}{
+		{"{created:YYYYMMDD}", mp4Created.Local().Format("20060102")}, // 容器中为 UTC，按本地时间输出
+		{"{duration}", "00m10s"},
+		{"{resolution}_{codec:upper}", "1920x1080_H264"},
+		{"{fps}", "29.97"},
+		{"{fps:3}", "29.970"},
+		{"{fps:-1}", ""},
+		{"{fps:999999999}", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			got, err := renderTemplate(t, video, tt.template, "")
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("got %q, expected an error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
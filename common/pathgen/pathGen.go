@@ -12,7 +12,6 @@ type CasePathGenerator struct {
 
 // GeneratePath 生成转换后路径
 func (g *CasePathGenerator) GeneratePath(file string, config model.RenameConfig) (string, error) {
-	dir, base, ext := g.splitPath(file)
-	newName := g.TransformName(base+ext, config.CaseType)
-	return filepath.Join(dir, newName), nil
+	dir, base, ext := g.splitPath(file, config.Folder)
+	return filepath.Join(dir, transformCase(base, config.CaseType)+ext), nil
 }
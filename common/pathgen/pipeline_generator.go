@@ -0,0 +1,56 @@
+package pathgen
+
+import (
+	"errors"
+	"fmt"
+
+	"rename-tool/setting/model"
+)
+
+// PipelinePathGenerator 按顺序串联多个重命名步骤
+// 每个步骤的输出作为下一个步骤的输入，最终只得到一个目标路径
+type PipelinePathGenerator struct {
+	BasePathGenerator
+	generators []PathGenerator
+}
+
+// GeneratePath 依次执行 config.Steps 中的每个步骤，生成最终路径
+func (g *PipelinePathGenerator) GeneratePath(file string, config model.RenameConfig) (string, error) {
+	if len(config.Steps) == 0 {
+		return "", errors.New("pipeline has no steps")
+	}
+	if err := g.ensureGenerators(config.Steps); err != nil {
+		return "", err
+	}
+
+	path := file
+	for i, step := range config.Steps {
+		step.Folder = config.Folder
+		next, err := g.generators[i].GeneratePath(path, step)
+		if err != nil {
+			return "", fmt.Errorf("step %d (%s): %w", i+1, step.Type, err)
+		}
+		path = next
+	}
+	return path, nil
+}
+
+// ensureGenerators 为每个步骤创建生成器，并在后续文件间复用（序列计数器需要保持状态）
+func (g *PipelinePathGenerator) ensureGenerators(steps []model.RenameConfig) error {
+	if len(g.generators) == len(steps) {
+		return nil
+	}
+
+	g.generators = make([]PathGenerator, len(steps))
+	for i, step := range steps {
+		if step.Type == model.RenameTypePipeline {
+			return fmt.Errorf("step %d: nested pipeline is not supported", i+1)
+		}
+		generator, err := GetPathGenerator(step.Type)
+		if err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+		g.generators[i] = generator
+	}
+	return nil
+}
@@ -13,7 +13,7 @@ type ReplacePathGenerator struct {
 
 // GeneratePath 生成正则替换后的新路径
 func (g *ReplacePathGenerator) GeneratePath(file string, config model.RenameConfig) (string, error) {
-	dirPath, nameWithoutExt, ext := g.splitPath(file)
+	dirPath, nameWithoutExt, ext := g.splitPath(file, config.Folder)
 
 	var newName string
 	if config.UseRegex {
@@ -0,0 +1,209 @@
+package pathgen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"rename-tool/setting/model"
+)
+
+// templateSegment 模板中的一段：字面文本或令牌
+type templateSegment struct {
+	literal string
+	token   string
+	arg     string
+}
+
+// TemplatePathGenerator 根据命名模板生成路径，例如 {parent}_{name:lower}_{n:03}{ext}
+// 计数器依赖调用顺序，同一实例应按文件顺序依次调用
+type TemplatePathGenerator struct {
+	BasePathGenerator
+	template string
+	segments []templateSegment
+	counter  int
+	counters map[string]int
+}
+
+// GeneratePath 按模板生成新路径，并推进计数器
+func (g *TemplatePathGenerator) GeneratePath(file string, config model.RenameConfig) (string, error) {
+	if g.segments == nil || g.template != config.Template {
+		segments, err := parseTemplate(config.Template)
+		if err != nil {
+			return "", err
+		}
+		g.template, g.segments = config.Template, segments
+		g.counters = make(map[string]int)
+	}
+
+	dirPath, nameWithoutExt, ext := g.splitPath(file, config.Folder)
+
+	// 计数器：全局顺序或按扩展名单独计数
+	number := g.counter
+	g.counter++
+	if config.FormatSpecificNumbering {
+		number = getFormatCounter(ext, g.counters)
+		incrementFormatCounter(ext, g.counters)
+	}
+	if !config.StartFromZero {
+		number++
+	}
+
+	ctx := &templateContext{
+		file:    file,
+		dirPath: dirPath,
+		name:    nameWithoutExt,
+		ext:     ext,
+		counter: number,
+		config:  config,
+	}
+
+	// 最后一个含 / 或 \ 的字面文本之前的令牌都位于文件夹名中
+	lastSeparator := -1
+	for i, seg := range g.segments {
+		if strings.ContainsAny(seg.literal, `/\`) {
+			lastSeparator = i
+		}
+	}
+
+	var sb strings.Builder
+	for i, seg := range g.segments {
+		if seg.token == "" {
+			sb.WriteString(seg.literal)
+			continue
+		}
+		ctx.inFolder = i < lastSeparator
+		value, err := templateTokens[seg.token](ctx, seg.arg)
+		if err != nil {
+			return "", fmt.Errorf("{%s}: %w", seg.token, err)
+		}
+		sb.WriteString(value)
+	}
+
+	// 模板中的 / 或 \ 表示子文件夹，执行时创建缺少的文件夹并把文件移入
+	newName := strings.NewReplacer("/", string(filepath.Separator), `\`, string(filepath.Separator)).Replace(sb.String())
+	if strings.TrimSpace(filepath.Base(newName)) == "" || strings.HasSuffix(newName, string(filepath.Separator)) {
+		return "", errors.New("template produced an empty file name")
+	}
+	return filepath.Join(dirPath, newName), nil
+}
+
+// ValidateTemplate 检查模板语法及令牌是否有效
+func ValidateTemplate(template string) error {
+	_, err := parseTemplate(template)
+	return err
+}
+
+// parseTemplate 将模板拆分为字面文本和令牌，{{ 与 }} 表示字面花括号
+func parseTemplate(template string) ([]templateSegment, error) {
+	if template == "" {
+		return nil, errors.New("template is empty")
+	}
+
+	segments := []templateSegment{}
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			segments = append(segments, templateSegment{literal: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		switch {
+		case c == '{' && i+1 < len(template) && template[i+1] == '{':
+			literal.WriteByte('{')
+			i++
+		case c == '}' && i+1 < len(template) && template[i+1] == '}':
+			literal.WriteByte('}')
+			i++
+		case c == '{':
+			end := strings.IndexByte(template[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed token at position %d", i)
+			}
+			body := template[i+1 : i+end]
+			token, arg, _ := strings.Cut(body, ":")
+			token = strings.ToLower(strings.TrimSpace(token))
+			if _, ok := templateTokens[token]; !ok {
+				return nil, fmt.Errorf("unknown token {%s}", body)
+			}
+			flush()
+			segments = append(segments, templateSegment{token: token, arg: arg})
+			i += end
+		case c == '}':
+			return nil, fmt.Errorf("unexpected '}' at position %d", i)
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	flush()
+	return segments, nil
+}
+
+// templateContext 单个文件求值模板时的上下文
+type templateContext struct {
+	file    string
+	dirPath string
+	name    string
+	ext     string
+	counter int
+	config  model.RenameConfig
+
+	inFolder bool // 当前令牌位于文件夹名中（其后还有 /）
+
+	info    os.FileInfo
+	infoErr error
+	statted bool
+
+	image     *ImageMetadata
+	imageErr  error
+	imageRead bool
+
+	audio     *AudioMetadata
+	audioErr  error
+	audioRead bool
+
+	video     *VideoMetadata
+	videoErr  error
+	videoRead bool
+}
+
+// stat 按需读取文件信息，同一文件只读取一次
+func (c *templateContext) stat() (os.FileInfo, error) {
+	if !c.statted {
+		c.info, c.infoErr = os.Stat(c.file)
+		c.statted = true
+	}
+	return c.info, c.infoErr
+}
+
+// imageMetadata 按需读取图片元数据，同一文件只读取一次
+func (c *templateContext) imageMetadata() (*ImageMetadata, error) {
+	if !c.imageRead {
+		c.image, c.imageErr = ReadImageMetadata(c.file)
+		c.imageRead = true
+	}
+	return c.image, c.imageErr
+}
+
+// audioMetadata 按需读取音频标签，同一文件只读取一次
+func (c *templateContext) audioMetadata() (*AudioMetadata, error) {
+	if !c.audioRead {
+		c.audio, c.audioErr = ReadAudioMetadata(c.file)
+		c.audioRead = true
+	}
+	return c.audio, c.audioErr
+}
+
+// videoMetadata 按需读取视频容器元数据，同一文件只读取一次
+func (c *templateContext) videoMetadata() (*VideoMetadata, error) {
+	if !c.videoRead {
+		c.video, c.videoErr = ReadVideoMetadata(c.file)
+		c.videoRead = true
+	}
+	return c.video, c.videoErr
+}
@@ -0,0 +1,56 @@
+package pathgen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"rename-tool/setting/model"
+)
+
+func TestTemplateFolders(t *testing.T) {
+	dir := t.TempDir()
+	photo := filepath.Join(dir, "IMG_1.jpg") // 拍摄时间 2024-05-01
+	if err := os.WriteFile(photo, jpegFixture(cameraTIFF()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	notes := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notes, []byte("text"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	modified := time.Date(2023, 2, 3, 12, 0, 0, 0, time.Local)
+	if err := os.Chtimes(notes, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		template string
+		file     string
+		want     string
+	}{
+		{"{year}/{month}/{name}{ext}", photo, "2024/05/IMG_1.jpg"},
+		{"{year}/{month}/{name}{ext}", notes, "2023/02/notes.txt"},
+		{"{year}-{month}-{day}_{name}{ext}", photo, "2024-05-01_IMG_1.jpg"},
+		{"{ext}/{name}{ext}", photo, "jpg/IMG_1.jpg"},
+		{"{ext:upper}/{year}/{name}{ext}", notes, "TXT/2023/notes.txt"},
+		{"{ext:1..}/{name}{ext}", photo, "jpg/IMG_1.jpg"},
+		{"{name}_{ext}", photo, "IMG_1_.jpg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			g := &TemplatePathGenerator{}
+			got, err := g.GeneratePath(tt.file, model.RenameConfig{Template: tt.template})
+			if err != nil {
+				t.Fatalf("GeneratePath: %v", err)
+			}
+			if want := filepath.Join(dir, filepath.FromSlash(tt.want)); got != want {
+				t.Errorf("got %s, want %s", got, want)
+			}
+		})
+	}
+
+	if _, err := (&TemplatePathGenerator{}).GeneratePath(photo, model.RenameConfig{Template: "{month:2}/{name}{ext}"}); err == nil {
+		t.Error("{month:2}: expected an error")
+	}
+}
@@ -0,0 +1,204 @@
+package pathgen
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// templateToken 令牌求值函数，arg 为令牌冒号后的参数
+type templateToken func(ctx *templateContext, arg string) (string, error)
+
+// templateTokens 模板中可用的令牌
+var templateTokens = map[string]templateToken{}
+
+// registerTemplateToken 注册模板令牌，可一次注册多个别名
+func registerTemplateToken(fn templateToken, names ...string) {
+	for _, name := range names {
+		templateTokens[name] = fn
+	}
+}
+
+func init() {
+	registerTemplateToken(counterToken, "n", "counter")
+	registerTemplateToken(func(ctx *templateContext, arg string) (string, error) {
+		return applyTextModifiers(ctx.name, arg)
+	}, "name")
+	registerTemplateToken(extToken, "ext")
+	registerTemplateToken(func(ctx *templateContext, arg string) (string, error) {
+		return applyTextModifiers(filepath.Base(filepath.Clean(ctx.dirPath)), arg)
+	}, "parent")
+	registerTemplateToken(sizeToken, "size")
+	registerTemplateToken(modTimeToken, "date", "mtime")
+	registerTemplateToken(datePartToken("YYYY"), "year")
+	registerTemplateToken(datePartToken("MM"), "month")
+	registerTemplateToken(datePartToken("DD"), "day")
+}
+
+// extToken {ext}：扩展名；用作文件夹名时去掉开头的点，{ext}/{name}{ext} 得到 jpg/a.jpg 而不是隐藏文件夹 .jpg
+func extToken(ctx *templateContext, arg string) (string, error) {
+	value, err := applyTextModifiers(ctx.ext, arg)
+	if err != nil || !ctx.inFolder {
+		return value, err
+	}
+	return strings.TrimPrefix(value, "."), nil
+}
+
+// counterToken {n} / {n:03}：序号，可指定补零位数
+func counterToken(ctx *templateContext, arg string) (string, error) {
+	return formatNumber(ctx.counter, arg)
+}
+
+// sizeToken {size} / {size:kb}：文件大小，单位 b/kb/mb/gb
+func sizeToken(ctx *templateContext, arg string) (string, error) {
+	info, err := ctx.stat()
+	if err != nil {
+		return "", err
+	}
+	size := info.Size()
+	switch strings.ToLower(arg) {
+	case "", "b":
+	case "kb":
+		size = (size + 1<<9) >> 10
+	case "mb":
+		size = (size + 1<<19) >> 20
+	case "gb":
+		size = (size + 1<<29) >> 30
+	default:
+		return "", fmt.Errorf("unknown size unit %q", arg)
+	}
+	return strconv.FormatInt(size, 10), nil
+}
+
+// modTimeToken {date} / {date:YYYYMMDD_hhmmss}：修改时间
+func modTimeToken(ctx *templateContext, arg string) (string, error) {
+	info, err := ctx.stat()
+	if err != nil {
+		return "", err
+	}
+	return formatTemplateDate(info.ModTime(), arg), nil
+}
+
+// datePartToken {year} {month} {day}：拍摄日期的一部分，没有拍摄时间（不是照片或缺少 EXIF）时使用修改时间
+func datePartToken(layout string) templateToken {
+	return func(ctx *templateContext, arg string) (string, error) {
+		if arg != "" {
+			return "", fmt.Errorf("unexpected argument %q", arg)
+		}
+		if meta, err := ctx.imageMetadata(); err == nil && !meta.DateTaken.IsZero() {
+			return formatTemplateDate(meta.DateTaken, layout), nil
+		}
+		info, err := ctx.stat()
+		if err != nil {
+			return "", err
+		}
+		return formatTemplateDate(info.ModTime(), layout), nil
+	}
+}
+
+// dateLayoutFields 日期格式中可用的占位符（长的在前，优先匹配）
+var dateLayoutFields = []struct {
+	field  string
+	format func(t time.Time) string
+}{
+	{"YYYY", func(t time.Time) string { return fmt.Sprintf("%04d", t.Year()) }},
+	{"YY", func(t time.Time) string { return fmt.Sprintf("%02d", t.Year()%100) }},
+	{"MM", func(t time.Time) string { return fmt.Sprintf("%02d", int(t.Month())) }},
+	{"DD", func(t time.Time) string { return fmt.Sprintf("%02d", t.Day()) }},
+	{"hh", func(t time.Time) string { return fmt.Sprintf("%02d", t.Hour()) }},
+	{"mm", func(t time.Time) string { return fmt.Sprintf("%02d", t.Minute()) }},
+	{"ss", func(t time.Time) string { return fmt.Sprintf("%02d", t.Second()) }},
+}
+
+// formatTemplateDate 按 YYYY MM DD hh mm ss 占位符输出时间，其余字符原样保留，默认 YYYY-MM-DD
+func formatTemplateDate(t time.Time, layout string) string {
+	if layout == "" {
+		layout = "YYYY-MM-DD"
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(layout); {
+		matched := false
+		for _, f := range dateLayoutFields {
+			if strings.HasPrefix(layout[i:], f.field) {
+				sb.WriteString(f.format(t))
+				i += len(f.field)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			sb.WriteByte(layout[i])
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// applyTextModifiers 依次应用以冒号分隔的修饰符：
+// upper / lower / title / camel 转换大小写，a..b 按字符截取（支持负数下标）
+func applyTextModifiers(value, arg string) (string, error) {
+	if arg == "" {
+		return value, nil
+	}
+	for _, mod := range strings.Split(arg, ":") {
+		switch strings.ToLower(strings.TrimSpace(mod)) {
+		case "":
+		case "upper":
+			value = strings.ToUpper(value)
+		case "lower":
+			value = strings.ToLower(value)
+		case "title":
+			value = transformWords(value, true, false)
+		case "camel":
+			value = transformWords(value, true, true)
+		default:
+			sliced, err := sliceRunes(value, mod)
+			if err != nil {
+				return "", err
+			}
+			value = sliced
+		}
+	}
+	return value, nil
+}
+
+// sliceRunes 按 a..b 截取字符，a、b 可省略或为负数（从末尾计数），越界时自动收缩
+func sliceRunes(value, spec string) (string, error) {
+	from, to, ok := strings.Cut(spec, "..")
+	if !ok {
+		return "", fmt.Errorf("unknown modifier %q", spec)
+	}
+
+	runes := []rune(value)
+	n := len(runes)
+	bound := func(s string, def int) (int, error) {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return def, nil
+		}
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid slice %q", spec)
+		}
+		if i < 0 {
+			i += n
+		}
+		return min(max(i, 0), n), nil
+	}
+
+	start, err := bound(from, 0)
+	if err != nil {
+		return "", err
+	}
+	end, err := bound(to, n)
+	if err != nil {
+		return "", err
+	}
+	if start >= end {
+		return "", nil
+	}
+	return string(runes[start:end]), nil
+}
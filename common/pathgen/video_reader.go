@@ -0,0 +1,551 @@
+package pathgen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// VideoMetadata 视频容器元数据，缺失的字段为零值
+type VideoMetadata struct {
+	Created   time.Time
+	Duration  time.Duration
+	Width     int
+	Height    int
+	FrameRate float64
+	Codec     string
+}
+
+var errUnsupportedVideo = errors.New("unsupported video format")
+
+// mp4Epoch MP4/MOV 时间戳起点（1904-01-01 UTC）
+var mp4Epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// matroskaEpoch Matroska DateUTC 起点（2001-01-01 UTC）
+var matroskaEpoch = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ReadVideoMetadata 读取 MP4/MOV 与 MKV/WebM 的创建时间、时长、分辨率、帧率和编码
+// 只按盒子/元素头跳转并读取小块元数据，不会扫描媒体数据，多 GB 文件也只读取少量字节
+func ReadVideoMetadata(path string) (*VideoMetadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, 8)
+	if _, err := io.ReadFull(f, head); err != nil {
+		return nil, errUnsupportedVideo
+	}
+
+	meta := &VideoMetadata{}
+	switch {
+	case bytes.Equal(head[:4], []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		err = readMatroskaMetadata(f, info.Size(), meta)
+	case isMP4BoxType(string(head[4:8])):
+		err = readMP4VideoMetadata(f, info.Size(), meta)
+	default:
+		return nil, errUnsupportedVideo
+	}
+	if err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// isMP4BoxType 判断文件开头是否为 MP4/MOV 的顶层盒子（旧版 MOV 可能没有 ftyp）
+func isMP4BoxType(typ string) bool {
+	switch typ {
+	case "ftyp", "moov", "mdat", "wide", "free", "skip":
+		return true
+	}
+	return false
+}
+
+// ---------- MP4 / MOV ----------
+
+// readMP4VideoMetadata 读取 moov/mvhd 与视频轨道的 tkhd、mdhd、stsd、stts
+func readMP4VideoMetadata(f *os.File, size int64, meta *VideoMetadata) error {
+	top, err := readBMFFBoxes(f, 0, size)
+	if err != nil && len(top) == 0 {
+		return err
+	}
+	moov, ok := findBMFFBox(top, "moov")
+	if !ok {
+		return errors.New("mp4 moov box not found")
+	}
+	children, err := readBMFFChildren(f, moov, 0)
+	if err != nil && len(children) == 0 {
+		return err
+	}
+
+	if mvhd, ok := findBMFFBox(children, "mvhd"); ok {
+		if data, err := readBMFFBody(f, mvhd, 256); err == nil {
+			created, timescale, duration := parseMP4TimeHeader(data)
+			if created > 0 {
+				meta.Created = mp4Epoch.Add(time.Duration(created) * time.Second).Local()
+			}
+			if timescale > 0 {
+				meta.Duration = mp4Duration(duration, timescale)
+			}
+		}
+	}
+
+	for _, trak := range children {
+		if trak.typ != "trak" {
+			continue
+		}
+		if readMP4VideoTrack(f, trak, meta) {
+			break
+		}
+	}
+	return nil
+}
+
+// readMP4VideoTrack 解析单个轨道，是视频轨道时写入尺寸、帧率与编码并返回 true
+func readMP4VideoTrack(f *os.File, trak bmffBox, meta *VideoMetadata) bool {
+	// 损坏的子盒子只解析到出错前的部分，一个盒子都没有读到时放弃该轨道
+	trakChildren, err := readBMFFChildren(f, trak, 0)
+	if err != nil && len(trakChildren) == 0 {
+		return false
+	}
+	mdia, ok := findBMFFBox(trakChildren, "mdia")
+	if !ok {
+		return false
+	}
+	mdiaChildren, err := readBMFFChildren(f, mdia, 0)
+	if err != nil && len(mdiaChildren) == 0 {
+		return false
+	}
+
+	hdlr, ok := findBMFFBox(mdiaChildren, "hdlr")
+	if !ok {
+		return false
+	}
+	hdlrData, err := readBMFFBody(f, hdlr, 1024)
+	if err != nil || len(hdlrData) < 12 || string(hdlrData[8:12]) != "vide" {
+		return false
+	}
+
+	if tkhd, ok := findBMFFBox(trakChildren, "tkhd"); ok {
+		if data, err := readBMFFBody(f, tkhd, 256); err == nil {
+			meta.Width, meta.Height = parseMP4TrackSize(data)
+		}
+	}
+
+	var timescale uint32
+	if mdhd, ok := findBMFFBox(mdiaChildren, "mdhd"); ok {
+		if data, err := readBMFFBody(f, mdhd, 256); err == nil {
+			_, timescale, _ = parseMP4TimeHeader(data)
+		}
+	}
+
+	minf, ok := findBMFFBox(mdiaChildren, "minf")
+	if !ok {
+		return true
+	}
+	minfChildren, err := readBMFFChildren(f, minf, 0)
+	if err != nil && len(minfChildren) == 0 {
+		return true
+	}
+	stbl, ok := findBMFFBox(minfChildren, "stbl")
+	if !ok {
+		return true
+	}
+	stblChildren, err := readBMFFChildren(f, stbl, 0)
+	if err != nil && len(stblChildren) == 0 {
+		return true
+	}
+
+	if stsd, ok := findBMFFBox(stblChildren, "stsd"); ok && stsd.size() >= 16 {
+		entry := make([]byte, 16)
+		if _, err := f.ReadAt(entry, stsd.body); err == nil {
+			meta.Codec = normalizeCodec(string(entry[12:16]))
+		}
+	}
+	if stts, ok := findBMFFBox(stblChildren, "stts"); ok && timescale > 0 {
+		if data, err := readBMFFBody(f, stts, maxMetadataBlock); err == nil {
+			meta.FrameRate = parseMP4FrameRate(data, timescale)
+		}
+	}
+	return true
+}
+
+// parseMP4TimeHeader 解析 mvhd/mdhd 的创建时间、时间刻度与时长（兼容版本 0/1）
+func parseMP4TimeHeader(data []byte) (created uint64, timescale uint32, duration uint64) {
+	if len(data) < 4 {
+		return 0, 0, 0
+	}
+	if data[0] == 1 {
+		if len(data) < 32 {
+			return 0, 0, 0
+		}
+		return binary.BigEndian.Uint64(data[4:12]), binary.BigEndian.Uint32(data[20:24]), binary.BigEndian.Uint64(data[24:32])
+	}
+	if len(data) < 20 {
+		return 0, 0, 0
+	}
+	return uint64(binary.BigEndian.Uint32(data[4:8])), binary.BigEndian.Uint32(data[12:16]), uint64(binary.BigEndian.Uint32(data[16:20]))
+}
+
+// parseMP4TrackSize 解析 tkhd 末尾 16.16 定点数表示的宽高
+func parseMP4TrackSize(data []byte) (width, height int) {
+	if len(data) < 8 {
+		return 0, 0
+	}
+	tail := data[len(data)-8:]
+	return int(binary.BigEndian.Uint32(tail[0:4]) >> 16), int(binary.BigEndian.Uint32(tail[4:8]) >> 16)
+}
+
+// parseMP4FrameRate 根据 stts 的样本数与总时长计算平均帧率
+func parseMP4FrameRate(data []byte, timescale uint32) float64 {
+	if len(data) < 8 {
+		return 0
+	}
+	count := int(binary.BigEndian.Uint32(data[4:8]))
+	var samples, total uint64
+	for i := 0; i < count && 8+i*8+8 <= len(data); i++ {
+		n := uint64(binary.BigEndian.Uint32(data[8+i*8:]))
+		delta := uint64(binary.BigEndian.Uint32(data[12+i*8:]))
+		samples += n
+		total += n * delta
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(samples) * float64(timescale) / float64(total)
+}
+
+// mp4Duration 将时间刻度单位的时长转换为 time.Duration
+func mp4Duration(duration uint64, timescale uint32) time.Duration {
+	if duration == math.MaxUint32 || duration == math.MaxUint64 {
+		return 0 // 未知时长
+	}
+	seconds := float64(duration) / float64(timescale)
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// ---------- Matroska / WebM ----------
+
+// EBML 元素 ID
+const (
+	ebmlSegment         = 0x18538067
+	ebmlSeekHead        = 0x114D9B74
+	ebmlSeek            = 0x4DBB
+	ebmlSeekID          = 0x53AB
+	ebmlSeekPosition    = 0x53AC
+	ebmlInfo            = 0x1549A966
+	ebmlTimecodeScale   = 0x2AD7B1
+	ebmlDuration        = 0x4489
+	ebmlDateUTC         = 0x4461
+	ebmlTracks          = 0x1654AE6B
+	ebmlTrackEntry      = 0xAE
+	ebmlTrackType       = 0x83
+	ebmlCodecID         = 0x86
+	ebmlDefaultDuration = 0x23E383
+	ebmlVideo           = 0xE0
+	ebmlPixelWidth      = 0xB0
+	ebmlPixelHeight     = 0xBA
+	ebmlCluster         = 0x1F43B675
+)
+
+// ebmlUnknownSize 未知长度元素（直播录制等场景）
+const ebmlUnknownSize = -1
+
+// ebmlElement EBML 元素的位置信息
+type ebmlElement struct {
+	id   uint32
+	data int64 // 数据起始偏移
+	size int64 // 数据长度，未知时为 ebmlUnknownSize
+}
+
+// readEBMLVarInt 读取 EBML 变长整数，keepMarker 为 true 时保留长度标记位（用于元素 ID）
+func readEBMLVarInt(r io.ReaderAt, off int64, maxLen int, keepMarker bool) (value uint64, length int, err error) {
+	var first [1]byte
+	if _, err := r.ReadAt(first[:], off); err != nil {
+		return 0, 0, err
+	}
+	length = 1
+	for mask := byte(0x80); length <= maxLen && first[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > maxLen {
+		return 0, 0, errors.New("invalid ebml varint")
+	}
+
+	buf := make([]byte, length)
+	if _, err := r.ReadAt(buf, off); err != nil {
+		return 0, 0, err
+	}
+	if !keepMarker {
+		buf[0] &= 0xFF >> length
+	}
+	allOnes := buf[0] == 0xFF>>length
+	for i, b := range buf {
+		value = value<<8 | uint64(b)
+		if i > 0 && b != 0xFF {
+			allOnes = false
+		}
+	}
+	if !keepMarker && allOnes {
+		return math.MaxUint64, length, nil
+	}
+	return value, length, nil
+}
+
+// readEBMLElement 读取 off 处的元素头
+func readEBMLElement(r io.ReaderAt, off int64) (ebmlElement, error) {
+	id, idLen, err := readEBMLVarInt(r, off, 4, true)
+	if err != nil {
+		return ebmlElement{}, err
+	}
+	size, sizeLen, err := readEBMLVarInt(r, off+int64(idLen), 8, false)
+	if err != nil {
+		return ebmlElement{}, err
+	}
+	el := ebmlElement{id: uint32(id), data: off + int64(idLen+sizeLen), size: int64(size)}
+	if size == math.MaxUint64 || size > math.MaxInt64/2 {
+		el.size = ebmlUnknownSize
+	}
+	return el, nil
+}
+
+// readEBMLChildren 读取 [start, end) 范围内的同级元素头，遇到 stop 中的元素时停止
+func readEBMLChildren(r io.ReaderAt, start, end int64, stop uint32) []ebmlElement {
+	var elements []ebmlElement
+	for off := start; off < end && len(elements) < maxBMFFBoxes; {
+		el, err := readEBMLElement(r, off)
+		if err != nil || el.id == stop {
+			break
+		}
+		elements = append(elements, el)
+		if el.size == ebmlUnknownSize || el.data+el.size > end {
+			break
+		}
+		off = el.data + el.size
+	}
+	return elements
+}
+
+// readEBMLBody 读取元素数据，超过 limit 时返回错误
+func readEBMLBody(r io.ReaderAt, el ebmlElement, limit int64) ([]byte, error) {
+	if el.size < 0 || el.size > limit {
+		return nil, errors.New("ebml element too large")
+	}
+	buf := make([]byte, el.size)
+	if n, err := r.ReadAt(buf, el.data); n < len(buf) {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// readMatroskaMetadata 定位 Segment 下的 Info 与 Tracks（必要时通过 SeekHead 跳转），不扫描 Cluster
+func readMatroskaMetadata(f *os.File, size int64, meta *VideoMetadata) error {
+	header, err := readEBMLElement(f, 0)
+	if err != nil || header.size < 0 {
+		return errUnsupportedVideo
+	}
+	segment, err := readEBMLElement(f, header.data+header.size)
+	if err != nil || segment.id != ebmlSegment {
+		return errors.New("matroska segment not found")
+	}
+	segmentEnd := size
+	if segment.size != ebmlUnknownSize && segment.data+segment.size < size {
+		segmentEnd = segment.data + segment.size
+	}
+
+	found := make(map[uint32]ebmlElement)
+	for _, el := range readEBMLChildren(f, segment.data, segmentEnd, ebmlCluster) {
+		if _, ok := found[el.id]; !ok {
+			found[el.id] = el
+		}
+	}
+
+	// Info 或 Tracks 位于 Cluster 之后时，通过 SeekHead 中记录的位置读取
+	if seekHead, ok := found[ebmlSeekHead]; ok {
+		if data, err := readEBMLBody(f, seekHead, maxMetadataBlock); err == nil {
+			for id, pos := range parseMatroskaSeekHead(data) {
+				if _, ok := found[id]; ok || (id != ebmlInfo && id != ebmlTracks) {
+					continue
+				}
+				if el, err := readEBMLElement(f, segment.data+pos); err == nil && el.id == id {
+					found[id] = el
+				}
+			}
+		}
+	}
+
+	if info, ok := found[ebmlInfo]; ok {
+		if data, err := readEBMLBody(f, info, maxMetadataBlock); err == nil {
+			parseMatroskaInfo(data, meta)
+		}
+	}
+	if tracks, ok := found[ebmlTracks]; ok {
+		if data, err := readEBMLBody(f, tracks, maxMetadataBlock); err == nil {
+			parseMatroskaTracks(data, meta)
+		}
+	}
+	return nil
+}
+
+// parseMatroskaSeekHead 解析 SeekHead，返回元素 ID 到 Segment 内偏移的映射
+func parseMatroskaSeekHead(data []byte) map[uint32]int64 {
+	r := bytes.NewReader(data)
+	positions := make(map[uint32]int64)
+	for _, seek := range readEBMLChildren(r, 0, int64(len(data)), 0) {
+		if seek.id != ebmlSeek || seek.size < 0 {
+			continue
+		}
+		var id uint32
+		var pos int64 = -1
+		for _, el := range readEBMLChildren(r, seek.data, seek.data+seek.size, 0) {
+			body := ebmlSlice(data, el)
+			switch el.id {
+			case ebmlSeekID:
+				id = uint32(ebmlUint(body))
+			case ebmlSeekPosition:
+				pos = int64(ebmlUint(body))
+			}
+		}
+		if id != 0 && pos >= 0 {
+			positions[id] = pos
+		}
+	}
+	return positions
+}
+
+// parseMatroskaInfo 解析 Info 中的时长与创建时间
+func parseMatroskaInfo(data []byte, meta *VideoMetadata) {
+	r := bytes.NewReader(data)
+	elements := readEBMLChildren(r, 0, int64(len(data)), 0)
+
+	timecodeScale := uint64(1000000)
+	for _, el := range elements {
+		if el.id == ebmlTimecodeScale {
+			if v := ebmlUint(ebmlSlice(data, el)); v > 0 {
+				timecodeScale = v
+			}
+		}
+	}
+	for _, el := range elements {
+		body := ebmlSlice(data, el)
+		switch el.id {
+		case ebmlDuration:
+			if d := ebmlFloat(body); d > 0 {
+				meta.Duration = time.Duration(d * float64(timecodeScale))
+			}
+		case ebmlDateUTC:
+			if len(body) == 8 {
+				ns := int64(binary.BigEndian.Uint64(body))
+				meta.Created = matroskaEpoch.Add(time.Duration(ns)).Local()
+			}
+		}
+	}
+}
+
+// parseMatroskaTracks 取第一个视频轨道的编码、分辨率与帧率
+func parseMatroskaTracks(data []byte, meta *VideoMetadata) {
+	r := bytes.NewReader(data)
+	for _, entry := range readEBMLChildren(r, 0, int64(len(data)), 0) {
+		if entry.id != ebmlTrackEntry || entry.size < 0 {
+			continue
+		}
+		var trackType uint64
+		var codec string
+		var defaultDuration uint64
+		var width, height int
+		for _, el := range readEBMLChildren(r, entry.data, entry.data+entry.size, 0) {
+			body := ebmlSlice(data, el)
+			switch el.id {
+			case ebmlTrackType:
+				trackType = ebmlUint(body)
+			case ebmlCodecID:
+				codec = strings.TrimRight(string(body), "\x00")
+			case ebmlDefaultDuration:
+				defaultDuration = ebmlUint(body)
+			case ebmlVideo:
+				if el.size < 0 {
+					continue
+				}
+				for _, v := range readEBMLChildren(r, el.data, el.data+el.size, 0) {
+					switch v.id {
+					case ebmlPixelWidth:
+						width = int(ebmlUint(ebmlSlice(data, v)))
+					case ebmlPixelHeight:
+						height = int(ebmlUint(ebmlSlice(data, v)))
+					}
+				}
+			}
+		}
+		if trackType != 1 { // 1 = 视频
+			continue
+		}
+		meta.Codec = normalizeCodec(codec)
+		meta.Width, meta.Height = width, height
+		if defaultDuration > 0 {
+			meta.FrameRate = 1e9 / float64(defaultDuration)
+		}
+		return
+	}
+}
+
+// ebmlSlice 返回元素在内存数据中的内容，越界时返回 nil
+func ebmlSlice(data []byte, el ebmlElement) []byte {
+	if el.size < 0 || el.data+el.size > int64(len(data)) {
+		return nil
+	}
+	return data[el.data : el.data+el.size]
+}
+
+// ebmlUint 解析大端无符号整数元素
+func ebmlUint(body []byte) uint64 {
+	var v uint64
+	for _, b := range body {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}
+
+// ebmlFloat 解析 4 或 8 字节浮点元素
+func ebmlFloat(body []byte) float64 {
+	switch len(body) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(body)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(body))
+	}
+	return 0
+}
+
+// ---------- 公共 ----------
+
+// normalizeCodec 将 MP4 四字符码和 Matroska CodecID 归一为常见编码名
+func normalizeCodec(codec string) string {
+	switch strings.TrimSpace(codec) {
+	case "avc1", "avc3", "V_MPEG4/ISO/AVC":
+		return "h264"
+	case "hvc1", "hev1", "V_MPEGH/ISO/HEVC":
+		return "hevc"
+	case "vp08", "V_VP8":
+		return "vp8"
+	case "vp09", "V_VP9":
+		return "vp9"
+	case "av01", "V_AV1":
+		return "av1"
+	case "mp4v", "V_MPEG4/ISO/SP", "V_MPEG4/ISO/ASP":
+		return "mpeg4"
+	case "apch", "apcn", "apcs", "apco", "ap4h", "V_PRORES":
+		return "prores"
+	}
+	return sanitizeNameComponent(strings.ToLower(strings.TrimSpace(codec)))
+}
@@ -0,0 +1,149 @@
+package pathgen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// mp4Created 测试文件的创建时间
+var mp4Created = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// mp4Track 生成轨道：handler 为 vide/soun，视频轨道带尺寸、编码与 stts
+func mp4Track(handler string, width, height uint32, codec string) []byte {
+	tkhd := fullBox("tkhd", 0, make([]byte, 72), be32(width<<16), be32(height<<16))
+	mdhd := fullBox("mdhd", 0, be32(0), be32(0), be32(30000), be32(300300), be32(0))
+	hdlr := fullBox("hdlr", 0, be32(0), []byte(handler), make([]byte, 13))
+	stsd := fullBox("stsd", 0, be32(1), be32(86), []byte(codec), make([]byte, 78))
+	stts := fullBox("stts", 0, be32(1), be32(300), be32(1001))
+	minf := box("minf", box("stbl", stsd, stts))
+	return box("trak", tkhd, box("mdia", mdhd, hdlr, minf))
+}
+
+// mp4Fixture 音频轨道在前、视频轨道在后的 MP4，时长 10 秒
+func mp4Fixture() []byte {
+	created := uint32(mp4Created.Sub(mp4Epoch) / time.Second)
+	mvhd := fullBox("mvhd", 0, be32(created), be32(created), be32(1000), be32(10000), make([]byte, 80))
+	moov := box("moov", mvhd, mp4Track("soun", 0, 0, "mp4a"), mp4Track("vide", 1920, 1080, "avc1"))
+	return bytes.Join([][]byte{box("ftyp", []byte("isom"), be32(0)), moov, box("mdat", make([]byte, 32))}, nil)
+}
+
+// ebml 生成 EBML 元素，长度统一使用 8 字节变长整数
+func ebml(id uint32, parts ...[]byte) []byte {
+	idBytes := bytes.TrimLeft(be32(id), "\x00")
+	body := bytes.Join(parts, nil)
+	size := binary.BigEndian.AppendUint64(nil, uint64(len(body)))
+	size[0] = 0x01
+	return bytes.Join([][]byte{idBytes, size, body}, nil)
+}
+
+func ebmlUintBytes(v uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, v)
+}
+
+// matroskaFixture VP9 视频轨道；infoAfterCluster 时 Info 位于 Cluster 之后，只能通过 SeekHead 找到
+func matroskaFixture(infoAfterCluster bool) []byte {
+	created := ebmlUintBytes(uint64(mp4Created.Sub(matroskaEpoch)))
+	info := ebml(ebmlInfo,
+		ebml(ebmlTimecodeScale, ebmlUintBytes(1000000)),
+		ebml(ebmlDuration, binary.BigEndian.AppendUint64(nil, math.Float64bits(10000))),
+		ebml(ebmlDateUTC, created),
+	)
+	tracks := ebml(ebmlTracks,
+		ebml(ebmlTrackEntry, ebml(ebmlTrackType, []byte{2}), ebml(ebmlCodecID, []byte("A_OPUS"))),
+		ebml(ebmlTrackEntry,
+			ebml(ebmlTrackType, []byte{1}),
+			ebml(ebmlCodecID, []byte("V_VP9")),
+			ebml(ebmlDefaultDuration, ebmlUintBytes(40000000)),
+			ebml(ebmlVideo, ebml(ebmlPixelWidth, be16(1280)), ebml(ebmlPixelHeight, be16(720))),
+		),
+	)
+	cluster := ebml(ebmlCluster, make([]byte, 16))
+
+	var children []byte
+	if infoAfterCluster {
+		seekHead := func(pos uint64) []byte {
+			return ebml(ebmlSeekHead, ebml(ebmlSeek, ebml(ebmlSeekID, be32(ebmlInfo)), ebml(ebmlSeekPosition, ebmlUintBytes(pos))))
+		}
+		pos := len(seekHead(0)) + len(tracks) + len(cluster)
+		children = bytes.Join([][]byte{seekHead(uint64(pos)), tracks, cluster, info}, nil)
+	} else {
+		children = bytes.Join([][]byte{info, tracks, cluster}, nil)
+	}
+	header := ebml(0x1A45DFA3, ebml(0x4282, []byte("webm")))
+	return append(header, ebml(ebmlSegment, children)...)
+}
+
+func TestReadVideoMetadata(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data []byte
+		want VideoMetadata
+	}{
+		{"mp4", "a.mp4", mp4Fixture(),
+			VideoMetadata{Created: mp4Created, Duration: 10 * time.Second, Width: 1920, Height: 1080, FrameRate: 30000.0 / 1001, Codec: "h264"}},
+		{"webm", "a.webm", matroskaFixture(false),
+			VideoMetadata{Created: mp4Created, Duration: 10 * time.Second, Width: 1280, Height: 720, FrameRate: 25, Codec: "vp9"}},
+		{"webm info after cluster", "b.webm", matroskaFixture(true),
+			VideoMetadata{Created: mp4Created, Duration: 10 * time.Second, Width: 1280, Height: 720, FrameRate: 25, Codec: "vp9"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadVideoMetadata(writeFixture(t, tt.file, tt.data))
+			if err != nil {
+				t.Fatalf("ReadVideoMetadata: %v", err)
+			}
+			if !got.Created.Equal(tt.want.Created) {
+				t.Errorf("Created = %v, want %v", got.Created, tt.want.Created)
+			}
+			if math.Abs(got.FrameRate-tt.want.FrameRate) > 0.001 {
+				t.Errorf("FrameRate = %v, want %v", got.FrameRate, tt.want.FrameRate)
+			}
+			got.Created, tt.want.Created = time.Time{}, time.Time{}
+			got.FrameRate, tt.want.FrameRate = 0, 0
+			if *got != tt.want {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadVideoMetadataMalformed(t *testing.T) {
+	// moov 中 64 位长度的 mvhd 只剩 12 字节，后面还有数据，盒子头读取成功但放不下
+	short64 := append(append(be32(1), "mvhd"...), be32(0)...)
+	truncated := mp4Fixture()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"empty", nil, true},
+		{"text", []byte("just some text, not video"), true},
+		{"mp4 without moov", box("ftyp", []byte("isom"), be32(0)), true},
+		{"malformed moov child", bytes.Join([][]byte{box("ftyp", []byte("isom"), be32(0)), box("moov", short64), box("free", be32(0xFFFFFFFF))}, nil), true},
+		{"truncated mp4", truncated[:len(truncated)-120], false},
+		{"matroska without segment", ebml(0x1A45DFA3, ebml(0x4282, []byte("webm"))), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadVideoMetadata(writeFixture(t, "x", tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func FuzzReadVideoMetadata(f *testing.F) {
+	f.Add(mp4Fixture())
+	f.Add(matroskaFixture(false))
+	f.Add(matroskaFixture(true))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// 只要求不崩溃：损坏的文件返回错误或部分元数据
+		ReadVideoMetadata(writeFixture(t, "fuzz", data))
+	})
+}
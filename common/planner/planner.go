@@ -0,0 +1,194 @@
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"rename-tool/common/filesort"
+	"rename-tool/common/pathgen"
+	"rename-tool/setting/model"
+)
+
+// Resolution 冲突处理结果
+type Resolution string
+
+const (
+	ResolutionNone      Resolution = ""          // 无冲突
+	ResolutionSkip      Resolution = "skip"      // 不重命名
+	ResolutionOverwrite Resolution = "overwrite" // 覆盖占用目标的文件
+	ResolutionSuffix    Resolution = "suffix"    // Target 已改为带后缀的名称
+	ResolutionDedupe    Resolution = "dedupe"    // 与占用目标的文件内容相同，删除源文件
+	ResolutionPrefix    Resolution = "prefix"    // Target 已加上相对路径前缀
+)
+
+// 复制或链接模式的错误
+var (
+	ErrOutputDir  = errors.New("output folder must be outside the selected folder")
+	ErrCopyFolder = errors.New("folders are not copied or linked, only files")
+)
+
+// 目标路径限制的错误：替换、插入等文本中的 / \ 或 .. 会把文件移出原文件夹
+var (
+	ErrTargetOutside = errors.New("new name leaves the selected folder")
+	ErrTargetMove    = errors.New("new name contains a folder, enable moving into folders to allow it")
+)
+
+// Entry 计划中的单个重命名项
+type Entry struct {
+	Source     string
+	Target     string
+	Err        error
+	Resolution Resolution // 冲突处理结果，预览与日志中显示
+	Conflict   string     // 与之冲突、占用目标名称的文件
+	Folder     bool       // 文件夹：名称不拆分扩展名，执行时先于所在的上级文件夹重命名
+}
+
+// Plan 重命名计划
+// 由文件列表和配置一次性生成，预览、冲突检测与执行共用同一份计划，
+// 保证预览结果与实际执行结果一致
+type Plan struct {
+	Config  model.RenameConfig
+	Entries []Entry
+}
+
+// Build 先按配置的排序依据排列文件，再按该顺序依次生成目标路径，构建重命名计划
+// 序号在这里一次性确定，与执行时的工作协程数量和完成顺序无关
+func Build(files []string, config model.RenameConfig) (*Plan, error) {
+	generator, err := pathgen.GetPathGenerator(config.Type)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOutputDir(config); err != nil {
+		return nil, err
+	}
+	if config.SortKey == model.SortManual {
+		files = filesort.Manual(files, config.SelectedDir, config.ManualOrder)
+	} else {
+		files = filesort.Sort(files, config.SortKey, config.SortDescending)
+	}
+
+	plan := &Plan{
+		Config:  config,
+		Entries: make([]Entry, 0, len(files)),
+	}
+	for _, file := range files {
+		itemConfig := config
+		itemConfig.Folder = config.Items != model.ItemFiles && isDir(file)
+		target, err := generator.GeneratePath(file, itemConfig)
+		if err == nil {
+			err = confine(file, target, itemConfig)
+		}
+		if err == nil && config.Mode != model.ExecRename {
+			target, err = outputPath(file, target, itemConfig)
+		}
+		plan.Entries = append(plan.Entries, Entry{Source: file, Target: target, Err: err, Folder: itemConfig.Folder})
+	}
+	return plan, nil
+}
+
+// TargetName 目标相对源所在文件夹的路径：原地重命名时为新名称，移入子文件夹时包含文件夹
+func TargetName(source, target string) string {
+	if rel, err := filepath.Rel(filepath.Dir(source), target); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return filepath.Base(target)
+}
+
+// confine 目标不能离开所选目录；未允许移动时还必须与源位于同一文件夹，
+// 新名称中的路径分隔符只在模板子文件夹或扁平化等明确允许移动时有效
+func confine(source, target string, config model.RenameConfig) error {
+	name, err := filepath.Rel(filepath.Dir(source), target)
+	if err != nil {
+		name = target
+	}
+	rel, err := filepath.Rel(config.SelectedDir, target)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%w: %s", ErrTargetOutside, name)
+	}
+	if filepath.Dir(target) == filepath.Dir(source) {
+		return nil
+	}
+	if !allowsMove(config) {
+		return fmt.Errorf("%w: %s", ErrTargetMove, name)
+	}
+	// 移入的文件夹可能是指向所选目录之外的符号链接，按实际位置再检查一次
+	if rel, err := filepath.Rel(resolveExisting(config.SelectedDir), resolveExisting(filepath.Dir(target))); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%w: %s", ErrTargetOutside, name)
+	}
+	return nil
+}
+
+// resolveExisting 解析路径中已存在部分的符号链接，尚未创建的文件夹原样接在后面
+func resolveExisting(path string) string {
+	rest := ""
+	for {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			return filepath.Join(resolved, rest)
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(path, rest)
+		}
+		rest = filepath.Join(filepath.Base(path), rest)
+		path = parent
+	}
+}
+
+// allowsMove 是否允许把文件移到其他文件夹：扁平化本身就是移动，其他类型需要明确开启
+func allowsMove(config model.RenameConfig) bool {
+	return config.AllowMove || config.Type == model.RenameTypeFlatten
+}
+
+// checkOutputDir 复制或链接模式的输出文件夹不能是所选目录或其中的子文件夹，否则副本会与源文件混在一起
+func checkOutputDir(config model.RenameConfig) error {
+	if config.Mode == model.ExecRename {
+		return nil
+	}
+	if config.OutputDir == "" {
+		return ErrOutputDir
+	}
+	// 位于其他盘符时无法求相对路径，肯定在所选目录之外
+	if rel, err := filepath.Rel(config.SelectedDir, config.OutputDir); err == nil && !strings.HasPrefix(rel, "..") {
+		return ErrOutputDir
+	}
+	return nil
+}
+
+// outputPath 复制或链接模式的目标：把新名称（包括模板中的子文件夹）放到输出文件夹中，
+// KeepStructure 时再加上源文件相对所选目录的子文件夹
+func outputPath(source, target string, config model.RenameConfig) (string, error) {
+	if config.Folder {
+		return source, ErrCopyFolder
+	}
+	dir := config.OutputDir
+	if config.KeepStructure {
+		if rel, err := filepath.Rel(config.SelectedDir, filepath.Dir(source)); err == nil && !strings.HasPrefix(rel, "..") {
+			dir = filepath.Join(dir, rel)
+		}
+	}
+	return filepath.Join(dir, TargetName(source, target)), nil
+}
+
+// DisplayTarget 预览中显示的目标：复制或链接模式下为相对输出文件夹的路径，否则同 TargetName
+func (p *Plan) DisplayTarget(entry Entry) string {
+	if p.Config.Mode != model.ExecRename {
+		if rel, err := filepath.Rel(p.Config.OutputDir, entry.Target); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return TargetName(entry.Source, entry.Target)
+}
+
+// isDir 判断路径是否为文件夹（不跟随符号链接）
+func isDir(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.IsDir()
+}
+
+// Len 返回计划中的文件数量
+func (p *Plan) Len() int {
+	return len(p.Entries)
+}
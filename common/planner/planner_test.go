@@ -0,0 +1,94 @@
+package planner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rename-tool/setting/model"
+)
+
+func TestConfine(t *testing.T) {
+	dir, outside := t.TempDir(), t.TempDir()
+	for _, sub := range []string{"sub", "sub/inner"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.FromSlash(sub)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "out")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "sub"), filepath.Join(dir, "alias")); err != nil {
+		t.Fatal(err)
+	}
+	path := func(rel string) string { return filepath.Join(dir, filepath.FromSlash(rel)) }
+
+	tests := []struct {
+		name   string
+		source string
+		target string
+		move   bool
+		typ    model.RenameType
+		want   error
+	}{
+		{"same folder", path("sub/a"), path("sub/b"), false, "", nil},
+		{"dot dot to the parent", path("sub/a"), path("sub/../a"), false, "", ErrTargetMove},
+		{"dot dot to the parent with moving allowed", path("sub/a"), path("sub/../a"), true, "", nil},
+		{"dot dot out of the selected folder", path("sub/a"), path("sub/../../a"), true, "", ErrTargetOutside},
+		{"the selected folder itself", path("a"), dir, true, "", ErrTargetOutside},
+		{"absolute path outside", path("a"), filepath.Join(outside, "a"), true, "", ErrTargetOutside},
+		{"folder move without moving allowed", path("sub"), path("inner2/sub"), false, "", ErrTargetMove},
+		{"folder move with moving allowed", path("sub"), path("inner2/sub"), true, "", nil},
+		{"flatten always moves", path("sub/inner/a"), path("a"), false, model.RenameTypeFlatten, nil},
+		{"symlinked parent outside", path("a"), path("out/a"), true, "", ErrTargetOutside},
+		{"new folder under a symlinked parent", path("a"), path("out/new/a"), true, "", ErrTargetOutside},
+		{"symlinked parent inside", path("a"), path("alias/inner/a"), true, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := model.RenameConfig{SelectedDir: dir, AllowMove: tt.move, Type: tt.typ}
+			if err := confine(tt.source, tt.target, config); !errors.Is(err, tt.want) {
+				t.Errorf("confine(%s, %s) = %v, want %v", tt.source, tt.target, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildConfinesTemplateOutput(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(file, []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		template string
+		move     bool
+		want     error
+		target   string // 没有错误时的目标，相对所选目录
+	}{
+		{"../{name}{ext}", true, ErrTargetOutside, ""},
+		{"x/../../{name}{ext}", true, ErrTargetOutside, ""},
+		{"x/../{name}_1{ext}", false, nil, "a_1.txt"},
+		// 模板中的绝对路径也接在源文件所在的文件夹下
+		{"/abs/{name}{ext}", false, ErrTargetMove, ""},
+		{"/abs/{name}{ext}", true, nil, "abs/a.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			config := model.RenameConfig{Type: model.RenameTypeTemplate, Template: tt.template, SelectedDir: dir, AllowMove: tt.move}
+			plan, err := Build([]string{file}, config)
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+			entry := plan.Entries[0]
+			if !errors.Is(entry.Err, tt.want) {
+				t.Errorf("err = %v, want %v", entry.Err, tt.want)
+			}
+			if tt.want == nil && entry.Target != filepath.Join(dir, filepath.FromSlash(tt.target)) {
+				t.Errorf("target = %s, want %s", entry.Target, tt.target)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package preflight
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"rename-tool/common/antisamename"
+	"rename-tool/common/filestatus"
+	"rename-tool/common/planner"
+	"rename-tool/setting/model"
+)
+
+// Problem 预检发现的问题，界面中以 "problem_"+Problem 为键显示
+type Problem string
+
+const (
+	ProblemTarget      Problem = "target"       // 目标名称无法生成
+	ProblemMissing     Problem = "missing"      // 源文件在扫描之后已被移走或删除
+	ProblemNoWrite     Problem = "no_write"     // 没有源或目标所在文件夹的写入权限
+	ProblemReadOnly    Problem = "read_only"    // 要删除或覆盖的文件是只读的
+	ProblemLocked      Problem = "locked"       // 文件被其他程序占用
+	ProblemTooLong     Problem = "too_long"     // 目标路径或其中的名称过长
+	ProblemInvalidName Problem = "invalid_name" // 目标名称包含文件系统不允许的字符或保留名称
+	ProblemConflict    Problem = "conflict"     // 未解决的重名冲突
+)
+
+// Issue 单个文件的一个问题
+type Issue struct {
+	Index   int // 计划项下标
+	Source  string
+	Target  string
+	Problem Problem
+	Detail  string // 相关的路径、名称或错误信息，可为空
+}
+
+// Check 在执行前逐个检查计划中将要处理的文件，返回按计划顺序排列的全部问题
+// 这些问题原本要到执行时才由重命名逐个报错，预检让用户在开始前就能排除它们
+func Check(plan *planner.Plan) []Issue {
+	issues, _ := check(plan, true)
+	return issues
+}
+
+// CheckDryRun 与 Check 相同，但不在磁盘上创建测试文件，用于试运行
+// 写入权限只能靠实际写入确认，返回的 unverified 为未经验证的文件夹
+func CheckDryRun(plan *planner.Plan) (issues []Issue, unverified []string) {
+	return check(plan, false)
+}
+
+// check probe 为 false 时跳过写入测试，记录涉及的文件夹
+func check(plan *planner.Plan, probe bool) ([]Issue, []string) {
+	var issues []Issue
+	add := func(i int, problem Problem, detail string) {
+		entry := plan.Entries[i]
+		issues = append(issues, Issue{Index: i, Source: entry.Source, Target: entry.Target, Problem: problem, Detail: detail})
+	}
+
+	rename := plan.Config.Mode == model.ExecRename
+	writable := make(map[string]bool) // 文件夹 -> 是否可写，同一文件夹只测试一次
+	var unverified []string
+	for i, entry := range plan.Entries {
+		if entry.Err != nil {
+			add(i, ProblemTarget, entry.Err.Error())
+			continue
+		}
+		if entry.Source == entry.Target || entry.Resolution == planner.ResolutionSkip {
+			continue
+		}
+		if _, err := os.Lstat(entry.Source); err != nil {
+			add(i, ProblemMissing, err.Error())
+			continue
+		}
+
+		// 目标文件夹可能还不存在，执行时在最近的已有上级中创建；原地重命名还要从源文件夹移走文件
+		dirs := []string{existingDir(filepath.Dir(entry.Target))}
+		if rename {
+			dirs = append(dirs, filepath.Dir(entry.Source))
+		}
+		for _, dir := range dirs {
+			ok, tested := writable[dir]
+			if !tested && !probe {
+				ok = true
+				writable[dir] = ok
+				unverified = append(unverified, dir)
+			} else if !tested {
+				ok = canWrite(dir)
+				writable[dir] = ok
+			}
+			if !ok {
+				add(i, ProblemNoWrite, dir)
+				break
+			}
+		}
+
+		if path := readOnlyFile(entry); path != "" {
+			add(i, ProblemReadOnly, path)
+		}
+		if rename && filestatus.IsLocked(entry.Source) {
+			add(i, ProblemLocked, entry.Source)
+		} else if entry.Resolution == planner.ResolutionOverwrite && filestatus.IsLocked(entry.Target) {
+			add(i, ProblemLocked, entry.Target)
+		}
+
+		// 去重时目标是已有的文件，名称不会改变
+		if entry.Resolution == planner.ResolutionDedupe {
+			continue
+		}
+		name := plan.DisplayTarget(entry)
+		if detail := checkLength(entry.Target, name); detail != "" {
+			add(i, ProblemTooLong, detail)
+		}
+		if detail := checkName(name); detail != "" {
+			add(i, ProblemInvalidName, detail)
+		}
+	}
+
+	for _, c := range antisamename.FindConflicts(plan) {
+		add(c.Index, ProblemConflict, c.Occupant)
+	}
+	sort.SliceStable(issues, func(a, b int) bool { return issues[a].Index < issues[b].Index })
+	sort.Strings(unverified)
+	return issues, unverified
+}
+
+// Exclude 从计划中移除有问题的文件，其余文件照常执行
+// 移除的文件保留原名，可能使其他文件产生新的冲突，调用方应再次 Check
+func Exclude(plan *planner.Plan, issues []Issue) {
+	excluded := make(map[int]bool, len(issues))
+	for _, issue := range issues {
+		excluded[issue.Index] = true
+	}
+	kept := make([]planner.Entry, 0, len(plan.Entries))
+	for i, entry := range plan.Entries {
+		if !excluded[i] {
+			kept = append(kept, entry)
+		}
+	}
+	plan.Entries = kept
+}
+
+// Files 返回有问题的文件数量（一个文件可能有多个问题）
+func Files(issues []Issue) int {
+	files := make(map[int]bool, len(issues))
+	for _, issue := range issues {
+		files[issue.Index] = true
+	}
+	return len(files)
+}
@@ -0,0 +1,7 @@
+package preflight
+
+import "rename-tool/setting/i18n"
+
+func dialogTr(key string) string {
+	return i18n.DialogTr(key)
+}
@@ -0,0 +1,114 @@
+package preflight
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+
+	"rename-tool/common/planner"
+)
+
+const (
+	maxPath = 259 // Windows MAX_PATH 去掉结尾的空字符，资源管理器等程序打不开更长的路径
+	maxName = 255 // NTFS、exFAT 及常见 Linux 文件系统中单个名称的上限
+)
+
+// invalidChars Windows 文件名中不允许的字符
+const invalidChars = `<>:"|?*\/`
+
+// reservedNames Windows 保留的设备名，带扩展名时同样不可用
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// existingDir 返回 dir 或其最近的已存在上级文件夹
+func existingDir(dir string) string {
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// canWrite 在文件夹中创建并删除一个临时文件，测试实际的写入权限（包括 ACL 和只读介质）
+func canWrite(dir string) bool {
+	f, err := os.CreateTemp(dir, ".renamer-preflight-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
+
+// readOnlyFile 返回执行时要删除或替换的只读文件：去重删除源文件，覆盖替换目标文件
+// 只读属性不妨碍重命名本身，只会使删除和替换失败
+func readOnlyFile(entry planner.Entry) string {
+	var path string
+	switch entry.Resolution {
+	case planner.ResolutionDedupe:
+		path = entry.Source
+	case planner.ResolutionOverwrite:
+		path = entry.Target
+	default:
+		return ""
+	}
+	if info, err := os.Lstat(path); err == nil && info.Mode().Perm()&0200 == 0 {
+		return path
+	}
+	return ""
+}
+
+// checkLength 检查完整目标路径与新名称中的每一级，过长时返回说明
+func checkLength(target, name string) string {
+	if n := len(utf16.Encode([]rune(target))); n > maxPath {
+		return fmt.Sprintf("%d > %d", n, maxPath)
+	}
+	for _, part := range splitName(name) {
+		if n := max(len(utf16.Encode([]rune(part))), len(part)); n > maxName {
+			return fmt.Sprintf("%s: %d > %d", part, n, maxName)
+		}
+	}
+	return ""
+}
+
+// checkName 按 Windows 的规则检查新名称中的每一级，返回第一个不可用的名称
+// 目标可能位于 NTFS、exFAT 或网络共享上，因此在所有平台上使用最严格的规则
+func checkName(name string) string {
+	for _, part := range splitName(name) {
+		if !validName(part) {
+			return part
+		}
+	}
+	return ""
+}
+
+// validName 不含非法字符和控制字符、不以点或空格结尾，且不是保留的设备名
+func validName(name string) bool {
+	if strings.ContainsAny(name, invalidChars) || strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+		return false
+	}
+	for _, r := range name {
+		if r < 0x20 {
+			return false
+		}
+	}
+	stem, _, _ := strings.Cut(name, ".")
+	return !reservedNames[strings.ToUpper(strings.TrimRight(stem, " "))]
+}
+
+// splitName 按当前平台的路径分隔符拆分相对路径
+func splitName(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return r < 0x80 && os.IsPathSeparator(uint8(r))
+	})
+}
@@ -0,0 +1,65 @@
+package preflight
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"rename-tool/common/planner"
+)
+
+// Confirm 执行前预检计划：没有问题时直接调用 onReady；
+// 有问题时列出每个文件的问题，用户可以排除这些文件后继续执行其余文件，取消时不调用 onReady
+func Confirm(window fyne.Window, plan *planner.Plan, onReady func()) {
+	issues := Check(plan)
+	if len(issues) == 0 {
+		onReady()
+		return
+	}
+
+	lines := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		lines = append(lines, fmt.Sprintf("%s\n  └─ %s", issue.Source, describe(issue)))
+	}
+	content := strings.Join(lines, "\n\n")
+
+	label := widget.NewLabel(content)
+	label.Wrapping = fyne.TextWrapWord
+	scroll := container.NewVScroll(label)
+	scroll.SetMinSize(fyne.NewSize(520, 240))
+
+	var d dialog.Dialog
+	copyBtn := widget.NewButton(dialogTr("copy"), func() {
+		window.Clipboard().SetContent(content)
+	})
+	excludeBtn := widget.NewButton(dialogTr("preflightExclude"), func() {
+		d.Hide()
+		// 排除的文件保留原名，可能使其他文件产生新的冲突，重新预检
+		Exclude(plan, issues)
+		Confirm(window, plan, onReady)
+	})
+	files := Files(issues)
+	if files == plan.Len() {
+		excludeBtn.Disable() // 没有剩余的文件可执行
+	}
+	cancelBtn := widget.NewButton(dialogTr("cancel"), func() { d.Hide() })
+
+	buttons := container.NewHBox(layout.NewSpacer(), copyBtn, excludeBtn, cancelBtn, layout.NewSpacer())
+	title := fmt.Sprintf(dialogTr("preflightTitle"), files, plan.Len())
+	d = dialog.NewCustomWithoutButtons(title, container.NewVBox(scroll, buttons), window)
+	d.Show()
+}
+
+// describe 问题的说明文字：翻译后的问题类型加上相关的路径或名称
+func describe(issue Issue) string {
+	text := dialogTr("problem_" + string(issue.Problem))
+	if issue.Detail != "" {
+		text += ": " + issue.Detail
+	}
+	return text
+}
@@ -1,16 +1,23 @@
 package preview
 
 import (
-	"rename-tool/setting/model"
+	"rename-tool/common/planner"
 
 	"fyne.io/fyne/v2"
 )
 
-// ShowPreviewWindow 显示预览窗口
-func ShowPreviewWindow(parentWindow fyne.Window, files []string, config model.RenameConfig) {
+// Options 预览窗口选项
+type Options struct {
+	Recursive    bool                 // 是否包含子目录，保存任务时写入
+	OnApplyOrder func(order []string) // 把调整后的顺序用于重命名界面，为 nil 时不显示该按钮
+}
+
+// ShowPreviewWindow 显示预览窗口，可拖动或上下移动文件调整编号顺序
+func ShowPreviewWindow(parentWindow fyne.Window, plan *planner.Plan, opts Options) {
 	previewWindow := createPreviewWindow()
-	previewList := createPreviewList(files, config)
-	content := buildWindowContent(previewList, len(files), previewWindow)
+	state := newOrderState(plan)
+	previewList := createPreviewList(state, previewWindow)
+	content := buildWindowContent(previewList, state, previewWindow, opts)
 
 	previewWindow.SetContent(content)
 	previewWindow.Show()
@@ -10,3 +10,7 @@ func buttonTr(key string) string {
 func dialogTr(key string) string {
 	return i18n.DialogTr(key)
 }
+
+func textTr(key string) string {
+	return i18n.TextTr(key)
+}
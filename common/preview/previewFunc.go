@@ -3,13 +3,17 @@ package preview
 import (
 	"fmt"
 	"path/filepath"
-	"rename-tool/common/pathgen"
+	"rename-tool/common/jobfile"
+	"rename-tool/common/planner"
 	"rename-tool/setting/global"
 	"rename-tool/setting/model"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -24,72 +28,132 @@ func createPreviewWindow() fyne.Window {
 	return window
 }
 
-// createPreviewList 创建预览列表
-func createPreviewList(files []string, config model.RenameConfig) *widget.List {
-	return widget.NewList(
-		func() int { return len(files) },
-		func() fyne.CanvasObject { return widget.NewLabel("") },
+// createPreviewList 创建可调整顺序的预览列表，未解决的冲突单独标出
+// 拖动行首手柄或使用底部按钮移动选中的文件
+func createPreviewList(state *orderState, window fyne.Window) *widget.List {
+	var list *widget.List
+	onMove := func(from, to int) {
+		if err := state.move(from, to); err != nil {
+			dialog.ShowError(err, window)
+		}
+	}
+	newRow := func() *fyne.Container {
+		return container.NewBorder(nil, nil, newDragHandle(onMove), nil, widget.NewLabel(""))
+	}
+	// 列表中相邻两行的间距：行高加分隔线
+	rowHeight := newRow().MinSize().Height + theme.Padding()
+
+	list = widget.NewList(
+		func() int { return state.plan.Len() },
+		func() fyne.CanvasObject {
+			row := newRow()
+			row.Objects[1].(*dragHandle).rowHeight = rowHeight
+			return row
+		},
 		func(id widget.ListItemID, obj fyne.CanvasObject) {
-			displayPreviewItem(obj.(*widget.Label), files[id], config, id)
+			row := obj.(*fyne.Container)
+			row.Objects[1].(*dragHandle).row = id
+			displayPreviewItem(row.Objects[0].(*widget.Label), state.plan, state.plan.Entries[id], state.conflicts[id])
 		},
 	)
+	list.OnSelected = func(id widget.ListItemID) { state.selected = id }
+	list.OnUnselected = func(widget.ListItemID) { state.selected = -1 }
+	state.list = list
+	return list
 }
 
-// displayPreviewItem 显示单个预览项
-func displayPreviewItem(label *widget.Label, file string, config model.RenameConfig, id int) {
-	_, oldName := filepath.Split(file)
-	newPath, err := generateNewPath(file, config, id)
+// displayPreviewItem 显示单个预览项及冲突处理结果
+func displayPreviewItem(label *widget.Label, plan *planner.Plan, entry planner.Entry, conflict bool) {
+	_, oldName := filepath.Split(entry.Source)
 
-	if err != nil {
-		label.SetText(fmt.Sprintf("%s → %s", oldName, err.Error()))
+	if entry.Err != nil {
+		label.SetText(fmt.Sprintf("%s → %s", oldName, entry.Err.Error()))
 		return
 	}
 
-	_, newName := filepath.Split(newPath)
-	label.SetText(fmt.Sprintf("%s → %s", oldName, newName))
-}
-
-// generateNewPath 根据配置生成新路径
-func generateNewPath(file string, config model.RenameConfig, id int) (string, error) {
-	counters := make(map[string]int)
-
-	switch config.Type {
-	case model.RenameTypeBatch:
-		return pathgen.GenerateBatchRenamePath(file, config, id, counters)
-	case model.RenameTypeExtension:
-		return pathgen.GenerateExtensionRenamePath(file, config)
-	case model.RenameTypeCase:
-		return pathgen.GenerateCaseRenamePath(file, config)
-	case model.RenameTypeInsertChar:
-		return pathgen.GenerateInsertCharRenamePath(file, config)
-	case model.RenameTypeReplace:
-		return pathgen.GenerateReplaceRenamePath(file, config)
-	case model.RenameTypeDeleteChar:
-		return pathgen.GenerateDeleteCharRenamePath(file, config)
-	default:
-		return "", fmt.Errorf("unknown rename type")
+	text := fmt.Sprintf("%s → %s", oldName, plan.DisplayTarget(entry))
+	switch {
+	case conflict:
+		text += "  [" + textTr("unresolvedConflict") + "]"
+	case entry.Resolution != planner.ResolutionNone:
+		text += "  [" + textTr("resolution_"+string(entry.Resolution)) + "]"
 	}
+	label.SetText(text)
 }
 
 // buildWindowContent 构建窗口内容
-func buildWindowContent(previewList *widget.List, fileCount int, window fyne.Window) *fyne.Container {
-	topBar := createTopBar(fileCount)
-	bottomBar := createBottomBar(window)
+func buildWindowContent(previewList *widget.List, state *orderState, window fyne.Window, opts Options) *fyne.Container {
+	topBar := createTopBar(state.plan)
+	bottomBar := createBottomBar(state, window, opts)
 
 	return container.NewBorder(topBar, bottomBar, nil, nil, previewList)
 }
 
-// createTopBar 创建顶部栏
-func createTopBar(fileCount int) *fyne.Container {
+// createTopBar 创建顶部栏；复制模式下显示输出文件夹
+func createTopBar(plan *planner.Plan) *fyne.Container {
 	title := widget.NewLabelWithStyle(buttonTr("preview"), fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
-	countLabel := widget.NewLabel(fmt.Sprintf(dialogTr("totalFiles")+": %d", fileCount))
-	return container.NewHBox(title, layout.NewSpacer(), countLabel)
+	countLabel := widget.NewLabel(fmt.Sprintf(dialogTr("totalFiles")+": %d", plan.Len()))
+	bar := container.NewHBox(title, layout.NewSpacer())
+	if plan.Config.Mode != model.ExecRename {
+		bar.Add(widget.NewLabel(fmt.Sprintf(textTr("mode_"+string(plan.Config.Mode)), plan.Config.OutputDir)))
+	}
+	bar.Add(countLabel)
+	return bar
 }
 
-// createBottomBar 创建底部栏
-func createBottomBar(window fyne.Window) *fyne.Container {
+// createBottomBar 创建底部栏：移动选中文件、保存任务、应用顺序
+func createBottomBar(state *orderState, window fyne.Window, opts Options) *fyne.Container {
+	moveBy := func(offset int) func() {
+		return func() {
+			if err := state.moveSelected(offset); err != nil {
+				dialog.ShowError(err, window)
+			}
+		}
+	}
+	n := state.plan.Len()
+	topBtn := widget.NewButton(buttonTr("moveTop"), moveBy(-n))
+	upBtn := widget.NewButtonWithIcon(buttonTr("moveUp"), theme.MoveUpIcon(), moveBy(-1))
+	downBtn := widget.NewButtonWithIcon(buttonTr("moveDown"), theme.MoveDownIcon(), moveBy(1))
+	bottomBtn := widget.NewButton(buttonTr("moveBottom"), moveBy(n))
+
+	saveBtn := widget.NewButton(buttonTr("saveJob"), func() {
+		saveJob(window, jobfile.New(manualConfig(state.plan.Config, state.sources()), opts.Recursive))
+	})
 	closeBtn := widget.NewButton(dialogTr("confirm"), func() {
 		window.Close()
 	})
-	return container.NewHBox(layout.NewSpacer(), closeBtn)
+
+	bar := container.NewHBox(topBtn, upBtn, downBtn, bottomBtn, layout.NewSpacer(), saveBtn)
+	if opts.OnApplyOrder != nil {
+		applyBtn := widget.NewButton(buttonTr("applyOrder"), func() {
+			opts.OnApplyOrder(state.order())
+			window.Close()
+		})
+		bar.Add(applyBtn)
+	}
+	bar.Add(closeBtn)
+	return bar
+}
+
+// saveJob 选择保存位置并写入任务文件
+func saveJob(window fyne.Window, job jobfile.Job) {
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		if writer == nil {
+			return // 取消
+		}
+		path := writer.URI().Path()
+		writer.Close()
+		if err := jobfile.Save(path, job); err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		dialog.ShowInformation(buttonTr("saveJob"), fmt.Sprintf(dialogTr("jobSaved"), path), window)
+	}, window)
+	save.SetFileName("rename" + jobfile.Extension)
+	save.SetFilter(storage.NewExtensionFileFilter([]string{jobfile.Extension}))
+	save.Show()
 }
@@ -0,0 +1,134 @@
+package preview
+
+import (
+	"math"
+
+	"rename-tool/common/antisamename"
+	"rename-tool/common/filesort"
+	"rename-tool/common/planner"
+	"rename-tool/setting/model"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// orderState 预览中可调整顺序的计划
+// 每次移动后按新顺序重新生成计划，序号与冲突处理结果随之更新
+type orderState struct {
+	plan      *planner.Plan
+	conflicts map[int]bool
+	selected  int // 选中的行，-1 表示未选中
+	list      *widget.List
+}
+
+func newOrderState(plan *planner.Plan) *orderState {
+	s := &orderState{plan: plan, selected: -1}
+	s.markConflicts()
+	return s
+}
+
+// markConflicts 标出未解决的冲突
+func (s *orderState) markConflicts() {
+	s.conflicts = make(map[int]bool)
+	for _, c := range antisamename.FindConflicts(s.plan) {
+		s.conflicts[c.Index] = true
+	}
+}
+
+// sources 当前顺序的源文件
+func (s *orderState) sources() []string {
+	sources := make([]string, len(s.plan.Entries))
+	for i, entry := range s.plan.Entries {
+		sources[i] = entry.Source
+	}
+	return sources
+}
+
+// order 当前顺序，保存为相对所选目录的路径
+func (s *orderState) order() []string {
+	return manualConfig(s.plan.Config, s.sources()).ManualOrder
+}
+
+// manualConfig 按 sources 的顺序手动排序的配置
+func manualConfig(config model.RenameConfig, sources []string) model.RenameConfig {
+	config.SortKey = model.SortManual
+	config.SortDescending = false
+	config.ManualOrder = make([]string, len(sources))
+	for i, source := range sources {
+		config.ManualOrder[i] = filesort.RelPath(config.SelectedDir, source)
+	}
+	return config
+}
+
+// move 把 from 行移到 to 行，并按新顺序重新生成计划
+func (s *orderState) move(from, to int) error {
+	n := len(s.plan.Entries)
+	if from < 0 || from >= n {
+		return nil
+	}
+	to = max(0, min(to, n-1))
+	if from == to {
+		return nil
+	}
+
+	sources := s.sources()
+	moved := sources[from]
+	sources = append(sources[:from], sources[from+1:]...)
+	sources = append(sources[:to], append([]string{moved}, sources[to:]...)...)
+
+	plan, err := planner.Build(sources, manualConfig(s.plan.Config, sources))
+	if err != nil {
+		return err
+	}
+	if err := antisamename.Resolve(plan); err != nil {
+		return err
+	}
+	s.plan = plan
+	s.markConflicts()
+
+	s.list.Refresh()
+	s.list.Select(to)
+	return nil
+}
+
+// moveSelected 按偏移量移动选中的行
+func (s *orderState) moveSelected(offset int) error {
+	if s.selected < 0 {
+		return nil
+	}
+	return s.move(s.selected, s.selected+offset)
+}
+
+// dragHandle 行首的拖动手柄，松开时按拖动距离移动所在的行
+type dragHandle struct {
+	widget.Icon
+	row       int
+	dy        float32
+	rowHeight float32
+	onMove    func(from, to int)
+}
+
+func newDragHandle(onMove func(from, to int)) *dragHandle {
+	h := &dragHandle{onMove: onMove}
+	h.ExtendBaseWidget(h)
+	h.SetResource(theme.MenuIcon())
+	return h
+}
+
+// Dragged 累计纵向拖动距离
+func (h *dragHandle) Dragged(e *fyne.DragEvent) {
+	h.dy += e.Dragged.DY
+}
+
+// DragEnd 拖动结束，按行高换算移动的行数
+func (h *dragHandle) DragEnd() {
+	rows := 0
+	if h.rowHeight > 0 {
+		rows = int(math.Round(float64(h.dy / h.rowHeight)))
+	}
+	h.dy = 0
+	if rows != 0 {
+		h.onMove(h.row, h.row+rows)
+	}
+}
@@ -17,6 +17,8 @@ type Dialog struct {
 	status    *widget.Label
 	cancelBtn *widget.Button
 	cancelled bool
+	closed    bool   // Hide 后不再接受更新
+	onCancel  func() // 点击取消时调用，用于停止后台任务
 	mu        sync.RWMutex
 	updateCh  chan struct {
 		progress float64
@@ -63,15 +65,21 @@ func NewDialog(title string, window fyne.Window) *Dialog {
 	cancelBtn.OnTapped = func() {
 		pd.mu.Lock()
 		pd.cancelled = true
+		onCancel := pd.onCancel
 		pd.mu.Unlock()
+		if onCancel != nil {
+			onCancel()
+		}
 		pd.dialog.Hide()
 	}
 
-	// 启动更新处理协程
+	// 启动更新处理协程，控件在界面线程中更新
 	go func() {
 		for update := range pd.updateCh {
-			progress.SetValue(update.progress)
-			status.SetText(update.status)
+			fyne.Do(func() {
+				progress.SetValue(update.progress)
+				status.SetText(update.status)
+			})
 		}
 	}()
 
@@ -86,11 +94,28 @@ func (pd *Dialog) Show() {
 // Hide 隐藏对话框
 func (pd *Dialog) Hide() {
 	pd.dialog.Hide()
-	close(pd.updateCh) // 关闭更新通道
+	pd.mu.Lock()
+	defer pd.mu.Unlock()
+	if !pd.closed {
+		pd.closed = true
+		close(pd.updateCh) // 关闭更新通道
+	}
+}
+
+// SetOnCancel 设置点击取消时的回调
+func (pd *Dialog) SetOnCancel(f func()) {
+	pd.mu.Lock()
+	pd.onCancel = f
+	pd.mu.Unlock()
 }
 
-// Update 更新进度和状态
+// Update 更新进度和状态，可在任意协程调用；对话框关闭后忽略
 func (pd *Dialog) Update(progress float64, status string) {
+	pd.mu.RLock()
+	defer pd.mu.RUnlock()
+	if pd.closed {
+		return
+	}
 	select {
 	case pd.updateCh <- struct {
 		progress float64
@@ -4,9 +4,9 @@ import (
 	"os"
 	"path/filepath"
 	"rename-tool/common/applog"
+	"rename-tool/common/filesort"
 	"rename-tool/common/filestatus"
 	"rename-tool/setting/i18n"
-	"sort"
 	"strings"
 )
 
@@ -31,7 +31,7 @@ func ScanFormats(dir string) ([]string, error) {
 	for ext := range formatMap {
 		formats = append(formats, ext)
 	}
-	sort.Strings(formats)
+	filesort.Strings(formats)
 	return formats, nil
 }
 
@@ -73,6 +73,6 @@ func ScanFormatsWalk(dir string) ([]string, error) {
 	for ext := range formatMap {
 		formats = append(formats, ext)
 	}
-	sort.Strings(formats)
+	filesort.Strings(formats)
 	return formats, nil
 }
@@ -2,16 +2,26 @@ package main
 
 //power by Tvacats
 import (
+	"os"
+
 	"rename-tool/common/appinit"
 	"rename-tool/common/applog"
+	"rename-tool/common/cli"
 	"rename-tool/common/menu"
 	"rename-tool/common/theme"
 	"rename-tool/setting/global"
 	"rename-tool/setting/i18n"
+	"rename-tool/utils"
 )
 
 func main() {
 
+	// Run headless when started with a subcommand, e.g. `renamer batch --dry-run D:\photos`
+	if cli.IsCommand(os.Args[1:]) {
+		cli.Main(os.Args[1:])
+		return
+	}
+
 	// Initialize application with default configuration
 	if err := appinit.InitializeApp(appinit.DefaultConfig()); err != nil {
 		applog.Logger.Printf("[INIT ERROR]  %s %v", i18n.LogTr("initAppError"), err)
@@ -21,6 +31,9 @@ func main() {
 	// Show main menu
 	menu.ShowMainMenu()
 
+	// Report rename batches that were interrupted by a crash or forced close
+	utils.CheckInterruptedRenames()
+
 	// Run application
 	appinit.RunApp()
 }
@@ -3,7 +3,8 @@ package config
 import "time"
 
 const (
-	LogDir = "logs"
+	LogDir      = "logs"
+	JournalFile = "journal.jsonl" // 重命名日志（JSON Lines），位于 LogDir 下
 
 	// 文件操作相关常量
 	MaxRetryAttempts = 3
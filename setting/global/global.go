@@ -5,9 +5,10 @@ import (
 )
 
 type RenameLog struct {
-	Original string
-	New      string
-	Time     string
+	Original   string
+	New        string
+	Time       string
+	Resolution string // 冲突处理结果，为空表示普通重命名
 }
 
 var (
@@ -34,67 +34,178 @@ var log_translations = map[string]map[string]string{
 }
 var dialog_translations = map[string]map[string]string{
 	"zh": {
-		"success":            "✅ 成功",
-		"warning":            "⚠️ 警告",
-		"error":              "❌ 错误",
-		"confirm":            "确认",
-		"successSavedTo":     "个成功保存到",
-		"noLogSaved":         "没有更改记录,日志为空",
-		"selectFormat":       "请选择要修改的扩展名",
-		"selectDirFirst":     "请选择目录",
-		"copy":               "复制",
-		"copySuccess":        "复制成功",
-		"noUndoOperations":   "没有可撤销的操作",
-		"undoSuccess":        "成功撤销重命名 %d 个文件",
-		"renameSuccess":      "重命名成功",
-		"duplicateNames":     "以下文件将重命名为相同的名称",
-		"failGetFiles":       "获取文件列表失败",
-		"operationCancelled": "操作已取消",
-		"successRenameCount": "重命名 %d 个文件",
-		"totalFiles":         "修改文件总数",
-		"logSaveError":       "日志保存失败",
+		"success":              "✅ 成功",
+		"warning":              "⚠️ 警告",
+		"error":                "❌ 错误",
+		"confirm":              "确认",
+		"successSavedTo":       "个成功保存到",
+		"noLogSaved":           "没有更改记录,日志为空",
+		"selectFormat":         "请选择要修改的扩展名",
+		"selectDirFirst":       "请选择目录",
+		"copy":                 "复制",
+		"copySuccess":          "复制成功",
+		"noUndoOperations":     "没有可撤销的操作",
+		"undoSuccess":          "成功撤销重命名 %d 个文件",
+		"renameSuccess":        "重命名成功",
+		"duplicateNames":       "以下文件将重命名为相同的名称",
+		"failGetFiles":         "获取文件列表失败",
+		"operationCancelled":   "操作已取消",
+		"successRenameCount":   "重命名 %d 个文件",
+		"totalFiles":           "修改文件总数",
+		"logSaveError":         "日志保存失败",
+		"journalReadError":     "读取重命名日志失败：%v",
+		"interruptedRename":    "上次有 %d 个批量重命名未正常结束：%d 个文件已重命名，%d 个未完成。可使用“撤销”恢复已重命名的文件。",
+		"cancel":               "取消",
+		"redoSuccess":          "成功重做重命名 %d 个文件",
+		"undoFailedCount":      "%d 个文件未能处理，原因已在列表中标出",
+		"conflictTitle":        "目标名称冲突",
+		"conflictMessage":      "%s 将重命名为 %s，但该名称已被占用。\n\n要重命名的文件：%s\n已占用的文件：%s",
+		"conflictApplyAll":     "对其余冲突执行相同操作",
+		"conflict_skip":        "跳过",
+		"conflict_overwrite":   "覆盖",
+		"conflict_suffix":      "自动加后缀",
+		"conflict_newer":       "保留较新的",
+		"conflict_dedupe":      "内容相同则删除重复",
+		"rollbackComplete":     "重命名失败，已回滚本批次 %d 个已完成的重命名，文件夹保持原状",
+		"rollbackIncomplete":   "重命名失败，回滚未完全成功：%d 个文件未能恢复原名",
+		"rollbackFailed":       "未能恢复原名",
+		"interruptedTitle":     "批量重命名未完成",
+		"interruptedBatch":     "%s 开始的批量重命名（%s）未正常结束：%d 个文件已重命名，%d 个尚未完成。\n可以继续执行剩余的重命名，或将已完成的文件恢复原名。",
+		"renameProgress":       "%d / %d  %s\n%.1f 个/秒，剩余约 %s",
+		"cancelledReport":      "已取消：%d 个文件已重命名，%d 个文件未重命名",
+		"jobSaved":             "任务已保存：%s",
+		"runJobConfirm":        "按任务重命名目录 %s 中的文件？（手动顺序 %d 个文件）",
+		"conflict_prefix":      "加上级文件夹前缀",
+		"selectOutputDirFirst": "请先选择输出文件夹",
+		"copyProgress":         "正在复制到其他磁盘：%.1f / %.1f MB",
+		"preflightTitle":       "%d/%d 个文件未通过执行前检查",
+		"preflightExclude":     "排除这些文件并继续",
+		"problem_target":       "无法生成新名称",
+		"problem_missing":      "扫描后源文件已不存在",
+		"problem_no_write":     "没有文件夹的写入权限",
+		"problem_read_only":    "文件为只读，无法删除或覆盖",
+		"problem_locked":       "文件被其他程序占用",
+		"problem_too_long":     "目标路径或名称过长",
+		"problem_invalid_name": "目标名称包含不允许的字符或保留名称",
+		"problem_conflict":     "与已有文件重名",
+		"skippedCount":         "，%d 个文件未改变或已跳过",
+		"rolledBackCount":      "，%d 个文件已回滚恢复原名",
 	},
 	"en": {
-		"success":            "✅ SUCCESS",
-		"warning":            "⚠️ WARNING",
-		"error":              "❌ ERROR",
-		"confirm":            "confirm",
-		"successSavedTo":     "successfully saved to ",
-		"noLogSaved":         "No change record, log is empty",
-		"selectFormat":       "Please select extension to modify",
-		"selectDirFirst":     "Please select a directory",
-		"copy":               "Copy",
-		"copySuccess":        "Copied successfully",
-		"noUndoOperations":   "No operations to undo",
-		"undoSuccess":        "Successfully undone renaming %d files",
-		"renameSuccess":      "Rename Successful",
-		"duplicateNames":     "The following files will be renamed to the same name",
-		"failGetFiles":       "Failed to get file list",
-		"operationCancelled": "Operation Cancelled",
-		"successRenameCount": "Renamed %d files",
-		"totalFiles":         "Total files to modify",
-		"logSaveError":       "Failed to save log",
+		"success":              "✅ SUCCESS",
+		"warning":              "⚠️ WARNING",
+		"error":                "❌ ERROR",
+		"confirm":              "confirm",
+		"successSavedTo":       "successfully saved to ",
+		"noLogSaved":           "No change record, log is empty",
+		"selectFormat":         "Please select extension to modify",
+		"selectDirFirst":       "Please select a directory",
+		"copy":                 "Copy",
+		"copySuccess":          "Copied successfully",
+		"noUndoOperations":     "No operations to undo",
+		"undoSuccess":          "Successfully undone renaming %d files",
+		"renameSuccess":        "Rename Successful",
+		"duplicateNames":       "The following files will be renamed to the same name",
+		"failGetFiles":         "Failed to get file list",
+		"operationCancelled":   "Operation Cancelled",
+		"successRenameCount":   "Renamed %d files",
+		"totalFiles":           "Total files to modify",
+		"logSaveError":         "Failed to save log",
+		"journalReadError":     "Failed to read the rename journal: %v",
+		"interruptedRename":    "%d rename batch(es) did not finish last time: %d file(s) were renamed and %d were not. Use Undo to restore the renamed files.",
+		"cancel":               "Cancel",
+		"redoSuccess":          "Successfully redid renaming %d files",
+		"undoFailedCount":      "%d file(s) could not be processed; the reasons are shown in the list",
+		"conflictTitle":        "Name conflict",
+		"conflictMessage":      "%s would be renamed to %s, but that name is already taken.\n\nFile to rename: %s\nExisting file: %s",
+		"conflictApplyAll":     "Do this for all remaining conflicts",
+		"conflict_skip":        "Skip",
+		"conflict_overwrite":   "Overwrite",
+		"conflict_suffix":      "Add suffix",
+		"conflict_newer":       "Keep newer",
+		"conflict_dedupe":      "Remove if identical",
+		"rollbackComplete":     "Rename failed; rolled back %d completed renames, the folder is unchanged",
+		"rollbackIncomplete":   "Rename failed and the rollback is incomplete: %d files could not be restored",
+		"rollbackFailed":       "could not restore the original name",
+		"interruptedTitle":     "Unfinished rename batch",
+		"interruptedBatch":     "The rename batch started at %s (%s) did not finish: %d file(s) were renamed and %d remain.\nYou can finish the remaining renames or restore the renamed files.",
+		"renameProgress":       "%d / %d  %s\n%.1f files/s, about %s left",
+		"cancelledReport":      "Cancelled: %d file(s) renamed, %d file(s) not renamed",
+		"jobSaved":             "Job saved: %s",
+		"runJobConfirm":        "Rename the files in %s as saved in the job? (%d files in manual order)",
+		"conflict_prefix":      "Prefix with folder path",
+		"selectOutputDirFirst": "Please choose an output folder first",
+		"copyProgress":         "Copying to another drive: %.1f / %.1f MB",
+		"preflightTitle":       "%d of %d file(s) failed the pre-flight check",
+		"preflightExclude":     "Exclude these files and continue",
+		"problem_target":       "New name could not be generated",
+		"problem_missing":      "Source no longer exists since the scan",
+		"problem_no_write":     "No write permission on the folder",
+		"problem_read_only":    "File is read-only and cannot be removed or replaced",
+		"problem_locked":       "File is in use by another program",
+		"problem_too_long":     "Target path or name is too long",
+		"problem_invalid_name": "Target name contains characters or a reserved name not allowed by the file system",
+		"problem_conflict":     "Conflicts with an existing name",
+		"skippedCount":         ", %d unchanged or skipped",
+		"rolledBackCount":      ", %d rolled back to their original names",
 	},
 	"ja": {
-		"success":            "✅ 成功",
-		"warning":            "⚠️ 警告",
-		"error":              "❌ エラー",
-		"confirm":            "確認する",
-		"successSavedTo":     "に正常に保存されました",
-		"noLogSaved":         "変更記録がありません。ログは空です",
-		"selectFormat":       "変更する拡張子を選択してください",
-		"selectDirFirst":     "ディレクトリを選択してください",
-		"copy":               "コピー",
-		"copySuccess":        "コピーしました",
-		"noUndoOperations":   "元に戻す操作がありません",
-		"undoSuccess":        "%d ファイルの名前変更を正常に元に戻しました",
-		"renameSuccess":      "リネーム成功",
-		"duplicateNames":     "以下のファイルは同じ名前にリネームされます",
-		"failGetFiles":       "ファイルリストの取得に失敗しました",
-		"operationCancelled": "操作がキャンセルされました",
-		"successRenameCount": "%d 件のファイルの名前を変更しました",
-		"totalFiles":         "変更するファイルの総数",
-		"logSaveError":       "ログの保存に失敗しました",
+		"success":              "✅ 成功",
+		"warning":              "⚠️ 警告",
+		"error":                "❌ エラー",
+		"confirm":              "確認する",
+		"successSavedTo":       "に正常に保存されました",
+		"noLogSaved":           "変更記録がありません。ログは空です",
+		"selectFormat":         "変更する拡張子を選択してください",
+		"selectDirFirst":       "ディレクトリを選択してください",
+		"copy":                 "コピー",
+		"copySuccess":          "コピーしました",
+		"noUndoOperations":     "元に戻す操作がありません",
+		"undoSuccess":          "%d ファイルの名前変更を正常に元に戻しました",
+		"renameSuccess":        "リネーム成功",
+		"duplicateNames":       "以下のファイルは同じ名前にリネームされます",
+		"failGetFiles":         "ファイルリストの取得に失敗しました",
+		"operationCancelled":   "操作がキャンセルされました",
+		"successRenameCount":   "%d 件のファイルの名前を変更しました",
+		"totalFiles":           "変更するファイルの総数",
+		"logSaveError":         "ログの保存に失敗しました",
+		"journalReadError":     "リネームログの読み込みに失敗しました：%v",
+		"interruptedRename":    "前回 %d 件の一括リネームが正常に終了しませんでした：%d 個のファイルはリネーム済み、%d 個は未完了です。「元に戻す」でリネーム済みのファイルを復元できます。",
+		"cancel":               "キャンセル",
+		"redoSuccess":          "%d ファイルの名前変更をやり直しました",
+		"undoFailedCount":      "%d 個のファイルを処理できませんでした。理由は一覧に表示されています",
+		"conflictTitle":        "名前の競合",
+		"conflictMessage":      "%s を %s にリネームしようとしましたが、その名前は既に使用されています。\n\nリネームするファイル：%s\n既存のファイル：%s",
+		"conflictApplyAll":     "残りの競合すべてに適用",
+		"conflict_skip":        "スキップ",
+		"conflict_overwrite":   "上書き",
+		"conflict_suffix":      "連番を付ける",
+		"conflict_newer":       "新しい方を残す",
+		"conflict_dedupe":      "同一内容なら重複を削除",
+		"rollbackComplete":     "リネームに失敗したため、完了済みの %d 件を元に戻しました。フォルダーは変更されていません",
+		"rollbackIncomplete":   "リネームに失敗し、ロールバックが完了しませんでした：%d 件のファイルを元に戻せませんでした",
+		"rollbackFailed":       "元の名前に戻せませんでした",
+		"interruptedTitle":     "未完了の一括リネーム",
+		"interruptedBatch":     "%s に開始した一括リネーム（%s）が正常に終了しませんでした：%d 個のファイルはリネーム済み、%d 個が未完了です。\n残りのリネームを続行するか、リネーム済みのファイルを元に戻せます。",
+		"renameProgress":       "%d / %d  %s\n%.1f 件/秒、残り約 %s",
+		"cancelledReport":      "キャンセルしました：%d 個のファイルをリネーム済み、%d 個は未リネーム",
+		"jobSaved":             "ジョブを保存しました：%s",
+		"runJobConfirm":        "ジョブの設定で %s のファイルをリネームしますか？（手動順 %d 件）",
+		"conflict_prefix":      "フォルダー名を前に付ける",
+		"selectOutputDirFirst": "先に出力フォルダーを選択してください",
+		"copyProgress":         "別のドライブにコピー中：%.1f / %.1f MB",
+		"preflightTitle":       "%d/%d 個のファイルが実行前チェックに失敗しました",
+		"preflightExclude":     "これらのファイルを除外して続行",
+		"problem_target":       "新しい名前を生成できません",
+		"problem_missing":      "スキャン後に元のファイルが見つかりません",
+		"problem_no_write":     "フォルダーへの書き込み権限がありません",
+		"problem_read_only":    "ファイルが読み取り専用のため削除または上書きできません",
+		"problem_locked":       "ファイルが他のプログラムで使用中です",
+		"problem_too_long":     "移動先のパスまたは名前が長すぎます",
+		"problem_invalid_name": "移動先の名前に使用できない文字または予約名が含まれています",
+		"problem_conflict":     "既存の名前と重複しています",
+		"skippedCount":         "、%d 件は変更なしまたはスキップ",
+		"rolledBackCount":      "、%d 件を元の名前に戻しました",
 	},
 }
 
@@ -142,6 +253,55 @@ var button_translations = map[string]map[string]string{
 		"formatNumDivision":   "格式单独计数",
 		"startFromZero":       "序号从0开始",
 		"recursiveSubdir":     "递归子目录",
+		"pipelineRename":      "多步重命名",
+		"pipelineSteps":       "步骤列表",
+		"stepType":            "步骤类型",
+		"addStep":             "添加步骤",
+		"caseType":            "大小写格式",
+		"templateRename":      "模板重命名",
+		"namingTemplate":      "命名模板",
+		"metadataFallback":    "元数据缺失时使用",
+		"undoHistory":         "撤销历史",
+		"redoRename":          "重做",
+		"conflictPolicy":      "名称冲突时",
+		"conflictAbort":       "列出冲突并中止",
+		"conflictAsk":         "逐个询问",
+		"suffixPattern":       "后缀格式",
+		"transactional":       "失败时全部回滚",
+		"resumeBatch":         "继续剩余的重命名",
+		"rollbackBatch":       "回滚已完成的重命名",
+		"later":               "稍后处理",
+		"sortBy":              "排序",
+		"sortDescending":      "降序",
+		"sort_name":           "文件名",
+		"sort_natural":        "文件名（自然排序）",
+		"sort_mtime":          "修改时间",
+		"sort_ctime":          "创建时间",
+		"sort_size":           "文件大小",
+		"sort_exif":           "拍摄时间（EXIF）",
+		"sort_manual":         "手动顺序",
+		"moveTop":             "移到顶部",
+		"moveUp":              "上移",
+		"moveDown":            "下移",
+		"moveBottom":          "移到底部",
+		"applyOrder":          "应用此顺序",
+		"saveJob":             "保存任务",
+		"loadJob":             "加载任务",
+		"renameItems":         "重命名对象",
+		"items_files":         "文件",
+		"items_folders":       "文件夹",
+		"items_both":          "文件和文件夹",
+		"flattenDirs":         "合并子文件夹",
+		"removeEmptyDirs":     "删除移空的文件夹",
+		"outputDir":           "输出文件夹",
+		"selectOutputDir":     "选择输出文件夹",
+		"keepStructure":       "保留子文件夹结构",
+		"execMode":            "执行方式",
+		"mode_rename":         "原地重命名",
+		"mode_copy":           "复制到输出文件夹",
+		"mode_symlink":        "在输出文件夹创建符号链接",
+		"mode_hardlink":       "在输出文件夹创建硬链接",
+		"allowMove":           "允许移入文件夹",
 	},
 	"en": {
 		"AppName":             "File Rename Tool",
@@ -186,6 +346,55 @@ var button_translations = map[string]map[string]string{
 		"formatNumDivision":   "Format Specific Numbering",
 		"startFromZero":       "Start from Zero",
 		"recursiveSubdir":     "Recursive Subdirectories",
+		"pipelineRename":      "Multi-step Rename",
+		"pipelineSteps":       "Steps",
+		"stepType":            "Step Type",
+		"addStep":             "Add Step",
+		"caseType":            "Case Style",
+		"templateRename":      "Template Rename",
+		"namingTemplate":      "Naming Template",
+		"metadataFallback":    "Fallback for missing metadata",
+		"undoHistory":         "Undo history",
+		"redoRename":          "Redo",
+		"conflictPolicy":      "On name conflict",
+		"conflictAbort":       "List conflicts and stop",
+		"conflictAsk":         "Ask for each",
+		"suffixPattern":       "Suffix pattern",
+		"transactional":       "Roll back all on failure",
+		"resumeBatch":         "Finish remaining renames",
+		"rollbackBatch":       "Roll back finished renames",
+		"later":               "Later",
+		"sortBy":              "Sort by",
+		"sortDescending":      "Descending",
+		"sort_name":           "Name",
+		"sort_natural":        "Name (natural)",
+		"sort_mtime":          "Modified time",
+		"sort_ctime":          "Created time",
+		"sort_size":           "Size",
+		"sort_exif":           "Date taken (EXIF)",
+		"sort_manual":         "Manual order",
+		"moveTop":             "Move to top",
+		"moveUp":              "Move up",
+		"moveDown":            "Move down",
+		"moveBottom":          "Move to bottom",
+		"applyOrder":          "Use this order",
+		"saveJob":             "Save job",
+		"loadJob":             "Load job",
+		"renameItems":         "Rename",
+		"items_files":         "Files",
+		"items_folders":       "Folders",
+		"items_both":          "Files and folders",
+		"flattenDirs":         "Flatten Folders",
+		"removeEmptyDirs":     "Remove emptied folders",
+		"outputDir":           "Output folder",
+		"selectOutputDir":     "Choose output folder",
+		"keepStructure":       "Keep subfolders",
+		"execMode":            "Mode",
+		"mode_rename":         "Rename in place",
+		"mode_copy":           "Copy to output folder",
+		"mode_symlink":        "Symlinks in output folder",
+		"mode_hardlink":       "Hard links in output folder",
+		"allowMove":           "Allow moving into folders",
 	},
 	"ja": {
 		"AppName":             "ファイル名変更ツール",
@@ -230,6 +439,55 @@ var button_translations = map[string]map[string]string{
 		"formatNumDivision":   "フォーマット別の番号付け",
 		"startFromZero":       "0から開始",
 		"recursiveSubdir":     "サブディレクトリを再帰的に処理",
+		"pipelineRename":      "複数ステップ名前変更",
+		"pipelineSteps":       "ステップ一覧",
+		"stepType":            "ステップの種類",
+		"addStep":             "ステップを追加",
+		"caseType":            "大文字小文字の形式",
+		"templateRename":      "テンプレート名前変更",
+		"namingTemplate":      "命名テンプレート",
+		"metadataFallback":    "メタデータがない場合の代替値",
+		"undoHistory":         "元に戻す履歴",
+		"redoRename":          "やり直す",
+		"conflictPolicy":      "名前が競合した場合",
+		"conflictAbort":       "競合を表示して中止",
+		"conflictAsk":         "その都度確認",
+		"suffixPattern":       "連番の形式",
+		"transactional":       "失敗時にすべて元に戻す",
+		"resumeBatch":         "残りのリネームを続行",
+		"rollbackBatch":       "完了したリネームを元に戻す",
+		"later":               "後で",
+		"sortBy":              "並べ替え",
+		"sortDescending":      "降順",
+		"sort_name":           "ファイル名",
+		"sort_natural":        "ファイル名（自然順）",
+		"sort_mtime":          "更新日時",
+		"sort_ctime":          "作成日時",
+		"sort_size":           "サイズ",
+		"sort_exif":           "撮影日時（EXIF）",
+		"sort_manual":         "手動順",
+		"moveTop":             "先頭へ",
+		"moveUp":              "上へ",
+		"moveDown":            "下へ",
+		"moveBottom":          "末尾へ",
+		"applyOrder":          "この順序を使用",
+		"saveJob":             "ジョブを保存",
+		"loadJob":             "ジョブを読み込む",
+		"renameItems":         "対象",
+		"items_files":         "ファイル",
+		"items_folders":       "フォルダー",
+		"items_both":          "ファイルとフォルダー",
+		"flattenDirs":         "フォルダーを平坦化",
+		"removeEmptyDirs":     "空になったフォルダーを削除",
+		"outputDir":           "出力フォルダー",
+		"selectOutputDir":     "出力フォルダーを選択",
+		"keepStructure":       "サブフォルダー構成を保持",
+		"execMode":            "実行方法",
+		"mode_rename":         "その場で名前を変更",
+		"mode_copy":           "出力フォルダーにコピー",
+		"mode_symlink":        "出力フォルダーにシンボリックリンク",
+		"mode_hardlink":       "出力フォルダーにハードリンク",
+		"allowMove":           "フォルダーへの移動を許可",
 	},
 }
 
@@ -250,6 +508,39 @@ var text_translations = map[string]map[string]string{
 		"insertPositionExceededLength": "以下文件名长度小于指定的插入位置",
 		"insertPositionNegative":       "插入位置不能为负数",
 		"deleteStartNegative":          "删除起始位置不能为负数",
+		"pipelineNoSteps":              "请至少添加一个步骤",
+		"newExtensionEmpty":            "新扩展名不能为空",
+		"invalidRegex":                 "正则表达式无效",
+		"templateEmpty":                "请输入命名模板",
+		"invalidTemplate":              "命名模板无效",
+		"templateTokenHelp":            "可用令牌：{n:03} 序号，{name} 原文件名（:lower :upper :title :camel，:0..4 截取），{ext} 扩展名，{parent} 上级目录，{size:kb} 文件大小，{date:YYYY-MM-DD_hhmmss} 修改时间，{year} {month} {day} 拍摄日期（没有时为修改日期）",
+		"templateImageTokenHelp":       "图片令牌：{taken:YYYY-MM-DD_hhmmss} 拍摄时间，{make} 相机品牌，{model} 相机型号，{lens} 镜头，{iso} ISO，{orientation} 方向，{width} {height} 像素尺寸",
+		"templateAudioTokenHelp":       "音频令牌：{artist} 艺术家，{album} 专辑，{title} 标题，{track:02} 音轨号，{disc} 碟号，{audioyear} 年份",
+		"templateVideoTokenHelp":       "视频令牌：{created:YYYY-MM-DD} 创建时间，{duration} 时长（{duration:hh-mm-ss} 或 {duration:s} 秒数），{resolution} 分辨率，{fps} 帧率，{codec} 编码；{width} {height} 同样适用于视频",
+		"undoHistoryHint":              "未勾选任何文件时，撤销/重做作用于整个操作",
+		"operationInterrupted":         "（已中断）",
+		"entryUndone":                  "已撤销",
+		"undoReasonMissing":            "文件已不存在",
+		"undoReasonTaken":              "名称已被其他文件占用",
+		"undoReasonNotRenamed":         "未被重命名",
+		"undoReasonBusy":               "文件被占用",
+		"unresolvedConflict":           "冲突",
+		"resolution_skip":              "跳过",
+		"resolution_overwrite":         "覆盖已有文件",
+		"resolution_suffix":            "已加后缀",
+		"resolution_dedupe":            "内容相同，删除此重复文件",
+		"templateFolderHelp":           "子文件夹：模板中的 / 表示文件夹，如 {year}/{month}/{name}{ext} 或 {ext}/{name}{ext}（用作文件夹名时扩展名不带点）；缺少的文件夹会自动创建，撤销时删除；需勾选“允许移入文件夹”",
+		"resolution_mkdir":             "新建的文件夹",
+		"undoReasonFolderNotEmpty":     "文件夹中还有其他文件",
+		"resolution_prefix":            "已加文件夹前缀",
+		"resolution_rmdir":             "删除的空文件夹",
+		"flattenHelp":                  "把所有子文件夹中的文件移到所选目录；重名时可按冲突策略加文件夹前缀（如 2023_a.jpg），撤销时恢复原目录结构",
+		"mode_copy":                    "复制到 %s（原文件不变）",
+		"opMode_copy":                  "复制",
+		"mode_symlink":                 "在 %s 创建符号链接（原文件不变）",
+		"mode_hardlink":                "在 %s 创建硬链接（原文件不变）",
+		"opMode_symlink":               "符号链接",
+		"opMode_hardlink":              "硬链接",
 	},
 	"en": {
 		"failInitAppID":                "Failed to initialize application",
@@ -267,6 +558,39 @@ var text_translations = map[string]map[string]string{
 		"insertPositionExceededLength": "The following filenames are shorter than the specified insert position",
 		"insertPositionNegative":       "Insert position cannot be negative",
 		"deleteStartNegative":          "Delete start position cannot be negative",
+		"pipelineNoSteps":              "Please add at least one step",
+		"newExtensionEmpty":            "New extension cannot be empty",
+		"invalidRegex":                 "Invalid regular expression",
+		"templateEmpty":                "Please enter a naming template",
+		"invalidTemplate":              "Invalid naming template",
+		"templateTokenHelp":            "Tokens: {n:03} counter, {name} original name (:lower :upper :title :camel, :0..4 slice), {ext} extension, {parent} parent folder, {size:kb} file size, {date:YYYY-MM-DD_hhmmss} modification time, {year} {month} {day} date taken (modification date if none)",
+		"templateImageTokenHelp":       "Image tokens: {taken:YYYY-MM-DD_hhmmss} date taken, {make} camera make, {model} camera model, {lens} lens, {iso} ISO, {orientation} orientation, {width} {height} pixel size",
+		"templateAudioTokenHelp":       "Audio tokens: {artist} artist, {album} album, {title} title, {track:02} track number, {disc} disc number, {audioyear} year",
+		"templateVideoTokenHelp":       "Video tokens: {created:YYYY-MM-DD} creation time, {duration} duration ({duration:hh-mm-ss} or {duration:s} seconds), {resolution} resolution, {fps} frame rate, {codec} codec; {width} {height} also work for videos",
+		"undoHistoryHint":              "With no files checked, Undo/Redo applies to the whole operation",
+		"operationInterrupted":         "(interrupted)",
+		"entryUndone":                  "undone",
+		"undoReasonMissing":            "file no longer exists",
+		"undoReasonTaken":              "name is used by another file",
+		"undoReasonNotRenamed":         "was not renamed",
+		"undoReasonBusy":               "file is in use",
+		"unresolvedConflict":           "conflict",
+		"resolution_skip":              "skipped",
+		"resolution_overwrite":         "overwrites existing file",
+		"resolution_suffix":            "suffix added",
+		"resolution_dedupe":            "identical, duplicate removed",
+		"templateFolderHelp":           "Subfolders: / in the template starts a folder, e.g. {year}/{month}/{name}{ext} or {ext}/{name}{ext} (as a folder name the extension has no dot); missing folders are created and removed again on undo; requires \"Allow moving into folders\"",
+		"resolution_mkdir":             "created folder",
+		"undoReasonFolderNotEmpty":     "folder is not empty",
+		"resolution_prefix":            "folder prefix added",
+		"resolution_rmdir":             "removed empty folder",
+		"flattenHelp":                  "Moves files from every subfolder into the selected directory; on name clashes the conflict policy can prefix the folder path (e.g. 2023_a.jpg). Undo restores the original tree",
+		"mode_copy":                    "Copying to %s (originals untouched)",
+		"opMode_copy":                  "copy",
+		"mode_symlink":                 "Creating symbolic links in %s (originals untouched)",
+		"mode_hardlink":                "Creating hard links in %s (originals untouched)",
+		"opMode_symlink":               "symlink",
+		"opMode_hardlink":              "hard link",
 	},
 	"ja": {
 		"failInitAppID":                "アプリケーションの初期化に失敗しました",
@@ -284,5 +608,38 @@ var text_translations = map[string]map[string]string{
 		"insertPositionExceededLength": "以下のファイル名は指定された挿入位置より短いです",
 		"insertPositionNegative":       "挿入位置は負の数にできません",
 		"deleteStartNegative":          "削除開始位置は負の数にできません",
+		"pipelineNoSteps":              "少なくとも1つのステップを追加してください",
+		"newExtensionEmpty":            "新しい拡張子を空にすることはできません",
+		"invalidRegex":                 "正規表現が無効です",
+		"templateEmpty":                "命名テンプレートを入力してください",
+		"invalidTemplate":              "命名テンプレートが無効です",
+		"templateTokenHelp":            "トークン：{n:03} 連番、{name} 元のファイル名（:lower :upper :title :camel、:0..4 で切り出し）、{ext} 拡張子、{parent} 親フォルダ、{size:kb} ファイルサイズ、{date:YYYY-MM-DD_hhmmss} 更新日時、{year} {month} {day} 撮影日（なければ更新日）",
+		"templateImageTokenHelp":       "画像トークン：{taken:YYYY-MM-DD_hhmmss} 撮影日時、{make} メーカー、{model} 機種、{lens} レンズ、{iso} ISO、{orientation} 向き、{width} {height} ピクセルサイズ",
+		"templateAudioTokenHelp":       "音声トークン：{artist} アーティスト、{album} アルバム、{title} タイトル、{track:02} トラック番号、{disc} ディスク番号、{audioyear} 年",
+		"templateVideoTokenHelp":       "動画トークン：{created:YYYY-MM-DD} 作成日時、{duration} 再生時間（{duration:hh-mm-ss} または {duration:s} 秒数）、{resolution} 解像度、{fps} フレームレート、{codec} コーデック。{width} {height} は動画にも使えます",
+		"undoHistoryHint":              "ファイルを選択していない場合、元に戻す/やり直しは操作全体に適用されます",
+		"operationInterrupted":         "（中断）",
+		"entryUndone":                  "元に戻し済み",
+		"undoReasonMissing":            "ファイルが存在しません",
+		"undoReasonTaken":              "名前が他のファイルで使用されています",
+		"undoReasonNotRenamed":         "リネームされていません",
+		"undoReasonBusy":               "ファイルが使用中です",
+		"unresolvedConflict":           "競合",
+		"resolution_skip":              "スキップ",
+		"resolution_overwrite":         "既存ファイルを上書き",
+		"resolution_suffix":            "連番を付加",
+		"resolution_dedupe":            "同一内容のため重複を削除",
+		"templateFolderHelp":           "サブフォルダー：テンプレートの / はフォルダーを表します（例 {year}/{month}/{name}{ext}、{ext}/{name}{ext}。フォルダー名では拡張子の点を省きます）。不足しているフォルダーは自動作成され、元に戻すと削除されます。「フォルダーへの移動を許可」をオンにしてください",
+		"resolution_mkdir":             "作成したフォルダー",
+		"undoReasonFolderNotEmpty":     "フォルダーが空ではありません",
+		"resolution_prefix":            "フォルダー名を付加",
+		"resolution_rmdir":             "削除した空フォルダー",
+		"flattenHelp":                  "すべてのサブフォルダーのファイルを選択したフォルダーへ移動します。名前が重なる場合は競合ポリシーでフォルダー名を前に付けられます（例 2023_a.jpg）。元に戻すと元の構成に戻ります",
+		"mode_copy":                    "%s にコピー（元のファイルは変更しません）",
+		"opMode_copy":                  "コピー",
+		"mode_symlink":                 "%s にシンボリックリンクを作成（元のファイルは変更しません）",
+		"mode_hardlink":                "%s にハードリンクを作成（元のファイルは変更しません）",
+		"opMode_symlink":               "シンボリックリンク",
+		"opMode_hardlink":              "ハードリンク",
 	},
 }
@@ -10,6 +10,55 @@ const (
 	RenameTypeInsertChar RenameType = "insert_char"
 	RenameTypeReplace    RenameType = "replace"
 	RenameTypeDeleteChar RenameType = "delete_char"
+	RenameTypePipeline   RenameType = "pipeline"
+	RenameTypeTemplate   RenameType = "template"
+	RenameTypeFlatten    RenameType = "flatten"
+)
+
+// ConflictPolicy 目标名称已被占用时的处理策略
+type ConflictPolicy string
+
+const (
+	ConflictAbort     ConflictPolicy = ""          // 列出冲突并中止（默认）
+	ConflictAsk       ConflictPolicy = "ask"       // 逐个询问，可应用到全部
+	ConflictSkip      ConflictPolicy = "skip"      // 跳过冲突的文件
+	ConflictOverwrite ConflictPolicy = "overwrite" // 覆盖已存在的文件
+	ConflictSuffix    ConflictPolicy = "suffix"    // 按 SuffixPattern 自动加后缀
+	ConflictNewer     ConflictPolicy = "newer"     // 保留修改时间较新的文件
+	ConflictDedupe    ConflictPolicy = "dedupe"    // 内容相同时删除重复文件，不同时加后缀
+	ConflictPrefix    ConflictPolicy = "prefix"    // 在名称前加上相对所选目录的路径（sub_dir_file.jpg），仍冲突时加后缀
+)
+
+// SortKey 生成计划（编号）前文件的排序依据
+type SortKey string
+
+const (
+	SortName     SortKey = "name"    // 文件名（默认）
+	SortNatural  SortKey = "natural" // 自然排序：file2 在 file10 之前
+	SortModified SortKey = "mtime"   // 修改时间
+	SortCreated  SortKey = "ctime"   // 创建时间
+	SortSize     SortKey = "size"    // 文件大小
+	SortExif     SortKey = "exif"    // EXIF 拍摄时间，没有时使用修改时间
+	SortManual   SortKey = "manual"  // 按 ManualOrder 中的手动顺序
+)
+
+// ItemType 重命名对象
+type ItemType string
+
+const (
+	ItemFiles   ItemType = ""        // 只重命名文件（默认）
+	ItemFolders ItemType = "folders" // 只重命名文件夹
+	ItemBoth    ItemType = "both"    // 文件和文件夹
+)
+
+// ExecMode 执行方式
+type ExecMode string
+
+const (
+	ExecRename   ExecMode = ""         // 原地重命名（默认）
+	ExecCopy     ExecMode = "copy"     // 把重命名后的副本写入 OutputDir，原文件不变
+	ExecSymlink  ExecMode = "symlink"  // 在 OutputDir 中创建指向原文件的符号链接
+	ExecHardlink ExecMode = "hardlink" // 在 OutputDir 中创建原文件的硬链接（须在同一磁盘）
 )
 
 // RenameConfig 重命名配置
@@ -34,4 +83,20 @@ type RenameConfig struct {
     DeleteStartPosition     int
     DeleteLength            int
     Filename                string
+    Steps                   []RenameConfig // 操作链中按顺序执行的步骤
+    Template                string         // 命名模板，如 {parent}_{name:lower}_{n:03}{ext}
+    MetadataFallback        string         // 模板中元数据缺失时使用的替代值，为空则报错
+    ConflictPolicy          ConflictPolicy // 目标冲突处理策略
+    SuffixPattern           string         // 自动后缀格式，{n} 为从 2 开始的序号，如 " ({n})"、"_{n:03}"
+    Transactional           bool           // 全部成功或全部回滚：任一文件失败时恢复本批次已完成的重命名
+    SortKey                 SortKey        // 编号前的排序依据，为空时按文件名
+    SortDescending          bool           // 降序排列
+    ManualOrder             []string       // 手动顺序：相对 SelectedDir 的路径（/ 分隔），未列出的文件按自然顺序排在后面
+    Items                   ItemType       // 重命名对象：文件、文件夹或两者
+    Folder                  bool           // 由计划按项设置：当前项是文件夹，名称不拆分扩展名
+    RemoveEmptyDirs         bool           // 执行后删除因移出文件而变空的文件夹（扁平化），撤销时重新创建
+    Mode                    ExecMode       // 执行方式：原地重命名，或把副本/链接写入输出文件夹
+    OutputDir               string         // 复制或链接模式的输出文件夹
+    KeepStructure           bool           // 复制或链接模式下在输出文件夹中保留相对所选目录的子文件夹
+    AllowMove               bool           // 允许新名称包含文件夹（如模板中的子文件夹），否则目标必须与源位于同一文件夹
 }
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"rename-tool/setting/global"
+	"rename-tool/setting/model"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ShowFlatten displays the interface that moves files from subfolders into the selected directory
+func ShowFlatten() {
+	// Create configuration form
+	help := widget.NewLabel(textTr("flattenHelp"))
+	help.Wrapping = fyne.TextWrapWord
+	removeEmpty := widget.NewCheck(buttonTr("removeEmptyDirs"), nil)
+	configForm := container.NewVBox(help, removeEmpty)
+
+	// Create configuration builder
+	configBuilder := func() model.RenameConfig {
+		return model.RenameConfig{
+			Type:            model.RenameTypeFlatten,
+			RemoveEmptyDirs: removeEmpty.Checked,
+		}
+	}
+
+	// Show rename interface
+	ShowRenameUI(RenameUIConfig{
+		Title:           buttonTr("flattenDirs"),
+		Window:          global.MainWindow,
+		RenameType:      model.RenameTypeFlatten,
+		ConfigBuilder:   configBuilder,
+		ValidateConfig:  func(config model.RenameConfig) error { return nil },
+		AdditionalItems: []fyne.CanvasObject{configForm},
+		Recursive:       true,
+	})
+}
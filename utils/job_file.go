@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"rename-tool/common/jobfile"
+)
+
+// setupLoadJobButton 加载预览中保存的任务（包括手动顺序），确认后按任务配置执行
+func setupLoadJobButton(ui *RenameUIComponents) *widget.Button {
+	return widget.NewButton(buttonTr("loadJob"), func() {
+		open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				errorDiaLog(ui.Window, err.Error())
+				return
+			}
+			if reader == nil {
+				return // 取消
+			}
+			path := reader.URI().Path()
+			reader.Close()
+
+			job, err := jobfile.Load(path)
+			if err != nil {
+				errorDiaLog(ui.Window, err.Error())
+				return
+			}
+			message := fmt.Sprintf(dialogTr("runJobConfirm"), job.Config.SelectedDir, len(job.Config.ManualOrder))
+			dialog.ShowConfirm(buttonTr("loadJob"), message, func(ok bool) {
+				if ok {
+					performRename(ui.Window, job.Config, job.Recursive)
+				}
+			}, ui.Window)
+		}, ui.Window)
+		open.SetFilter(storage.NewExtensionFileFilter([]string{jobfile.Extension}))
+		open.Show()
+	})
+}
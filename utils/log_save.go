@@ -19,6 +19,10 @@ func SaveLogs() {
 
 	var sb strings.Builder
 	for _, log := range global.Logs {
+		if log.Resolution != "" {
+			fmt.Fprintf(&sb, "%s -> %s [%s] (%s)\n", log.Original, log.New, log.Time, textTr("resolution_"+log.Resolution))
+			continue
+		}
 		fmt.Fprintf(&sb, "%s -> %s [%s]\n", log.Original, log.New, log.Time)
 	}
 	content := sb.String()
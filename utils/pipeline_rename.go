@@ -0,0 +1,356 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"rename-tool/common/pathgen"
+	"rename-tool/setting/global"
+	"rename-tool/setting/model"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// pipelineStepTypes 操作链中可选的步骤类型（按显示顺序）
+var pipelineStepTypes = []model.RenameType{
+	model.RenameTypeReplace,
+	model.RenameTypeCase,
+	model.RenameTypeInsertChar,
+	model.RenameTypeDeleteChar,
+	model.RenameTypeExtension,
+	model.RenameTypeBatch,
+	model.RenameTypeTemplate,
+}
+
+// caseTypes 大小写转换可选格式
+var caseTypes = []string{"upper", "lower", "title", "camel"}
+
+// ShowPipelineRename displays the multi-step pipeline interface
+func ShowPipelineRename() {
+	var steps []model.RenameConfig
+
+	// Step list, rebuilt whenever steps change
+	stepBox := container.NewVBox()
+	stepScroll := container.NewVScroll(stepBox)
+	stepScroll.SetMinSize(fyne.NewSize(0, 150))
+
+	var refreshSteps func()
+	refreshSteps = func() {
+		stepBox.Objects = nil
+		for i, step := range steps {
+			index := i
+			upBtn := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() {
+				if index > 0 {
+					steps[index-1], steps[index] = steps[index], steps[index-1]
+					refreshSteps()
+				}
+			})
+			downBtn := widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() {
+				if index < len(steps)-1 {
+					steps[index+1], steps[index] = steps[index], steps[index+1]
+					refreshSteps()
+				}
+			})
+			removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				steps = append(steps[:index], steps[index+1:]...)
+				refreshSteps()
+			})
+			label := widget.NewLabel(fmt.Sprintf("%d. %s", index+1, describeStep(step)))
+			stepBox.Add(container.NewBorder(nil, nil, nil, container.NewHBox(upBtn, downBtn, removeBtn), label))
+		}
+		stepBox.Refresh()
+	}
+
+	// Step editor: pick a type, fill its form, then add it to the list
+	formHolder := container.NewStack()
+	var buildStep func() (model.RenameConfig, error)
+
+	typeNames := make([]string, len(pipelineStepTypes))
+	for i, t := range pipelineStepTypes {
+		typeNames[i] = stepTypeName(model.RenameConfig{Type: t})
+	}
+	typeSelect := widget.NewSelect(typeNames, func(selected string) {
+		for i, name := range typeNames {
+			if name == selected {
+				var form fyne.CanvasObject
+				form, buildStep = newStepForm(pipelineStepTypes[i])
+				formHolder.Objects = []fyne.CanvasObject{form}
+				formHolder.Refresh()
+				break
+			}
+		}
+	})
+	typeSelect.SetSelected(typeNames[0])
+
+	addBtn := widget.NewButtonWithIcon(buttonTr("addStep"), theme.ContentAddIcon(), func() {
+		step, err := buildStep()
+		if err != nil {
+			errorDiaLog(global.MainWindow, err.Error())
+			return
+		}
+		steps = append(steps, step)
+		refreshSteps()
+	})
+
+	editor := container.NewVBox(
+		widget.NewLabel(buttonTr("pipelineSteps")),
+		stepScroll,
+		widget.NewSeparator(),
+		widget.NewForm(widget.NewFormItem(buttonTr("stepType"), typeSelect)),
+		formHolder,
+		container.NewHBox(addBtn),
+	)
+
+	// Create configuration builder
+	configBuilder := func() model.RenameConfig {
+		return model.RenameConfig{
+			Type:  model.RenameTypePipeline,
+			Steps: append([]model.RenameConfig(nil), steps...),
+		}
+	}
+
+	// Create validation function
+	validateConfig := func(config model.RenameConfig) error {
+		if len(config.Steps) == 0 {
+			return errors.New(textTr("pipelineNoSteps"))
+		}
+		return nil
+	}
+
+	// Show rename interface
+	ShowRenameUI(RenameUIConfig{
+		Title:           buttonTr("pipelineRename"),
+		Window:          global.MainWindow,
+		RenameType:      model.RenameTypePipeline,
+		ConfigBuilder:   configBuilder,
+		ValidateConfig:  validateConfig,
+		AdditionalItems: []fyne.CanvasObject{editor},
+	})
+}
+
+// newStepForm 根据步骤类型创建配置表单，并返回从表单读取步骤配置的函数
+func newStepForm(renameType model.RenameType) (fyne.CanvasObject, func() (model.RenameConfig, error)) {
+	switch renameType {
+	case model.RenameTypeReplace:
+		patternEntry := widget.NewEntry()
+		textEntry := widget.NewEntry()
+		useRegexCheck := widget.NewCheck(buttonTr("useRegex"), nil)
+		form := widget.NewForm(
+			widget.NewFormItem(buttonTr("replacePattern"), patternEntry),
+			widget.NewFormItem(buttonTr("replaceText"), textEntry),
+			widget.NewFormItem("", useRegexCheck),
+		)
+		return form, func() (model.RenameConfig, error) {
+			if patternEntry.Text == "" {
+				return model.RenameConfig{}, errors.New(textTr("replaceEmptyText"))
+			}
+			if useRegexCheck.Checked {
+				if _, err := regexp.Compile(patternEntry.Text); err != nil {
+					return model.RenameConfig{}, fmt.Errorf("%s: %v", textTr("invalidRegex"), err)
+				}
+			}
+			return model.RenameConfig{
+				Type:           model.RenameTypeReplace,
+				ReplacePattern: patternEntry.Text,
+				ReplaceText:    textEntry.Text,
+				UseRegex:       useRegexCheck.Checked,
+			}, nil
+		}
+
+	case model.RenameTypeCase:
+		names := make([]string, len(caseTypes))
+		for i, caseType := range caseTypes {
+			names[i] = buttonTr(caseType + "Case")
+		}
+		caseSelect := widget.NewSelect(names, nil)
+		caseSelect.SetSelected(names[0])
+		form := widget.NewForm(widget.NewFormItem(buttonTr("caseType"), caseSelect))
+		return form, func() (model.RenameConfig, error) {
+			return model.RenameConfig{
+				Type:     model.RenameTypeCase,
+				CaseType: caseTypes[caseSelect.SelectedIndex()],
+			}, nil
+		}
+
+	case model.RenameTypeInsertChar:
+		positionEntry := widget.NewEntry()
+		textEntry := widget.NewEntry()
+		form := widget.NewForm(
+			widget.NewFormItem(buttonTr("insertPosition"), positionEntry),
+			widget.NewFormItem(buttonTr("insertText"), textEntry),
+		)
+		return form, func() (model.RenameConfig, error) {
+			position, err := strconv.Atoi(positionEntry.Text)
+			if err != nil {
+				return model.RenameConfig{}, errors.New(textTr("isNotNumber"))
+			}
+			if position < 0 {
+				return model.RenameConfig{}, errors.New(textTr("insertPositionNegative"))
+			}
+			if textEntry.Text == "" {
+				return model.RenameConfig{}, errors.New(textTr("insertEmptyText"))
+			}
+			return model.RenameConfig{
+				Type:           model.RenameTypeInsertChar,
+				InsertPosition: position,
+				InsertText:     textEntry.Text,
+			}, nil
+		}
+
+	case model.RenameTypeDeleteChar:
+		positionEntry := widget.NewEntry()
+		lengthEntry := widget.NewEntry()
+		form := widget.NewForm(
+			widget.NewFormItem(buttonTr("deletePosition"), positionEntry),
+			widget.NewFormItem(buttonTr("deleteLength"), lengthEntry),
+		)
+		return form, func() (model.RenameConfig, error) {
+			position, err := strconv.Atoi(positionEntry.Text)
+			if err != nil {
+				return model.RenameConfig{}, errors.New(textTr("isNotNumber"))
+			}
+			length, err := strconv.Atoi(lengthEntry.Text)
+			if err != nil {
+				return model.RenameConfig{}, errors.New(textTr("delLengthIsNotNumber"))
+			}
+			if position < 0 {
+				return model.RenameConfig{}, errors.New(textTr("deleteStartNegative"))
+			}
+			if length < 0 {
+				return model.RenameConfig{}, errors.New(textTr("delLengthNegative"))
+			}
+			return model.RenameConfig{
+				Type:                model.RenameTypeDeleteChar,
+				DeleteStartPosition: position,
+				DeleteLength:        length,
+			}, nil
+		}
+
+	case model.RenameTypeExtension:
+		extEntry := widget.NewEntry()
+		form := widget.NewForm(widget.NewFormItem(buttonTr("newExtension"), extEntry))
+		return form, func() (model.RenameConfig, error) {
+			newExt := strings.TrimSpace(extEntry.Text)
+			if newExt == "" {
+				return model.RenameConfig{}, errors.New(textTr("newExtensionEmpty"))
+			}
+			if !strings.HasPrefix(newExt, ".") {
+				newExt = "." + newExt
+			}
+			return model.RenameConfig{
+				Type:         model.RenameTypeExtension,
+				NewExtension: newExt,
+			}, nil
+		}
+
+	case model.RenameTypeTemplate:
+		templateEntry := widget.NewEntry()
+		templateEntry.SetPlaceHolder("{name}_{n:03}{ext}")
+		startFromZero := widget.NewCheck(buttonTr("startFromZero"), nil)
+		form := widget.NewForm(
+			widget.NewFormItem(buttonTr("namingTemplate"), templateEntry),
+			widget.NewFormItem("", startFromZero),
+		)
+		return form, func() (model.RenameConfig, error) {
+			if templateEntry.Text == "" {
+				return model.RenameConfig{}, errors.New(textTr("templateEmpty"))
+			}
+			if err := pathgen.ValidateTemplate(templateEntry.Text); err != nil {
+				return model.RenameConfig{}, fmt.Errorf("%s: %v", textTr("invalidTemplate"), err)
+			}
+			return model.RenameConfig{
+				Type:          model.RenameTypeTemplate,
+				Template:      templateEntry.Text,
+				StartFromZero: startFromZero.Checked,
+			}, nil
+		}
+
+	default: // model.RenameTypeBatch
+		digits := []string{"0", "1", "2", "3", "4", "5"}
+		prefixDigits := widget.NewSelect(digits, nil)
+		prefixDigits.SetSelected("0")
+		prefixText := widget.NewEntry()
+		keepOriginal := widget.NewCheck(buttonTr("keepOriginal"), nil)
+		keepOriginal.SetChecked(true)
+		suffixText := widget.NewEntry()
+		suffixDigits := widget.NewSelect(digits, nil)
+		suffixDigits.SetSelected("0")
+		formatSpecificNumbering := widget.NewCheck(buttonTr("formatNumDivision"), nil)
+		startFromZero := widget.NewCheck(buttonTr("startFromZero"), nil)
+		startFromZero.SetChecked(true)
+		form := widget.NewForm(
+			widget.NewFormItem(buttonTr("prefixDigits"), prefixDigits),
+			widget.NewFormItem(buttonTr("prefixText"), prefixText),
+			widget.NewFormItem("", keepOriginal),
+			widget.NewFormItem(buttonTr("suffixText"), suffixText),
+			widget.NewFormItem(buttonTr("suffixDigits"), suffixDigits),
+			widget.NewFormItem("", container.NewHBox(formatSpecificNumbering, startFromZero)),
+		)
+		return form, func() (model.RenameConfig, error) {
+			preDig, _ := strconv.Atoi(prefixDigits.Selected)
+			sufDig, _ := strconv.Atoi(suffixDigits.Selected)
+			return model.RenameConfig{
+				Type:                    model.RenameTypeBatch,
+				PrefixDigits:            preDig,
+				PrefixText:              prefixText.Text,
+				SuffixDigits:            sufDig,
+				SuffixText:              suffixText.Text,
+				KeepOriginal:            keepOriginal.Checked,
+				FormatSpecificNumbering: formatSpecificNumbering.Checked,
+				StartFromZero:           startFromZero.Checked,
+			}, nil
+		}
+	}
+}
+
+// stepTypeName 返回步骤类型的显示名称
+func stepTypeName(step model.RenameConfig) string {
+	switch step.Type {
+	case model.RenameTypeBatch:
+		return buttonTr("sequenceRename")
+	case model.RenameTypeExtension:
+		return buttonTr("extensionModify")
+	case model.RenameTypeCase:
+		if step.CaseType != "" {
+			return buttonTr(step.CaseType + "Case")
+		}
+		return buttonTr("caseType")
+	case model.RenameTypeInsertChar:
+		return buttonTr("insertLetter")
+	case model.RenameTypeDeleteChar:
+		return buttonTr("deleteLetter")
+	case model.RenameTypeReplace:
+		return buttonTr("regexReplace")
+	case model.RenameTypeTemplate:
+		return buttonTr("templateRename")
+	default:
+		return string(step.Type)
+	}
+}
+
+// describeStep 返回步骤在列表中的简要说明
+func describeStep(step model.RenameConfig) string {
+	name := stepTypeName(step)
+	switch step.Type {
+	case model.RenameTypeReplace:
+		return fmt.Sprintf("%s: %q → %q", name, step.ReplacePattern, step.ReplaceText)
+	case model.RenameTypeInsertChar:
+		return fmt.Sprintf("%s: %d, %q", name, step.InsertPosition, step.InsertText)
+	case model.RenameTypeDeleteChar:
+		return fmt.Sprintf("%s: %d, %d", name, step.DeleteStartPosition, step.DeleteLength)
+	case model.RenameTypeExtension:
+		return fmt.Sprintf("%s: %s", name, step.NewExtension)
+	case model.RenameTypeTemplate:
+		return fmt.Sprintf("%s: %s", name, step.Template)
+	case model.RenameTypeBatch:
+		return fmt.Sprintf("%s: %d/%q/%q/%d", name, step.PrefixDigits, step.PrefixText, step.SuffixText, step.SuffixDigits)
+	default:
+		return name
+	}
+}
@@ -1,24 +1,25 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
-	"runtime"
-	"sync"
+	"sort"
 	"time"
 
 	"rename-tool/common/antisamename"
 	"rename-tool/common/dialogcustomize"
 	"rename-tool/common/dirpath"
-	"rename-tool/common/filestatus"
-	"rename-tool/common/pathgen"
+	"rename-tool/common/executor"
+	"rename-tool/common/planner"
+	"rename-tool/common/preflight"
 	"rename-tool/common/progress"
 	"rename-tool/setting/global"
 	"rename-tool/setting/model"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
-	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
 )
@@ -31,6 +32,7 @@ type RenameUIConfig struct {
 	ConfigBuilder   func() model.RenameConfig
 	ValidateConfig  func(config model.RenameConfig) error
 	AdditionalItems []fyne.CanvasObject
+	Recursive       bool // 总是包含子目录，勾选并禁用“包含子目录”
 }
 
 // ✅ 主入口函数，整合UI、事件与布局
@@ -41,12 +43,27 @@ func ShowRenameUI(config RenameUIConfig) {
 		errorDiaLog(global.MainWindow, err.Error())
 		return
 	}
+	if config.Recursive {
+		ui.RecursiveCheck.SetChecked(true)
+		ui.RecursiveCheck.Disable()
+	}
+	if config.RenameType == model.RenameTypeFlatten {
+		// 扁平化本身就是移动文件
+		ui.AllowMoveCheck.SetChecked(true)
+		ui.AllowMoveCheck.Disable()
+	}
 
-	scanBtn, previewBtn, renameBtn, backBtn := setupRenameUIEvents(ui, config)
+	scanBtn, previewBtn, renameBtn, backBtn, loadJobBtn := setupRenameUIEvents(ui, config)
 
 	dirBox := container.NewHBox(ui.DirSelector, scanBtn)
 	formatBox := container.NewHBox(ui.FormatLabel, ui.SelectAllBtn)
-	recursiveBox := container.NewHBox(ui.RecursiveCheck)
+	recursiveBox := container.NewHBox(widget.NewLabel(buttonTr("renameItems")), ui.ItemSelect, ui.RecursiveCheck, ui.TransactionCheck, ui.AllowMoveCheck)
+	sortBox := container.NewHBox(widget.NewLabel(buttonTr("sortBy")), ui.SortSelect, ui.SortDescCheck)
+	conflictBox := container.NewHBox(
+		widget.NewLabel(buttonTr("conflictPolicy")), ui.ConflictSelect,
+		widget.NewLabel(buttonTr("suffixPattern")), ui.SuffixEntry,
+	)
+	outputBox := container.NewHBox(widget.NewLabel(buttonTr("execMode")), ui.ModeSelect, ui.OutputSelector, ui.KeepStructureCheck)
 
 	mainContent := container.NewVBox(
 		ui.Title,
@@ -54,6 +71,9 @@ func ShowRenameUI(config RenameUIConfig) {
 		dirBox,
 		widget.NewSeparator(),
 		recursiveBox,
+		sortBox,
+		conflictBox,
+		outputBox,
 		widget.NewSeparator(),
 		formatBox,
 		ui.FormatScroll,
@@ -65,201 +85,227 @@ func ShowRenameUI(config RenameUIConfig) {
 		mainContent.Add(widget.NewSeparator())
 	}
 
-	bottomButtons := container.NewHBox(layout.NewSpacer(), backBtn, previewBtn, renameBtn)
+	bottomButtons := container.NewHBox(loadJobBtn, layout.NewSpacer(), backBtn, previewBtn, renameBtn)
 	mainContent.Add(bottomButtons)
 
 	ui.Window.SetContent(mainContent)
 	ui.Window.Show()
 }
 
-// performRename 执行重命名操作
-func performRename(window fyne.Window, config model.RenameConfig, recursive bool) {
+// buildRenamePlan 列出所选目录中的文件并生成重命名计划，预览与执行使用同一流程，结果一致
+func buildRenamePlan(config model.RenameConfig, recursive bool) (*planner.Plan, error) {
 	if config.SelectedDir == "" {
-		errorDiaLog(window, dialogTr("selectDirFirst"))
-		return
+		return nil, errors.New(dialogTr("selectDirFirst"))
 	}
-	// 获取文件列表
-	files, err := dirpath.GetFiles(config.SelectedDir, config.Formats, recursive)
+	files, err := dirpath.GetItems(config.SelectedDir, config.Formats, recursive, config.Items)
 	if err != nil {
-		errorDiaLog(window, dialogTr("failGetFiles"))
-		return
+		return nil, fmt.Errorf("%s: %w", dialogTr("failGetFiles"), err)
 	}
+	return planner.Build(files, config)
+}
 
-	// 统一防重名预检（批量内部重复、命中磁盘已存在路径）
-	if stop, err := antisamename.CheckAndShowConflicts(window, files, config); err != nil {
-		dialog.ShowError(err, window)
-		return
-	} else if stop {
+// performRename 执行重命名操作
+func performRename(window fyne.Window, config model.RenameConfig, recursive bool) {
+	// 生成重命名计划，预检与执行共用同一份计划
+	plan, err := buildRenamePlan(config, recursive)
+	if err != nil {
+		errorDiaLog(window, err.Error())
 		return
 	}
 
-	// 检查重名（仅替换类型）
-	if config.Type == model.RenameTypeReplace {
-		duplicates, err := pathgen.CheckDuplicateNames(files, config)
-		if err != nil {
-			dialog.ShowError(err, window)
-			return
-		}
-		if len(duplicates) > 0 {
-			dialogcustomize.ShowMultiLineCopyDialog("error", dialogTr("duplicateNames"), duplicates, window)
-			return
-		}
-	}
+	// 按所选策略处理冲突（询问模式下逐个弹窗），再预检权限、占用、名称和剩余冲突，
+	// 有问题时列出，用户可排除这些文件后继续
+	antisamename.ResolveConflicts(window, plan, func() {
+		preflight.Confirm(window, plan, func() {
+			executePlan(window, plan)
+		})
+	})
+}
 
-	// 创建进度对话框
-	pd := progress.NewDialog(buttonTr("implement"), window)
-	pd.Show()
+// executePlan 执行已通过冲突检查的计划并显示结果
+func executePlan(window fyne.Window, plan *planner.Plan) {
+	// 使用工作池执行计划，目标路径已在计划中确定，与处理顺序无关
+	runPlan(window, buttonTr("implement"), plan, func(ctx context.Context, tracker *executor.Tracker) (<-chan executor.Result, error) {
+		return executor.Run(ctx, plan, tracker), nil
+	}, nil)
+}
 
-	// 使用工作池处理文件
-	workerCount := runtime.NumCPU()
-	fileChan := make(chan string, len(files))
-	resultChan := make(chan struct {
-		file string
-		err  error
-	}, len(files))
-
-	// 创建本地计数器map和互斥锁
-	counters := make(map[string]int)
-	var countersMutex sync.Mutex
-
-	// 启动工作协程
-	var wg sync.WaitGroup
-	counter := 0
-	var counterMutex sync.Mutex
-	for i := 0; i < workerCount; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for file := range fileChan {
-				// 生成新路径
-				newPath, err := generateRenamePath(file, config, &counter, &counterMutex, counters, &countersMutex)
-				if err != nil {
-					resultChan <- struct {
-						file string
-						err  error
-					}{file: file, err: err}
-					continue
-				}
-
-				// 执行重命名
-				if err := filestatus.RenameFile(file, newPath); err != nil {
-					resultChan <- struct {
-						file string
-						err  error
-					}{file: file, err: err}
-				} else {
-					appendRenameLog(file, newPath)
-					resultChan <- struct {
-						file string
-						err  error
-					}{file: file, err: nil}
-				}
-			}
-		}()
-	}
+// runPlan 在后台执行计划并在进度对话框中显示进度，取消时停止开始新的重命名
+// 全部结果返回后在界面线程显示报告，然后调用 then（可为 nil）
+func runPlan(window fyne.Window, title string, plan *planner.Plan,
+	start func(ctx context.Context, tracker *executor.Tracker) (<-chan executor.Result, error), then func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pd := progress.NewDialog(title, window)
+	pd.SetOnCancel(cancel)
+	pd.Show()
 
-	// 发送文件到工作池
-	go func() {
-		for _, file := range files {
-			fileChan <- file
+	tracker := executor.NewTracker(plan.Len())
+	resultChan, err := start(ctx, tracker)
+	if err != nil {
+		cancel()
+		pd.Hide()
+		errorDiaLog(window, fmt.Sprintf(dialogTr("journalReadError"), err))
+		if then != nil {
+			then()
 		}
-		close(fileChan)
-		wg.Wait()
-		close(resultChan)
-	}()
-
-	// 处理结果
-	errorResults := collectRenameResults(resultChan, pd)
-	pd.Hide()
-
-	if pd.IsCancelled() {
-		warningDiaLog(window, dialogTr("operationCancelled"))
 		return
 	}
 
-	// 显示错误或成功消息
-	showRenameResults(window, errorResults, len(files))
+	go func() {
+		defer cancel()
+		stop := make(chan struct{})
+		go trackProgress(pd, tracker, stop)
+
+		// 取消后仍读取全部结果：正在执行的重命名会完成，报告需要列出每个文件的最终状态
+		results := collectRenameResults(resultChan)
+		results.transactional = plan.Config.Transactional
+		results.cancelled = ctx.Err() != nil
+		close(stop)
+
+		fyne.Do(func() {
+			pd.Hide()
+			showRenameResults(window, results)
+			if then != nil {
+				then()
+			}
+		})
+	}()
 }
 
-// generateRenamePath 生成重命名路径
-func generateRenamePath(file string, config model.RenameConfig, counter *int, counterMutex *sync.Mutex,
-	counters map[string]int, countersMutex *sync.Mutex) (string, error) {
-	switch config.Type {
-	case model.RenameTypeBatch:
-		// 批量重命名需要计数器和扩展名计数器
-		counterMutex.Lock()
-		currentCounter := *counter
-		*counter++
-		counterMutex.Unlock()
-
-		countersMutex.Lock()
-		ext := filepath.Ext(file)
-		if _, exists := counters[ext]; !exists {
-			counters[ext] = 0
+// trackProgress 定时把执行进度写入进度对话框，直到 stop 关闭
+func trackProgress(pd *progress.Dialog, tracker *executor.Tracker, stop <-chan struct{}) {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		p := tracker.Snapshot()
+		if p.Total == 0 {
+			continue
+		}
+		eta := "-"
+		if p.ETA > 0 {
+			eta = p.ETA.Round(time.Second).String()
 		}
-		newPath, err := pathgen.GenerateBatchRenamePath(file, config, currentCounter, counters)
-		countersMutex.Unlock()
-		return newPath, err
-
-	case model.RenameTypeExtension:
-		return pathgen.GenerateExtensionRenamePath(file, config)
-	case model.RenameTypeCase:
-		return pathgen.GenerateCaseRenamePath(file, config)
-	case model.RenameTypeInsertChar:
-		return pathgen.GenerateInsertCharRenamePath(file, config)
-	case model.RenameTypeReplace:
-		return pathgen.GenerateReplaceRenamePath(file, config)
-	case model.RenameTypeDeleteChar:
-		return pathgen.GenerateDeleteCharRenamePath(file, config)
-	default:
-		return "", fmt.Errorf("unsupported rename type: %v", config.Type)
+		status := fmt.Sprintf(dialogTr("renameProgress"), p.Done, p.Total, filepath.Base(p.Current), p.Rate, eta)
+		done := float64(p.Done)
+		if p.Size > 0 {
+			// 大文件移到其他磁盘时显示复制进度，进度条按已复制的比例前进
+			status += "\n" + fmt.Sprintf(dialogTr("copyProgress"), float64(p.Copied)/(1<<20), float64(p.Size)/(1<<20))
+			done += float64(p.Copied) / float64(p.Size)
+		}
+		pd.Update(min(done/float64(p.Total), 1), status)
 	}
 }
 
-// appendRenameLog 追加重命名日志
-func appendRenameLog(original, newPath string) {
-	global.Logs = append(global.Logs, global.RenameLog{
-		Original: original,
-		New:      newPath,
-		Time:     time.Now().Format("2006-01-02 15:04:05"),
-	})
-}
-
 // errorResults 错误结果集合（合并 busyFiles 和 otherErrors）
 type errorResults struct {
-	errors map[string]error
+	errors         map[string]error
+	transactional  bool              // 事务模式：失败时已回滚整个批次
+	rolledBack     []string          // 事务模式下已恢复原名的文件
+	rollbackErrors map[string]error  // 事务模式下未能恢复原名的文件
+	cancelled      bool              // 用户取消了执行
+	renamed        map[string]string // 已重命名（且未回滚）的文件：源 -> 目标
+	notStarted     []string          // 取消或事务中止后未执行的文件
+	skipped        int               // 名称未改变或按冲突策略跳过的文件数
 }
 
-// collectRenameResults 收集重命名结果
-func collectRenameResults(resultChan <-chan struct {
-	file string
-	err  error
-}, pd *progress.Dialog) errorResults {
+// collectRenameResults 收集重命名结果，直到结果通道关闭
+func collectRenameResults(resultChan <-chan executor.Result) errorResults {
 	results := errorResults{
-		errors: make(map[string]error),
+		errors:         make(map[string]error),
+		rollbackErrors: make(map[string]error),
+		renamed:        make(map[string]string),
 	}
 
 	for result := range resultChan {
-		if result.err != nil {
-			results.errors[result.file] = result.err
-		}
-
-		if pd.IsCancelled() {
-			break
+		switch {
+		case result.RollbackErr != nil:
+			results.rollbackErrors[result.Source] = result.RollbackErr
+		case result.RolledBack:
+			results.rolledBack = append(results.rolledBack, result.Source)
+			delete(results.renamed, result.Source)
+		case errors.Is(result.Err, executor.ErrAborted), errors.Is(result.Err, executor.ErrCancelled):
+			// 未执行的文件保持原名，不算作失败
+			results.notStarted = append(results.notStarted, result.Source)
+		case result.Err != nil:
+			results.errors[result.Source] = result.Err
+		case result.Source == result.Target, result.Resolution == planner.ResolutionSkip:
+			results.skipped++
+		default:
+			results.renamed[result.Source] = result.Target
 		}
 	}
 
 	return results
 }
 
-// showRenameResults 显示重命名结果
-func showRenameResults(window fyne.Window, results errorResults, totalFiles int) {
+// showCancelledResults 取消后的报告：逐个列出已重命名、失败和未重命名的文件
+func showCancelledResults(window fyne.Window, results errorResults) {
+	var lines []string
+	for source, target := range results.renamed {
+		lines = append(lines, fmt.Sprintf("✓ %s → %s", source, planner.TargetName(source, target)))
+	}
+	for source, err := range results.errors {
+		lines = append(lines, fmt.Sprintf("✗ %s: %v", source, err))
+	}
+	for source, err := range results.rollbackErrors {
+		lines = append(lines, fmt.Sprintf("✗ %s: %s: %v", source, dialogTr("rollbackFailed"), err))
+	}
+	for _, source := range results.rolledBack {
+		lines = append(lines, fmt.Sprintf("↺ %s", source))
+	}
+	for _, source := range results.notStarted {
+		lines = append(lines, fmt.Sprintf("– %s", source))
+	}
+	sort.Strings(lines)
+
+	// 事务模式下取消会回滚已完成的文件，回滚的文件单独计数
+	notRenamed := len(results.notStarted) + len(results.errors)
+	title := fmt.Sprintf(dialogTr("cancelledReport"), len(results.renamed), notRenamed)
+	if len(results.rolledBack) > 0 {
+		title += fmt.Sprintf(dialogTr("rolledBackCount"), len(results.rolledBack))
+	}
+	dialogcustomize.ShowMultiLineCopyDialog("warning", title, lines, window)
+}
+
+// showRenameResults 显示重命名结果，成功数只计实际重命名的文件
+func showRenameResults(window fyne.Window, results errorResults) {
+	if results.cancelled {
+		showCancelledResults(window, results)
+		return
+	}
+
+	// 事务回滚不完整：列出失败原因和未能恢复的文件
+	if len(results.rollbackErrors) > 0 {
+		for file, err := range results.rollbackErrors {
+			results.errors[file] = fmt.Errorf("%s: %w", dialogTr("rollbackFailed"), err)
+		}
+		title := fmt.Sprintf(dialogTr("rollbackIncomplete"), len(results.rollbackErrors))
+		dialogcustomize.ShowMultiLineErrorDialog("error", title, results.errors, window)
+		return
+	}
+
+	// 事务已完整回滚：目录保持执行前的状态
+	if len(results.errors) > 0 && results.transactional {
+		title := fmt.Sprintf(dialogTr("rollbackComplete"), len(results.rolledBack))
+		dialogcustomize.ShowMultiLineErrorDialog("error", title, results.errors, window)
+		return
+	}
+
 	// 有错误：展示错误列表
 	if len(results.errors) > 0 {
 		dialogcustomize.ShowMultiLineErrorDialog("error", "rename_failed_files", results.errors, window)
 		return
 	}
 
-	// 全部成功
-	successDiaLog(window, fmt.Sprintf(dialogTr("successRenameCount"), totalFiles))
+	// 全部成功：未改变和跳过的文件单独说明
+	message := fmt.Sprintf(dialogTr("successRenameCount"), len(results.renamed))
+	if results.skipped > 0 {
+		message += fmt.Sprintf(dialogTr("skippedCount"), results.skipped)
+	}
+	successDiaLog(window, message)
 }
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"rename-tool/common/executor"
+	"rename-tool/common/journal"
+	"rename-tool/setting/global"
+)
+
+// askInterrupted 逐个询问中断的批次：继续执行剩余文件、回滚已完成的文件，或稍后处理（下次启动再询问）
+func askInterrupted(ops []*journal.OperationLog) {
+	if len(ops) == 0 {
+		return
+	}
+	op, rest := ops[0], ops[1:]
+	window := global.MainWindow
+
+	remaining := executor.RemainingPlan(op)
+	message := fmt.Sprintf(dialogTr("interruptedBatch"),
+		op.Started.Format("2006-01-02 15:04:05"), op.Dir, len(op.Undoable()), remaining.Len())
+	label := widget.NewLabel(message)
+	label.Wrapping = fyne.TextWrapWord
+
+	var d dialog.Dialog
+	resumeBtn := widget.NewButton(buttonTr("resumeBatch"), func() {
+		d.Hide()
+		resumeBatch(window, op, func() { askInterrupted(rest) })
+	})
+	rollbackBtn := widget.NewButton(buttonTr("rollbackBatch"), func() {
+		d.Hide()
+		results, err := executor.Rollback(op)
+		if err != nil {
+			errorDiaLog(window, fmt.Sprintf(dialogTr("journalReadError"), err))
+		} else {
+			showUndoResults(results)
+		}
+		askInterrupted(rest)
+	})
+	laterBtn := widget.NewButton(buttonTr("later"), func() {
+		d.Hide()
+		askInterrupted(rest)
+	})
+
+	buttons := container.NewHBox(layout.NewSpacer(), resumeBtn, rollbackBtn, laterBtn)
+	d = dialog.NewCustomWithoutButtons(dialogTr("interruptedTitle"), container.NewVBox(label, buttons), window)
+	d.Resize(fyne.NewSize(560, 0))
+	d.Show()
+}
+
+// resumeBatch 执行中断批次中剩余的文件，进度和结果显示方式与普通重命名相同，结束后调用 then
+func resumeBatch(window fyne.Window, op *journal.OperationLog, then func()) {
+	plan := executor.RemainingPlan(op)
+	runPlan(window, buttonTr("resumeBatch"), plan, func(ctx context.Context, tracker *executor.Tracker) (<-chan executor.Result, error) {
+		return executor.Resume(ctx, op, plan, tracker)
+	}, then)
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+
+	"rename-tool/common/pathgen"
+	"rename-tool/setting/global"
+	"rename-tool/setting/model"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ShowTemplateRename displays the token-based template interface
+func ShowTemplateRename() {
+	// Create configuration form
+	templateEntry := widget.NewEntry()
+	templateEntry.SetPlaceHolder("{parent}_{name:lower}_{n:03}{ext}")
+
+	tokenHelp := widget.NewLabel(textTr("templateTokenHelp") + "\n" + textTr("templateImageTokenHelp") + "\n" + textTr("templateAudioTokenHelp") + "\n" + textTr("templateVideoTokenHelp") + "\n" + textTr("templateFolderHelp"))
+	tokenHelp.Wrapping = fyne.TextWrapWord
+
+	// 留空时缺失的元数据作为单个文件的错误显示在预览中
+	fallbackEntry := widget.NewEntry()
+	fallbackEntry.SetPlaceHolder("unknown")
+
+	formatSpecificNumbering := widget.NewCheck(buttonTr("formatNumDivision"), nil)
+	startFromZero := widget.NewCheck(buttonTr("startFromZero"), nil)
+
+	configForm := container.NewVBox(
+		widget.NewLabel(buttonTr("namingTemplate")),
+		templateEntry,
+		tokenHelp,
+		widget.NewForm(widget.NewFormItem(buttonTr("metadataFallback"), fallbackEntry)),
+		container.NewHBox(formatSpecificNumbering, startFromZero),
+	)
+
+	// Create configuration builder
+	configBuilder := func() model.RenameConfig {
+		return model.RenameConfig{
+			Type:                    model.RenameTypeTemplate,
+			Template:                templateEntry.Text,
+			MetadataFallback:        fallbackEntry.Text,
+			FormatSpecificNumbering: formatSpecificNumbering.Checked,
+			StartFromZero:           startFromZero.Checked,
+		}
+	}
+
+	// Create validation function
+	validateConfig := func(config model.RenameConfig) error {
+		if config.Template == "" {
+			return errors.New(textTr("templateEmpty"))
+		}
+		if err := pathgen.ValidateTemplate(config.Template); err != nil {
+			return fmt.Errorf("%s: %v", textTr("invalidTemplate"), err)
+		}
+		return nil
+	}
+
+	// Show rename interface
+	ShowRenameUI(RenameUIConfig{
+		Title:           buttonTr("templateRename"),
+		Window:          global.MainWindow,
+		RenameType:      model.RenameTypeTemplate,
+		ConfigBuilder:   configBuilder,
+		ValidateConfig:  validateConfig,
+		AdditionalItems: []fyne.CanvasObject{configForm},
+	})
+}
@@ -1,49 +1,51 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
-	"os"
 
+	"rename-tool/common/executor"
+	"rename-tool/common/filestatus"
+	"rename-tool/common/journal"
 	"rename-tool/setting/global"
 )
 
-// UndoRename handles undoing previous rename operations in memory
+// UndoRename undoes the most recent rename operation recorded in the journal,
+// so it keeps working after the application restarts
 func UndoRename() {
-	if len(global.Logs) == 0 {
+	op, err := journal.LastUndoable()
+	if err != nil {
+		errorDiaLog(global.MainWindow, fmt.Sprintf(dialogTr("journalReadError"), err))
+		return
+	}
+	if op == nil {
 		warningDiaLog(global.MainWindow, dialogTr("noUndoOperations"))
 		return
 	}
 
+	results, err := executor.Undo(op, op.Undoable())
+	if err != nil {
+		errorDiaLog(global.MainWindow, fmt.Sprintf(dialogTr("journalReadError"), err))
+		return
+	}
+
+	showUndoResults(results)
+}
+
+// showUndoResults 显示撤销结果：无法撤销的文件及原因，或成功数量
+func showUndoResults(results []executor.Result) {
 	var (
-		newLogs      []global.RenameLog // 保留未撤销的日志
-		busyFiles    []string           // 无法撤销的文件
+		busyFiles    []string // 无法撤销的文件及原因，日志中保留以便稍后重试
 		successCount int
 	)
-
-	// 倒序遍历日志，最新的重命名先撤销
-	for i := len(global.Logs) - 1; i >= 0; i-- {
-		log := global.Logs[i]
-
-		// 判断目标文件是否存在（即要撤销的“新文件名”）
-		if _, err := os.Stat(log.New); err == nil {
-			// 尝试把文件名改回原名
-			if err := os.Rename(log.New, log.Original); err == nil {
-				successCount++
-				continue // 撤销成功，不保留这条日志
-			} else {
-				// 文件被占用或权限问题
-				busyFiles = append(busyFiles, log.New)
-				newLogs = append([]global.RenameLog{log}, newLogs...)
-			}
-		} else {
-			// 新文件不存在，说明用户手动删了或改了名
-			newLogs = append([]global.RenameLog{log}, newLogs...)
+	for _, result := range results {
+		if result.Err != nil {
+			busyFiles = append(busyFiles, fmt.Sprintf("%s: %s", result.Target, undoReason(result.Err)))
+			continue
 		}
+		successCount++
 	}
 
-	// 更新全局日志（只保留未撤销成功的）
-	global.Logs = newLogs
-
 	// 反馈结果
 	switch {
 	case successCount == 0 && len(busyFiles) == 0:
@@ -56,3 +58,49 @@ func UndoRename() {
 		successDiaLog(global.MainWindow, fmt.Sprintf(dialogTr("undoSuccess"), successCount))
 	}
 }
+
+// CheckInterruptedRenames 启动时检测上次未正常结束的批量重命名，补全日志
+// 有预写计划的批次询问继续执行剩余文件还是回滚已完成的文件；旧日志只能提示撤销
+func CheckInterruptedRenames() {
+	recovered, err := journal.RecoverInterrupted()
+	if err != nil {
+		errorDiaLog(global.MainWindow, fmt.Sprintf(dialogTr("journalReadError"), err))
+		return
+	}
+	ops, err := journal.Interrupted()
+	if err != nil {
+		errorDiaLog(global.MainWindow, fmt.Sprintf(dialogTr("journalReadError"), err))
+		return
+	}
+	if len(ops) > 0 {
+		askInterrupted(ops)
+		return
+	}
+	if len(recovered) == 0 {
+		return
+	}
+
+	done, failed := 0, 0
+	for _, op := range recovered {
+		done += op.Count(journal.KindDone)
+		failed += op.Count(journal.KindFailed)
+	}
+	warningDiaLog(global.MainWindow, fmt.Sprintf(dialogTr("interruptedRename"), len(recovered), done, failed))
+}
+
+// undoReason 将撤销/重做失败的原因转换为界面文本
+func undoReason(err error) string {
+	switch {
+	case errors.Is(err, executor.ErrRenamedMissing), errors.Is(err, executor.ErrOriginalGone):
+		return textTr("undoReasonMissing")
+	case errors.Is(err, executor.ErrOriginalTaken), errors.Is(err, executor.ErrNewNameTaken):
+		return textTr("undoReasonTaken")
+	case errors.Is(err, executor.ErrFolderNotEmpty):
+		return textTr("undoReasonFolderNotEmpty")
+	case errors.Is(err, executor.ErrNotRenamed):
+		return textTr("undoReasonNotRenamed")
+	case filestatus.IsFileBusyError(err):
+		return textTr("undoReasonBusy")
+	}
+	return err.Error()
+}
@@ -0,0 +1,324 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"rename-tool/common/executor"
+	"rename-tool/common/journal"
+	"rename-tool/common/planner"
+	"rename-tool/setting/global"
+	"rename-tool/setting/model"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// undoHistory 撤销历史窗口的状态
+type undoHistory struct {
+	window   fyne.Window
+	ops      []*journal.OperationLog // 最近的在前
+	current  *journal.OperationLog
+	selected map[*journal.EntryLog]bool
+	lastErr  map[[2]string]error // 最近一次撤销/重做失败的原因，刷新后仍然显示
+
+	opList     *widget.List
+	entryList  *widget.List
+	header     *widget.Label
+	selectAll  *widget.Check
+	undoButton *widget.Button
+	redoButton *widget.Button
+}
+
+// ShowUndoHistory displays past rename operations from the journal and lets the user
+// undo or redo a whole operation or individual files
+func ShowUndoHistory() {
+	h := &undoHistory{
+		window:   global.MyApp.NewWindow(buttonTr("undoHistory")),
+		selected: make(map[*journal.EntryLog]bool),
+		lastErr:  make(map[[2]string]error),
+	}
+	if err := h.reload(); err != nil {
+		errorDiaLog(global.MainWindow, fmt.Sprintf(dialogTr("journalReadError"), err))
+		return
+	}
+	if len(h.ops) == 0 {
+		warningDiaLog(global.MainWindow, dialogTr("noUndoOperations"))
+		return
+	}
+
+	h.opList = widget.NewList(
+		func() int { return len(h.ops) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(describeOperation(h.ops[id]))
+		},
+	)
+	h.opList.OnSelected = func(id widget.ListItemID) {
+		h.show(h.ops[id])
+	}
+
+	h.entryList = widget.NewList(
+		func() int {
+			if h.current == nil {
+				return 0
+			}
+			return len(h.current.Entries)
+		},
+		func() fyne.CanvasObject {
+			return container.NewHBox(widget.NewCheck("", nil), widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			entry := h.current.Entries[id]
+			row := obj.(*fyne.Container)
+			check := row.Objects[0].(*widget.Check)
+			check.OnChanged = nil
+			check.SetChecked(h.selected[entry])
+			check.OnChanged = func(checked bool) {
+				h.selected[entry] = checked
+			}
+			row.Objects[1].(*widget.Label).SetText(h.describeEntry(entry))
+		},
+	)
+
+	h.header = widget.NewLabel("")
+	h.header.Wrapping = fyne.TextWrapBreak
+	h.selectAll = widget.NewCheck(buttonTr("selectAll"), func(checked bool) {
+		if h.current == nil {
+			return
+		}
+		for _, entry := range h.current.Entries {
+			h.selected[entry] = checked
+		}
+		h.entryList.Refresh()
+	})
+	h.undoButton = widget.NewButton(buttonTr("undoRename"), func() { h.confirm(false) })
+	h.redoButton = widget.NewButton(buttonTr("redoRename"), func() { h.confirm(true) })
+	closeBtn := widget.NewButton(dialogTr("confirm"), h.window.Close)
+
+	hint := widget.NewLabel(textTr("undoHistoryHint"))
+	hint.Wrapping = fyne.TextWrapWord
+	right := container.NewBorder(
+		container.NewVBox(h.header, h.selectAll),
+		container.NewVBox(hint, container.NewHBox(layout.NewSpacer(), h.undoButton, h.redoButton, closeBtn)),
+		nil, nil,
+		h.entryList,
+	)
+	split := container.NewHSplit(h.opList, right)
+	split.SetOffset(0.35)
+
+	h.window.SetContent(split)
+	h.window.Resize(fyne.NewSize(900, 600))
+	h.opList.Select(0)
+	h.window.Show()
+}
+
+// reload 重新读取日志，保持当前选中的操作
+func (h *undoHistory) reload() error {
+	ops, err := journal.Load()
+	if err != nil {
+		return err
+	}
+	h.ops = h.ops[:0]
+	for i := len(ops) - 1; i >= 0; i-- {
+		if len(ops[i].Entries) > 0 {
+			h.ops = append(h.ops, ops[i])
+		}
+	}
+	return nil
+}
+
+// show 显示操作的文件列表
+func (h *undoHistory) show(op *journal.OperationLog) {
+	h.current = op
+	h.selected = make(map[*journal.EntryLog]bool)
+	h.selectAll.SetChecked(false)
+	h.header.SetText(fmt.Sprintf("%s\n%s", describeOperation(op), op.Dir))
+	h.entryList.Refresh()
+	h.updateButtons()
+}
+
+// updateButtons 根据当前操作中是否有可撤销/可重做的记录启用按钮
+func (h *undoHistory) updateButtons() {
+	undo, redo := false, false
+	for _, entry := range h.current.Entries {
+		undo = undo || entry.State == journal.KindDone
+		redo = redo || entry.State == journal.KindUndone
+	}
+	setEnabled(h.undoButton, undo)
+	setEnabled(h.redoButton, redo)
+}
+
+// targets 返回勾选的记录；未勾选任何记录时作用于整个操作
+func (h *undoHistory) targets(state string) []*journal.EntryLog {
+	var all, picked []*journal.EntryLog
+	for _, entry := range h.current.Entries {
+		if entry.State != state {
+			continue
+		}
+		all = append(all, entry)
+		if h.selected[entry] {
+			picked = append(picked, entry)
+		}
+	}
+	for _, checked := range h.selected {
+		if checked {
+			return picked
+		}
+	}
+	return all
+}
+
+// confirm 预览撤销/重做将执行的操作，确认后执行
+func (h *undoHistory) confirm(redo bool) {
+	state, check, title := journal.KindDone, executor.CheckUndo, buttonTr("undoRename")
+	if redo {
+		state, check, title = journal.KindUndone, executor.CheckRedo, buttonTr("redoRename")
+	}
+	entries := h.targets(state)
+	if len(entries) == 0 {
+		warningDiaLog(h.window, dialogTr("noUndoOperations"))
+		return
+	}
+
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		from, to := entry.Target, entry.Source
+		if redo {
+			from, to = entry.Source, entry.Target
+		}
+		lines[i] = fmt.Sprintf("%s → %s", filepath.Base(from), filepath.Base(to))
+		if err := check(entry); err != nil {
+			lines[i] = fmt.Sprintf("%s ✗ %s", filepath.Base(from), undoReason(err))
+		}
+	}
+	previewList := widget.NewList(
+		func() int { return len(lines) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) { obj.(*widget.Label).SetText(lines[id]) },
+	)
+	content := container.NewGridWrap(fyne.NewSize(600, 300), previewList)
+
+	dialog.ShowCustomConfirm(title, dialogTr("confirm"), dialogTr("cancel"), content, func(ok bool) {
+		if ok {
+			h.run(entries, redo)
+		}
+	}, h.window)
+}
+
+// run 执行撤销/重做并刷新列表，失败的记录保留在列表中并显示原因
+func (h *undoHistory) run(entries []*journal.EntryLog, redo bool) {
+	run := executor.Undo
+	if redo {
+		run = executor.Redo
+	}
+	results, err := run(h.current, entries)
+	if err != nil {
+		errorDiaLog(h.window, fmt.Sprintf(dialogTr("journalReadError"), err))
+		return
+	}
+
+	failed, success := 0, 0
+	for _, result := range results {
+		key := [2]string{result.Source, result.Target}
+		if result.Err != nil {
+			h.lastErr[key] = result.Err
+			failed++
+			continue
+		}
+		delete(h.lastErr, key)
+		success++
+	}
+
+	currentID := h.current.ID
+	if err := h.reload(); err != nil {
+		errorDiaLog(h.window, fmt.Sprintf(dialogTr("journalReadError"), err))
+		return
+	}
+	h.opList.Refresh()
+	for i, op := range h.ops {
+		if op.ID == currentID {
+			h.opList.Select(i)
+			h.show(op)
+		}
+	}
+
+	message := fmt.Sprintf(dialogTr("undoSuccess"), success)
+	if redo {
+		message = fmt.Sprintf(dialogTr("redoSuccess"), success)
+	}
+	if failed > 0 {
+		warningDiaLog(h.window, message+"\n"+fmt.Sprintf(dialogTr("undoFailedCount"), failed))
+		return
+	}
+	successDiaLog(h.window, message)
+}
+
+// describeOperation 操作列表中的一行：时间、类型、执行方式、文件数
+func describeOperation(op *journal.OperationLog) string {
+	name := stepTypeName(model.RenameConfig{Type: model.RenameType(op.Type)})
+	if op.Mode != "" {
+		name += " (" + textTr("opMode_"+op.Mode) + ")"
+	}
+	text := fmt.Sprintf("%s  %s  %d/%d",
+		op.Started.Local().Format("2006-01-02 15:04:05"), name,
+		op.Count(journal.KindDone), len(op.Entries))
+	if op.State == journal.StateInterrupted {
+		text += "  " + textTr("operationInterrupted")
+	}
+	return text
+}
+
+// describeEntry 文件列表中的一行：当前状态以及不能撤销/重做的原因
+func (h *undoHistory) describeEntry(entry *journal.EntryLog) string {
+	source, target := filepath.Base(entry.Source), planner.TargetName(entry.Source, entry.Target)
+	if entry.Mode != "" {
+		target = entry.Target // 副本或链接位于输出文件夹
+	}
+	if entry.Resolution == journal.ResolutionMkdir {
+		source, target = "+", entry.Target // 执行时创建的文件夹
+	}
+	if entry.Resolution == journal.ResolutionRmdir {
+		source, target = entry.Source, "-" // 执行后删除的空文件夹
+	}
+	var text string
+	var reason error
+	if entry.Resolution != "" {
+		target += "  [" + textTr("resolution_"+entry.Resolution) + "]"
+	}
+	switch entry.State {
+	case journal.KindSkipped:
+		return fmt.Sprintf("%s → %s", source, target)
+	case journal.KindDone:
+		text = fmt.Sprintf("%s → %s", source, target)
+		reason = executor.CheckUndo(entry)
+	case journal.KindUndone:
+		text = fmt.Sprintf("%s → %s  (%s)", source, target, textTr("entryUndone"))
+		reason = executor.CheckRedo(entry)
+	default:
+		text = fmt.Sprintf("%s → %s", source, target)
+		if entry.Error != "" {
+			return fmt.Sprintf("%s  ✗ %s", text, entry.Error)
+		}
+		return fmt.Sprintf("%s  ✗ %s", text, textTr("undoReasonNotRenamed"))
+	}
+	if err, ok := h.lastErr[[2]string{entry.Source, entry.Target}]; ok && reason == nil {
+		reason = err
+	}
+	if reason != nil {
+		text += "  ✗ " + undoReason(reason)
+	}
+	return text
+}
+
+// setEnabled 启用或禁用按钮
+func setEnabled(button *widget.Button, enabled bool) {
+	if enabled {
+		button.Enable()
+	} else {
+		button.Disable()
+	}
+}
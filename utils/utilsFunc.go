@@ -1,12 +1,16 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"rename-tool/common/antisamename"
 	"rename-tool/common/dirpath"
+	"rename-tool/common/filesort"
 	"rename-tool/common/preview"
 	"rename-tool/common/scan"
 	"rename-tool/common/theme"
 	"rename-tool/setting/global"
+	"rename-tool/setting/model"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -24,6 +28,66 @@ type RenameUIComponents struct {
 	FormatScroll        *container.Scroll
 	DirSelector         fyne.CanvasObject
 	RecursiveCheck      *widget.Check
+	TransactionCheck    *widget.Check
+	AllowMoveCheck      *widget.Check // 允许新名称包含文件夹，把文件移入子文件夹
+	ConflictSelect      *widget.Select
+	SuffixEntry         *widget.Entry
+	SortSelect          *widget.Select
+	SortDescCheck       *widget.Check
+	ManualOrder         []string // 预览中调整后应用的手动顺序
+	ItemSelect          *widget.Select
+	ModeSelect          *widget.Select // 执行方式：原地重命名，或把副本/链接写入输出文件夹
+	OutputSelector      fyne.CanvasObject
+	OutputDir           string
+	KeepStructureCheck  *widget.Check
+}
+
+// execModes 执行方式，顺序即下拉框顺序
+var execModes = []model.ExecMode{model.ExecRename, model.ExecCopy, model.ExecSymlink, model.ExecHardlink}
+
+// itemTypes 重命名对象，顺序即下拉框顺序
+var itemTypes = []model.ItemType{model.ItemFiles, model.ItemFolders, model.ItemBoth}
+
+// sortKeys 排序依据，顺序即下拉框顺序；手动顺序在预览中调整
+var sortKeys = append(append([]model.SortKey{}, filesort.Keys...), model.SortManual)
+
+// conflictPolicies 冲突处理策略，顺序即下拉框顺序
+var conflictPolicies = []model.ConflictPolicy{
+	model.ConflictAbort,
+	model.ConflictAsk,
+	model.ConflictSkip,
+	model.ConflictOverwrite,
+	model.ConflictSuffix,
+	model.ConflictPrefix,
+	model.ConflictNewer,
+	model.ConflictDedupe,
+}
+
+// conflictPolicyName 冲突处理策略的显示名称
+func conflictPolicyName(policy model.ConflictPolicy) string {
+	switch policy {
+	case model.ConflictAbort:
+		return buttonTr("conflictAbort")
+	case model.ConflictAsk:
+		return buttonTr("conflictAsk")
+	}
+	return dialogTr("conflict_" + string(policy))
+}
+
+// execModeName 执行方式的显示名称
+func execModeName(mode model.ExecMode) string {
+	if mode == model.ExecRename {
+		return buttonTr("mode_rename")
+	}
+	return buttonTr("mode_" + string(mode))
+}
+
+// itemTypeName 重命名对象的显示名称
+func itemTypeName(items model.ItemType) string {
+	if items == model.ItemFiles {
+		return buttonTr("items_files")
+	}
+	return buttonTr("items_" + string(items))
 }
 
 func safeUI(f func()) {
@@ -71,8 +135,59 @@ func initRenameUI(config RenameUIConfig) (*RenameUIComponents, error) {
 
 	recursiveCheck := widget.NewCheck(buttonTr("recursiveSubdir"), nil)
 	recursiveCheck.SetChecked(false) // 默认不递归
+	transactionCheck := widget.NewCheck(buttonTr("transactional"), nil)
+	allowMoveCheck := widget.NewCheck(buttonTr("allowMove"), nil)
+
+	itemNames := make([]string, len(itemTypes))
+	for i, items := range itemTypes {
+		itemNames[i] = itemTypeName(items)
+	}
+	itemSelect := widget.NewSelect(itemNames, nil)
+	itemSelect.SetSelectedIndex(0) // 默认只重命名文件
+
+	policyNames := make([]string, len(conflictPolicies))
+	for i, policy := range conflictPolicies {
+		policyNames[i] = conflictPolicyName(policy)
+	}
+	conflictSelect := widget.NewSelect(policyNames, nil)
+	conflictSelect.SetSelectedIndex(0) // 默认列出冲突并中止
+	suffixEntry := widget.NewEntry()
+	suffixEntry.SetPlaceHolder(antisamename.DefaultSuffixPattern)
+
+	sortNames := make([]string, len(sortKeys))
+	for i, key := range sortKeys {
+		sortNames[i] = buttonTr("sort_" + string(key))
+	}
+	sortSelect := widget.NewSelect(sortNames, nil)
+	sortSelect.SetSelectedIndex(0) // 默认按文件名
+	sortDescCheck := widget.NewCheck(buttonTr("sortDescending"), nil)
+	sortSelect.OnChanged = func(string) {
+		// 手动顺序没有升降序之分
+		if sortKeys[sortSelect.SelectedIndex()] == model.SortManual {
+			sortDescCheck.Disable()
+		} else {
+			sortDescCheck.Enable()
+		}
+	}
+
+	modeNames := make([]string, len(execModes))
+	for i, mode := range execModes {
+		modeNames[i] = execModeName(mode)
+	}
+	keepStructureCheck := widget.NewCheck(buttonTr("keepStructure"), nil)
+	keepStructureCheck.Disable()
+	modeSelect := widget.NewSelect(modeNames, nil)
+	modeSelect.OnChanged = func(string) {
+		// 子文件夹结构只作用于输出文件夹
+		if execModes[modeSelect.SelectedIndex()] == model.ExecRename {
+			keepStructureCheck.Disable()
+		} else {
+			keepStructureCheck.Enable()
+		}
+	}
+	modeSelect.SetSelectedIndex(0) // 默认原地重命名
 
-	return &RenameUIComponents{
+	ui := &RenameUIComponents{
 		Window:              window,
 		Title:               title,
 		FormatLabel:         formatLabel,
@@ -82,7 +197,35 @@ func initRenameUI(config RenameUIConfig) (*RenameUIComponents, error) {
 		FormatScroll:        formatScroll,
 		DirSelector:         dirSelector,
 		RecursiveCheck:      recursiveCheck,
-	}, nil
+		TransactionCheck:    transactionCheck,
+		AllowMoveCheck:      allowMoveCheck,
+		ConflictSelect:      conflictSelect,
+		SuffixEntry:         suffixEntry,
+		SortSelect:          sortSelect,
+		SortDescCheck:       sortDescCheck,
+		ItemSelect:          itemSelect,
+		ModeSelect:          modeSelect,
+		KeepStructureCheck:  keepStructureCheck,
+	}
+	ui.OutputSelector = dirpath.CreateOutputDirSelector(window, func(dir string) {
+		ui.OutputDir = dir
+	})
+	return ui, nil
+}
+
+// applyExecMode 把执行方式（原地重命名，或复制/链接到输出文件夹）写入配置
+func applyExecMode(ui *RenameUIComponents, config *model.RenameConfig) error {
+	mode := execModes[ui.ModeSelect.SelectedIndex()]
+	if mode == model.ExecRename {
+		return nil
+	}
+	if ui.OutputDir == "" {
+		return errors.New(dialogTr("selectOutputDirFirst"))
+	}
+	config.Mode = mode
+	config.OutputDir = ui.OutputDir
+	config.KeepStructure = ui.KeepStructureCheck.Checked
+	return nil
 }
 
 func doScanFormats(dir string, recursive bool) ([]string, error) {
@@ -160,60 +303,82 @@ func setupScanButton(ui *RenameUIComponents, config RenameUIConfig) *widget.Butt
 // ===== 其他按钮逻辑保持不动 =====
 //
 
-func setupPreviewButton(ui *RenameUIComponents, config RenameUIConfig) *widget.Button {
-	return widget.NewButton(buttonTr("preview"), func() {
-		var selectedFormats []string
-		for format, check := range ui.FormatChecks {
-			if check.Checked {
-				selectedFormats = append(selectedFormats, format)
-			}
-		}
-		if len(selectedFormats) == 0 {
-			errorDiaLog(ui.Window, dialogTr("selectFormat"))
-			return
+// buildRenameConfig 按界面当前的选择生成重命名配置并检查，预览与执行共用
+func buildRenameConfig(ui *RenameUIComponents, config RenameUIConfig) (model.RenameConfig, error) {
+	var selectedFormats []string
+	for format, check := range ui.FormatChecks {
+		if check.Checked {
+			selectedFormats = append(selectedFormats, format)
 		}
+	}
+	items := itemTypes[ui.ItemSelect.SelectedIndex()]
+	if len(selectedFormats) == 0 && items != model.ItemFolders {
+		return model.RenameConfig{}, errors.New(dialogTr("selectFormat"))
+	}
 
-		renameConfig := config.ConfigBuilder()
-		renameConfig.Type = config.RenameType
-		renameConfig.SelectedDir = global.SelectedDir
-		renameConfig.Formats = selectedFormats
+	renameConfig := config.ConfigBuilder()
+	renameConfig.Type = config.RenameType
+	renameConfig.SelectedDir = global.SelectedDir
+	renameConfig.Formats = selectedFormats
+	renameConfig.Items = items
+	renameConfig.ConflictPolicy = conflictPolicies[ui.ConflictSelect.SelectedIndex()]
+	renameConfig.SuffixPattern = ui.SuffixEntry.Text
+	renameConfig.SortKey = sortKeys[ui.SortSelect.SelectedIndex()]
+	renameConfig.SortDescending = ui.SortDescCheck.Checked
+	if renameConfig.SortKey == model.SortManual {
+		renameConfig.ManualOrder = ui.ManualOrder
+	}
+	renameConfig.AllowMove = ui.AllowMoveCheck.Checked
+	renameConfig.Transactional = ui.TransactionCheck.Checked
 
-		if err := config.ValidateConfig(renameConfig); err != nil {
+	if err := antisamename.ValidateSuffixPattern(renameConfig.SuffixPattern); err != nil {
+		return renameConfig, err
+	}
+	if err := applyExecMode(ui, &renameConfig); err != nil {
+		return renameConfig, err
+	}
+	if err := config.ValidateConfig(renameConfig); err != nil {
+		return renameConfig, err
+	}
+	return renameConfig, nil
+}
+
+func setupPreviewButton(ui *RenameUIComponents, config RenameUIConfig) *widget.Button {
+	return widget.NewButton(buttonTr("preview"), func() {
+		renameConfig, err := buildRenameConfig(ui, config)
+		if err != nil {
 			errorDiaLog(ui.Window, err.Error())
 			return
 		}
 
 		recursive := ui.RecursiveCheck.Checked
-		files, err := dirpath.GetFiles(global.SelectedDir, selectedFormats, recursive)
+		plan, err := buildRenamePlan(renameConfig, recursive)
 		if err != nil {
 			errorDiaLog(ui.Window, err.Error())
 			return
 		}
 
-		preview.ShowPreviewWindow(ui.Window, files, renameConfig)
+		// 预览中显示自动冲突处理的结果；询问模式下冲突在执行时逐个确认
+		if err := antisamename.Resolve(plan); err != nil {
+			errorDiaLog(ui.Window, err.Error())
+			return
+		}
+
+		preview.ShowPreviewWindow(ui.Window, plan, preview.Options{
+			Recursive: recursive,
+			OnApplyOrder: func(order []string) {
+				ui.ManualOrder = order
+				ui.SortSelect.SetSelectedIndex(len(sortKeys) - 1)
+			},
+		})
 	})
 }
 
 func setupRenameButton(ui *RenameUIComponents, config RenameUIConfig) *widget.Button {
 	var btn *widget.Button
 	btn = widget.NewButton(buttonTr("implement"), func() {
-		var selectedFormats []string
-		for format, check := range ui.FormatChecks {
-			if check.Checked {
-				selectedFormats = append(selectedFormats, format)
-			}
-		}
-		if len(selectedFormats) == 0 {
-			errorDiaLog(ui.Window, dialogTr("selectFormat"))
-			return
-		}
-
-		renameConfig := config.ConfigBuilder()
-		renameConfig.Type = config.RenameType
-		renameConfig.SelectedDir = global.SelectedDir
-		renameConfig.Formats = selectedFormats
-
-		if err := config.ValidateConfig(renameConfig); err != nil {
+		renameConfig, err := buildRenameConfig(ui, config)
+		if err != nil {
 			errorDiaLog(ui.Window, err.Error())
 			return
 		}
@@ -244,10 +409,11 @@ func setupBackButton(ui *RenameUIComponents) *widget.Button {
 	})
 }
 
-func setupRenameUIEvents(ui *RenameUIComponents, config RenameUIConfig) (scanBtn, previewBtn, renameBtn, backBtn *widget.Button) {
+func setupRenameUIEvents(ui *RenameUIComponents, config RenameUIConfig) (scanBtn, previewBtn, renameBtn, backBtn, loadJobBtn *widget.Button) {
 	scanBtn = setupScanButton(ui, config)
 	previewBtn = setupPreviewButton(ui, config)
 	renameBtn = setupRenameButton(ui, config)
 	backBtn = setupBackButton(ui)
+	loadJobBtn = setupLoadJobButton(ui)
 	return
 }